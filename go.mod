@@ -8,6 +8,7 @@ require (
 	github.com/charmbracelet/bubbletea v1.3.10
 	github.com/charmbracelet/lipgloss v1.1.0
 	github.com/hekmon/cunits/v3 v3.0.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
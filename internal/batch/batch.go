@@ -0,0 +1,282 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+const (
+	DefaultBPC    = 10
+	DefaultTiming = "cvt-rbv2"
+)
+
+// Item is one display spec read from the input. Err is set when the record
+// could not be turned into a Display; the other records are unaffected.
+type Item struct {
+	Line    int
+	Name    string
+	Display video.Display
+	Err     error
+}
+
+// FormatFromPath guesses the input format from a file extension.
+func FormatFromPath(path string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, true
+	case ".ndjson", ".jsonl", ".json":
+		return FormatNDJSON, true
+	}
+	return "", false
+}
+
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "csv":
+		return FormatCSV, nil
+	case "ndjson", "jsonl":
+		return FormatNDJSON, nil
+	}
+	return "", fmt.Errorf("unknown input format %q", s)
+}
+
+// Read parses every record of r. An empty format sniffs the first
+// non-blank byte: '{' selects NDJSON, anything else CSV.
+func Read(r io.Reader, format Format) ([]Item, error) {
+	br := bufio.NewReader(r)
+	if format == "" {
+		format = sniff(br)
+	}
+	switch format {
+	case FormatCSV:
+		return readCSV(br)
+	case FormatNDJSON:
+		return readNDJSON(br)
+	}
+	return nil, fmt.Errorf("unknown input format %q", format)
+}
+
+func sniff(br *bufio.Reader) Format {
+	for i := 1; ; i++ {
+		b, err := br.Peek(i)
+		if err != nil || len(b) < i {
+			return FormatCSV
+		}
+		switch b[i-1] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{':
+			return FormatNDJSON
+		}
+		return FormatCSV
+	}
+}
+
+type record struct {
+	Name     string `json:"name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Refresh  int    `json:"refresh"`
+	BPC      int    `json:"bpc"`
+	Encoding string `json:"encoding"`
+	Timing   string `json:"timing"`
+	// Interlaced records refresh at their field rate.
+	Interlaced bool `json:"interlaced"`
+	// Fractional records refresh at 1000/1001 of Refresh. A CSV refresh
+	// of 59.94 sets it.
+	Fractional bool `json:"fractional"`
+}
+
+func (r record) display() (video.Display, error) {
+	if r.Width <= 0 || r.Height <= 0 || r.Refresh <= 0 {
+		return video.Display{}, fmt.Errorf("width, height and refresh must be positive")
+	}
+	if r.Interlaced && r.Height%2 != 0 {
+		return video.Display{}, fmt.Errorf("an interlaced height must be even, got %d", r.Height)
+	}
+	if r.BPC == 0 {
+		r.BPC = DefaultBPC
+	}
+	if r.Timing == "" {
+		r.Timing = DefaultTiming
+	}
+	colorDepth, err := video.ColorDepthFromBPC(r.BPC)
+	if err != nil {
+		return video.Display{}, err
+	}
+	encoding, err := video.ParseEncoding(r.Encoding)
+	if err != nil {
+		return video.Display{}, err
+	}
+	timing, ok := video.LookupTiming(r.Timing)
+	if !ok {
+		return video.Display{}, fmt.Errorf("unknown timing %q", r.Timing)
+	}
+	d := video.Display{
+		Width:       r.Width,
+		Height:      r.Height,
+		RefreshRate: r.Refresh,
+		ColorDepth:  colorDepth,
+		Encoding:    encoding,
+		Timing:      timing,
+		Interlaced:  r.Interlaced,
+		Fractional:  r.Fractional,
+	}
+	if err := video.CheckTiming(d); err != nil {
+		return video.Display{}, err
+	}
+	return d, nil
+}
+
+var csvColumns = map[string]string{
+	"name":       "name",
+	"width":      "width",
+	"height":     "height",
+	"refresh":    "refresh",
+	"refresh_hz": "refresh",
+	"bpc":        "bpc",
+	"encoding":   "encoding",
+	"timing":     "timing",
+	"interlaced": "interlaced",
+}
+
+func readCSV(r io.Reader) ([]Item, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("csv header: %w", err)
+	}
+	columns := make([]string, len(header))
+	for i, h := range header {
+		c, ok := csvColumns[strings.ToLower(strings.TrimSpace(h))]
+		if !ok {
+			return nil, fmt.Errorf("csv header: unknown column %q", h)
+		}
+		columns[i] = c
+	}
+	for _, required := range []string{"width", "height", "refresh"} {
+		if !slices.Contains(columns, required) {
+			return nil, fmt.Errorf("csv header: missing column %q", required)
+		}
+	}
+
+	var items []Item
+	for {
+		fields, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		line, _ := cr.FieldPos(0)
+		item := Item{Line: line}
+		if err != nil {
+			var perr *csv.ParseError
+			if !errors.As(err, &perr) {
+				return items, err
+			}
+			item.Line = perr.Line
+			item.Err = perr.Err
+			items = append(items, item)
+			continue
+		}
+		var rec record
+		item.Err = func() error {
+			if len(fields) != len(columns) {
+				return fmt.Errorf("want %d fields, got %d", len(columns), len(fields))
+			}
+			for i, v := range fields {
+				v = strings.TrimSpace(v)
+				var err error
+				switch columns[i] {
+				case "name":
+					rec.Name = v
+				case "width":
+					rec.Width, err = atoi("width", v)
+				case "height":
+					rec.Height, err = atoi("height", v)
+				case "refresh":
+					if rec.Refresh, rec.Fractional, err = video.ParseRefresh(v); err != nil {
+						err = fmt.Errorf("refresh: %q is not a whole or 1000/1001 refresh rate", v)
+					}
+				case "bpc":
+					if v != "" {
+						rec.BPC, err = atoi("bpc", v)
+					}
+				case "encoding":
+					rec.Encoding = v
+				case "timing":
+					rec.Timing = v
+				case "interlaced":
+					if v != "" {
+						if rec.Interlaced, err = strconv.ParseBool(v); err != nil {
+							err = fmt.Errorf("interlaced: %q is not a boolean", v)
+						}
+					}
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}()
+		item.Name = rec.Name
+		if item.Err == nil {
+			item.Display, item.Err = rec.display()
+		}
+		items = append(items, item)
+	}
+}
+
+func readNDJSON(r io.Reader) ([]Item, error) {
+	var items []Item
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; s.Scan(); line++ {
+		b := bytes.TrimSpace(s.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		item := Item{Line: line}
+		var rec record
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&rec); err != nil {
+			item.Err = err
+		} else {
+			item.Name = rec.Name
+			item.Display, item.Err = rec.display()
+		}
+		items = append(items, item)
+	}
+	return items, s.Err()
+}
+
+func atoi(field, v string) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not a number", field, v)
+	}
+	return n, nil
+}
@@ -0,0 +1,112 @@
+package batch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func TestReadCSV(t *testing.T) {
+	in := `# monitors
+name,width,height,refresh_hz,bpc,encoding,timing,interlaced
+desk,3840,2160,144,,,,
+tv,1920,1080,59.94,8,422,cvt-rb,true
+bad,1920,x,60,8,,,
+short,1920,1080
+fast,1920,1080,3000,,,cvt-rb,
+`
+	items, err := Read(strings.NewReader(in), "")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(items) != 5 {
+		t.Fatalf("%d items, want 5", len(items))
+	}
+
+	desk := items[0]
+	if desk.Err != nil || desk.Name != "desk" || desk.Line != 3 {
+		t.Fatalf("desk = %+v", desk)
+	}
+	if d := desk.Display; d.Width != 3840 || d.Height != 2160 || d.RefreshRate != 144 ||
+		d.ColorDepth.BPC() != DefaultBPC || d.Encoding != video.EncodingRGB || d.Timing.String() != "CVT-RBv2" || d.Interlaced || d.Fractional {
+		t.Errorf("desk display = %v", d)
+	}
+
+	tv := items[1]
+	if tv.Err != nil {
+		t.Fatalf("tv: %v", tv.Err)
+	}
+	if d := tv.Display; d.RefreshRate != 60 || !d.Fractional || d.ColorDepth.BPC() != 8 || d.Encoding != video.EncodingYCbCr422 || d.Timing.String() != "CVT-RB" || !d.Interlaced {
+		t.Errorf("tv display = %v", d)
+	}
+
+	if err := items[2].Err; err == nil || !strings.Contains(err.Error(), "height") {
+		t.Errorf("bad error = %v, want a height error", err)
+	}
+	if err := items[3].Err; err == nil || !strings.Contains(err.Error(), "want 8 fields") {
+		t.Errorf("short error = %v, want a field count error", err)
+	}
+	if err := items[4].Err; err == nil || !strings.Contains(err.Error(), "blanking limit") {
+		t.Errorf("fast error = %v, want a blanking limit error", err)
+	}
+}
+
+func TestReadCSVHeader(t *testing.T) {
+	for _, in := range []string{
+		"width,height\n1920,1080\n",
+		"width,height,refresh,depth\n1920,1080,60,8\n",
+	} {
+		if _, err := Read(strings.NewReader(in), FormatCSV); err == nil {
+			t.Errorf("Read(%q) succeeded", in)
+		}
+	}
+	items, err := Read(strings.NewReader(""), FormatCSV)
+	if err != nil || len(items) != 0 {
+		t.Errorf("Read of an empty input = %v, %v", items, err)
+	}
+}
+
+func TestReadNDJSON(t *testing.T) {
+	in := `
+{"name": "desk", "width": 2560, "height": 1440, "refresh": 165, "bpc": 8}
+
+{"width": 1920, "height": 1080, "refresh": 60, "typo": 1}
+{"width": 1920, "height": 1081, "refresh": 50, "interlaced": true}
+{"width": 1920,
+`
+	items, err := Read(strings.NewReader(in), "")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(items) != 4 {
+		t.Fatalf("%d items, want 4", len(items))
+	}
+	if it := items[0]; it.Err != nil || it.Line != 2 || it.Name != "desk" || it.Display.RefreshRate != 165 || it.Display.ColorDepth.BPC() != 8 {
+		t.Errorf("desk = %+v", it)
+	}
+	if it := items[1]; it.Line != 4 || it.Err == nil || !strings.Contains(it.Err.Error(), "typo") {
+		t.Errorf("unknown field item = %+v", it)
+	}
+	if it := items[2]; it.Err == nil || !strings.Contains(it.Err.Error(), "even") {
+		t.Errorf("odd interlaced item = %+v", it)
+	}
+	if it := items[3]; it.Line != 6 || it.Err == nil {
+		t.Errorf("truncated item = %+v", it)
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	for path, want := range map[string]Format{
+		"displays.csv":   FormatCSV,
+		"displays.JSONL": FormatNDJSON,
+		"displays.json":  FormatNDJSON,
+	} {
+		if got, ok := FormatFromPath(path); !ok || got != want {
+			t.Errorf("FormatFromPath(%q) = %q, %v, want %q", path, got, ok, want)
+		}
+	}
+	if _, ok := FormatFromPath("displays.txt"); ok {
+		t.Error("FormatFromPath accepted .txt")
+	}
+}
@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/drm"
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+	"github.com/hekmon/cunits/v3"
+)
+
+func init() {
+	register(Command{
+		Name:    "analyze-log",
+		Summary: "Compare the links trained in an i915 or amdgpu drm.debug log with what the modes need",
+		Run:     runAnalyzeLog,
+	})
+}
+
+type logTraining struct {
+	Mode   string `json:"mode" yaml:"mode"`
+	Lanes  int    `json:"lanes" yaml:"lanes"`
+	Passed bool   `json:"passed" yaml:"passed"`
+}
+
+type analyzeLogOutput struct {
+	Connector       string          `json:"connector" yaml:"connector"`
+	Driver          string          `json:"driver,omitempty" yaml:"driver,omitempty"`
+	Display         *report.Display `json:"display,omitempty" yaml:"display,omitempty"`
+	Refresh         float64         `json:"refresh_hz,omitempty" yaml:"refresh_hz,omitempty"`
+	DSCBitsPerPixel float64         `json:"dsc_bits_per_pixel,omitempty" yaml:"dsc_bits_per_pixel,omitempty"`
+	Trainings       []logTraining   `json:"trainings,omitempty" yaml:"trainings,omitempty"`
+	Link            string          `json:"link,omitempty" yaml:"link,omitempty"`
+	LinkBandwidth   uint64          `json:"link_bandwidth_bps,omitempty" yaml:"link_bandwidth_bps,omitempty"`
+	Bandwidth       uint64          `json:"bandwidth_bps,omitempty" yaml:"bandwidth_bps,omitempty"`
+	// Needs is the slowest link that carries the mode, at the trained lane
+	// count when one does.
+	Needs    string        `json:"needs,omitempty" yaml:"needs,omitempty"`
+	Usage    float64       `json:"usage,omitempty" yaml:"usage,omitempty"`
+	Fit      *video.Status `json:"fit,omitempty" yaml:"fit,omitempty"`
+	Problems []string      `json:"problems,omitempty" yaml:"problems,omitempty"`
+}
+
+func runAnalyzeLog(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("analyze-log", stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gvbc analyze-log [flags] [file]\n\n"+
+			"Reads a kernel log captured with drm.debug set, e.g. 0x10e, from file or stdin,\n"+
+			"and rebuilds the link i915 or amdgpu trained on each DisplayPort connector, the\n"+
+			"mode it set and its bpc and DSC setting. Each link is checked against the\n"+
+			"bandwidth of its mode, on the version selected with -dp or the newest one.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	bpc := fs.Int("bpc", 0, "The color depth in bits per component (default the pipe bpp of the log, or 8)")
+	var ifaces interfaceFlags
+	ifaces.register(fs)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+	if *bpc != 0 {
+		if _, err := video.ColorDepthFromBPC(*bpc); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+	}
+	selected, err := ifaces.resolve()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	r := io.Reader(os.Stdin)
+	if path := fs.Arg(0); path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+		defer f.Close()
+		r = f
+	}
+	links, err := drm.ParseLog(r)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+
+	outputs := []analyzeLogOutput{}
+	t := report.Table{Header: []string{"output", "driver", "mode", "bandwidth", "link", "needs", "usage", "fit"}}
+	var summaries []string
+	allFit := true
+	for _, l := range links {
+		if l.Mode == nil && len(l.Trainings) == 0 {
+			continue
+		}
+		o := analyzeLogOutput{Connector: l.Connector, Driver: l.Driver, DSCBitsPerPixel: l.DSCBitsPerPixel}
+		row := []string{l.Connector, "-", "-", "-", "-", "-", "-", "-"}
+		if l.Driver != "" {
+			row[1] = l.Driver
+		}
+		for _, s := range l.Trainings {
+			o.Trainings = append(o.Trainings, logTraining{Mode: s.Rate, Lanes: s.Lanes, Passed: s.Passed})
+		}
+		o.Problems = append(o.Problems, trainingProblems(l)...)
+		link, trained := l.Link()
+		if trained {
+			o.Link = link.LinkName()
+			row[4] = o.Link
+		}
+
+		if l.Mode == nil {
+			o.Problems = append(o.Problems, "no mode set in the log")
+		} else if d, err := logDisplay(l, *bpc); err != nil {
+			// The pipe bpp of the log is not a color depth gvbc models.
+			o.Problems = append(o.Problems, err.Error())
+		} else {
+			rd := report.NewDisplay(d)
+			o.Display, o.Refresh = &rd, l.Mode.RefreshRate()
+			row[2] = fmt.Sprintf("%dx%d%s@%.2f %d bpc", d.Width, d.Height, video.ScanSuffix(d.Interlaced), o.Refresh, d.ColorDepth.BPC())
+			if d.Encoding != video.EncodingRGB {
+				row[2] += " " + d.Encoding.ShortName()
+			}
+			if l.DSCBitsPerPixel > 0 {
+				row[2] += fmt.Sprintf(" DSC %g bpp", l.DSCBitsPerPixel)
+			}
+			stream := d.Bandwidth()
+			if l.DSCBitsPerPixel > 0 {
+				stream.Bits = cunits.Bits(float64(d.EffectivePixelRate()) * l.DSCBitsPerPixel)
+			}
+			o.Bandwidth = uint64(stream.Bits)
+			row[3] = stream.String()
+			if l.MaxBPP > l.BPP && l.BPP > 0 {
+				o.Problems = append(o.Problems, fmt.Sprintf("bpc lowered from %d to %d", l.MaxBPP/3, l.BPP/3))
+			}
+
+			i, ok := logInterface(l, selected)
+			switch {
+			case !ok:
+				o.Problems = append(o.Problems, "no DisplayPort link to check against")
+			case len(l.Trainings) == 0:
+				o.Problems = append(o.Problems, "no link training in the log")
+			case !trained:
+				// trainingProblems already reports the failures.
+			default:
+				needs, needsLanes, found := neededLink(i, stream, link.Lanes)
+				if found {
+					o.Needs = fmt.Sprintf("%s x%d", needs.GetName(), needsLanes)
+					row[5] = o.Needs
+				}
+				mode, known := video.LookupLinkMode(link.Rate)
+				if !known {
+					o.Problems = append(o.Problems, fmt.Sprintf("link rate %s kHz is not a known link mode", link.Rate))
+					break
+				}
+				bandwidth := i.LinkBandwidth(mode, link.Lanes)
+				o.LinkBandwidth = uint64(bandwidth.Bits)
+				o.Usage = float64(stream.Bits) * 100 / float64(bandwidth.Bits)
+				status := video.StatusOK
+				if l.DSCBitsPerPixel > 0 {
+					status = video.StatusDSC
+				}
+				if stream.Bits > bandwidth.Bits {
+					status = video.StatusBandwidth
+					allFit = false
+					if found {
+						o.Problems = append(o.Problems, fmt.Sprintf("trained at %s but mode needs %s", o.Link, o.Needs))
+					} else {
+						o.Problems = append(o.Problems, fmt.Sprintf("trained at %s but mode needs more than any %s link", o.Link, i))
+					}
+				}
+				o.Fit = &status
+				row[6], row[7] = fmt.Sprintf("%.1f%%", o.Usage), status.String()
+				summary := fmt.Sprintf("%s is at %.0f%% of %s", l.Connector, o.Usage, o.Link)
+				if since, ok := linkVersion(i, link.Rate); ok {
+					summary += fmt.Sprintf(" (%s)", since)
+				}
+				summaries = append(summaries, summary)
+			}
+		}
+		outputs = append(outputs, o)
+		t.Rows = append(t.Rows, row)
+	}
+
+	problems := report.Table{Title: "Problems", Header: []string{"output", "problem"}}
+	for _, o := range outputs {
+		for _, p := range o.Problems {
+			problems.Rows = append(problems.Rows, []string{o.Connector, p})
+		}
+	}
+	tables := []report.Table{t}
+	if len(problems.Rows) > 0 {
+		tables = append(tables, problems)
+	}
+	if format == report.FormatText {
+		if len(outputs) == 0 {
+			fmt.Fprintln(stdout, "no link training or mode set in the log, was it captured with drm.debug set?")
+			return ExitOK
+		}
+		for _, s := range summaries {
+			fmt.Fprintln(stdout, s)
+		}
+		if len(summaries) > 0 {
+			fmt.Fprintln(stdout)
+		}
+	}
+	if err := report.WriteTables(stdout, format, outputs, tables...); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if !allFit {
+		return ExitNoFit
+	}
+	return ExitOK
+}
+
+// logDisplay is the mode of l at bpc, or else at the pipe bpp of the log,
+// or 8 bpc.
+func logDisplay(l drm.LogLink, bpc int) (video.Display, error) {
+	if bpc == 0 {
+		bpc = 8
+		if l.BPP > 0 && l.BPP%3 == 0 {
+			bpc = l.BPP / 3
+		}
+	}
+	colorDepth, err := video.ColorDepthFromBPC(bpc)
+	if err != nil {
+		return video.Display{}, err
+	}
+	return l.Mode.Display(colorDepth, l.Encoding), nil
+}
+
+// logInterface is the selected version of the family of the connector of
+// l, or else its newest one. Only DisplayPort links are trained.
+func logInterface(l drm.LogLink, selected map[string]video.Interface) (video.Interface, bool) {
+	f, ok := video.LookupInterfaceFamily(l.Family())
+	if !ok || f.Name != "DisplayPort" || len(f.Versions) == 0 {
+		return video.Interface{}, false
+	}
+	if i, ok := selected[f.Name]; ok {
+		return i, true
+	}
+	return f.Versions[0], true
+}
+
+// linkVersion is the oldest version of the family of i with the link
+// mode, the one a link at that rate tells apart. A log names the rate, not
+// the version the source and sink speak.
+func linkVersion(i video.Interface, mode string) (video.Interface, bool) {
+	f, ok := video.LookupInterfaceFamily(i.Family)
+	if !ok {
+		return video.Interface{}, false
+	}
+	for _, v := range slices.Backward(f.Versions) {
+		if slices.ContainsFunc(v.Modes, func(m video.TransmissionMode) bool { return m.GetName() == mode }) {
+			return v, true
+		}
+	}
+	return video.Interface{}, false
+}
+
+// neededLink is the slowest link mode of i that carries stream over lanes,
+// or else over the widest link.
+func neededLink(i video.Interface, stream cunits.Speed, lanes int) (video.TransmissionMode, int, bool) {
+	tried := []int{lanes}
+	if len(i.Lanes) > 0 && i.Lanes[len(i.Lanes)-1] != lanes {
+		tried = append(tried, i.Lanes[len(i.Lanes)-1])
+	}
+	for _, n := range tried {
+		for _, m := range i.TrainingModes() {
+			if i.LinkBandwidth(m, n).Bits >= stream.Bits {
+				return m, n, true
+			}
+		}
+	}
+	return nil, 0, false
+}
+
+// trainingProblems lists the failed trainings of l and what it fell back
+// to.
+func trainingProblems(l drm.LogLink) []string {
+	var failed []string
+	for _, s := range l.Trainings {
+		if !s.Passed && !slices.Contains(failed, s.LinkName()) {
+			failed = append(failed, s.LinkName())
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	if link, ok := l.Link(); ok {
+		return []string{fmt.Sprintf("link training failed at %s, fell back to %s", strings.Join(failed, ", "), link.LinkName())}
+	}
+	return []string{fmt.Sprintf("link training failed at %s and never passed", strings.Join(failed, ", "))}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aloababa/gvbc/internal/batch"
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "batch",
+		Summary: "Evaluate many displays from a CSV or NDJSON file (or stdin)",
+		Run:     runBatch,
+	})
+}
+
+func runBatch(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("batch", stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gvbc batch [flags] [file]\n\nReads display specs from file, or stdin when file is omitted or \"-\".\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	var ifaces interfaceFlags
+	ifaces.register(fs)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	inputFormat := fs.String("input-format", "", "The input format (csv or ndjson); guessed from the file extension or content when empty")
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+	selected, err := ifaces.resolve()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+
+	var in batch.Format
+	if *inputFormat != "" {
+		if in, err = batch.ParseFormat(*inputFormat); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+	}
+	r := io.Reader(os.Stdin)
+	if path := fs.Arg(0); path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+		defer f.Close()
+		r = f
+		if in == "" {
+			in, _ = batch.FormatFromPath(path)
+		}
+	}
+	items, err := batch.Read(r, in)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+
+	status := ExitOK
+	entries := make([]report.Entry, len(items))
+	for i, item := range items {
+		entries[i] = report.Entry{Line: item.Line, Name: item.Name}
+		if item.Err != nil {
+			entries[i].Error = item.Err.Error()
+			status = ExitNoFit
+			continue
+		}
+		rows, fits := evaluateMatrix(item.Display, selected)
+		result := report.New(item.Display, rows, fits)
+		entries[i].Result = &result
+		if !fits {
+			status = ExitNoFit
+		}
+	}
+	if err := report.WriteBatch(stdout, format, entries); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	return status
+}
+
+// evaluateMatrix is evaluate with one row per interface version: the
+// lowest compatible mode of a selected version, as in the calc table.
+func evaluateMatrix(d video.Display, selected map[string]video.Interface) ([]video.Compatibility, bool) {
+	results, fits := evaluate(d, selected)
+	var rows []video.Compatibility
+	for _, r := range results {
+		iface, ok := selected[r.Family.Name]
+		if !ok {
+			rows = append(rows, r.Results...)
+			continue
+		}
+		lowest := iface.LowestCompatibleMode(d)
+		for _, c := range r.Results {
+			if lowest != nil && c.Mode.GetName() == lowest.GetName() {
+				rows = append(rows, c)
+				break
+			}
+		}
+	}
+	return rows, fits
+}
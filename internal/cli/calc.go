@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "calc",
+		Summary: "Calculate the bandwidth of a display and check interface compatibility",
+		Run:     runCalc,
+	})
+}
+
+func runCalc(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("calc", stderr)
+	var df displayFlags
+	df.register(fs)
+	var ifaces interfaceFlags
+	ifaces.register(fs)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	d, err := df.display()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	selected, err := ifaces.resolve()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	results, fits := evaluate(d, selected)
+	var rows []video.Compatibility
+	for _, r := range results {
+		rows = append(rows, r.Results...)
+	}
+	r := report.New(d, rows, fits)
+	for _, target := range suggestionTargets(d, results, selected) {
+		for _, s := range video.Suggest(d, target, maxSuggestions) {
+			r.Suggestions = append(r.Suggestions, report.NewSuggestion(target, s))
+		}
+	}
+	if err := report.Write(stdout, format, r); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if !fits {
+		return ExitNoFit
+	}
+	return ExitOK
+}
+
+const maxSuggestions = 5
+
+// suggestionTargets returns the interfaces worth suggesting remediations
+// for: each selected interface the display does not fit, and for the other
+// families the newest version when even that one does not fit.
+func suggestionTargets(d video.Display, results []familyResult, selected map[string]video.Interface) []video.Interface {
+	var targets []video.Interface
+	for _, r := range results {
+		if i, ok := selected[r.Family.Name]; ok {
+			if !anyFits(r.Results) {
+				targets = append(targets, i)
+			}
+			continue
+		}
+		if len(r.Family.Versions) > 0 && !anyFits(r.Family.Versions[0].Evaluate(d)) {
+			targets = append(targets, r.Family.Versions[0])
+		}
+	}
+	return targets
+}
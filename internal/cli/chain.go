@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "chain",
+		Summary: "Validate a display over a chain of adapters and cables from a source to a sink",
+		Run:     runChain,
+	})
+}
+
+type chainSegment struct {
+	Family     string       `json:"family" yaml:"family"`
+	Version    string       `json:"version" yaml:"version"`
+	Parts      []string     `json:"parts" yaml:"parts"`
+	Bottleneck string       `json:"bottleneck" yaml:"bottleneck"`
+	Mode       string       `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Bandwidth  uint64       `json:"bandwidth_bps" yaml:"bandwidth_bps"`
+	DSC        bool         `json:"dsc" yaml:"dsc"`
+	Status     video.Status `json:"status" yaml:"status"`
+}
+
+type chainResult struct {
+	Display    report.Display     `json:"display" yaml:"display"`
+	Fits       bool               `json:"fits" yaml:"fits"`
+	Status     video.Status       `json:"status" yaml:"status"`
+	Bottleneck string             `json:"bottleneck,omitempty" yaml:"bottleneck,omitempty"`
+	DSC        bool               `json:"dsc" yaml:"dsc"`
+	Segments   []chainSegment     `json:"segments" yaml:"segments"`
+	Best       *report.Suggestion `json:"best,omitempty" yaml:"best,omitempty"`
+	Problems   []string           `json:"problems,omitempty" yaml:"problems,omitempty"`
+}
+
+func runChain(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("chain", stderr)
+	var df displayFlags
+	df.register(fs)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	sourceFlag := fs.String("source", "", "The source as a catalog `name[:port]` or family=version")
+	sinkFlag := fs.String("sink", "", "The sink as a catalog `name[:input]` or family=version")
+	var vias []string
+	fs.Func("via", "A catalog cable or adapter the signal goes through, from the source on (repeatable)", func(v string) error {
+		vias = append(vias, v)
+		return nil
+	})
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	d, err := df.display()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if *sourceFlag == "" || *sinkFlag == "" {
+		fmt.Fprintln(stderr, "-source and -sink are required")
+		return ExitUsage
+	}
+	var c video.Chain
+	for _, name := range vias {
+		if cable, ok := video.LookupCable(name); ok {
+			c.Hops = append(c.Hops, video.Hop{Cable: &cable})
+		} else if adapter, ok := video.LookupAdapter(name); ok {
+			c.Hops = append(c.Hops, video.Hop{Adapter: &adapter})
+		} else {
+			fmt.Fprintf(stderr, "unknown cable or adapter %q\n", name)
+			return ExitUsage
+		}
+	}
+	// Without a port, a catalog device connects on the first one of the
+	// family the next hop takes.
+	var sourceFamily string
+	if len(c.Hops) > 0 {
+		if h := c.Hops[0]; h.Cable != nil {
+			sourceFamily = h.Cable.Family
+		} else {
+			sourceFamily = h.Adapter.Input.Interface.Family
+		}
+	}
+	if c.Source, err = sourceEnd(*sourceFlag, sourceFamily); err != nil {
+		fmt.Fprintf(stderr, "-source: %v\n", err)
+		return ExitUsage
+	}
+	sinkFamily := c.Source.Port.Interface.Family
+	for _, h := range c.Hops {
+		if h.Adapter != nil {
+			sinkFamily = h.Adapter.Output.Interface.Family
+		}
+	}
+	if c.Sink, err = sinkEnd(*sinkFlag, sinkFamily); err != nil {
+		fmt.Fprintf(stderr, "-sink: %v\n", err)
+		return ExitUsage
+	}
+
+	r := c.Evaluate(d)
+	res := chainResult{
+		Display:    report.NewDisplay(d),
+		Fits:       len(r.Problems) == 0 && r.Status.Fits(),
+		Status:     r.Status,
+		Bottleneck: r.Bottleneck,
+		DSC:        r.DSC,
+		Problems:   r.Problems,
+	}
+	segments := report.Table{
+		Title:  "Segments",
+		Header: []string{"parts", "link", "fastest", "bandwidth", "dsc", "bottleneck", "status"},
+	}
+	for _, s := range r.Segments {
+		cs := chainSegment{
+			Family:     s.Interface.Family,
+			Version:    s.Interface.Version,
+			Parts:      s.Parts,
+			Bottleneck: s.Bottleneck,
+			Bandwidth:  uint64(s.Bandwidth.Bits),
+			DSC:        s.Interface.DSC,
+			Status:     s.Compatibility.Status,
+		}
+		fastest := "-"
+		if len(s.Interface.Modes) > 0 {
+			fastest = slices.MaxFunc(s.Interface.Modes, func(a, b video.TransmissionMode) int {
+				return cmp.Compare(a.GetBandwidth().Bits, b.GetBandwidth().Bits)
+			}).GetName()
+		}
+		status := "-"
+		if s.Compatibility.Mode != nil {
+			cs.Mode = s.Compatibility.Mode.GetName()
+			status = s.Compatibility.Status.String()
+		}
+		res.Segments = append(res.Segments, cs)
+		segments.Rows = append(segments.Rows, []string{strings.Join(s.Parts, " → "), s.Interface.String(), fastest,
+			s.Bandwidth.String(), yesNo(cs.DSC), s.Bottleneck, status})
+	}
+	var tables []report.Table
+	if len(segments.Rows) > 0 {
+		tables = append(tables, segments)
+	}
+	if r.Best != nil {
+		s := report.NewSuggestion(r.Best.Interface, *r.Best)
+		res.Best = &s
+		tables = append(tables, report.Table{
+			Title:  "Best configuration",
+			Header: []string{"display", "link", "changes"},
+			Rows: [][]string{{s.Display.String(), r.Best.Interface.String() + " " + s.Mode,
+				strings.Join(s.Changes, ", ")}},
+		})
+	}
+	if len(r.Problems) > 0 {
+		problems := report.Table{Title: "Problems", Header: []string{"problem"}}
+		for _, p := range r.Problems {
+			problems.Rows = append(problems.Rows, []string{p})
+		}
+		tables = append(tables, problems)
+	}
+	if format == report.FormatText {
+		fmt.Fprintf(stdout, "Chain for %s, %s, %s: %s\n", d.Mode(), d.ColorDepth, d.Timing,
+			chainOutcome(res))
+		if r.Bottleneck != "" {
+			fmt.Fprintf(stdout, "Bottleneck: %s\n", r.Bottleneck)
+		}
+		fmt.Fprintln(stdout)
+	}
+	if err := report.WriteTables(stdout, format, res, tables...); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if !res.Fits {
+		return ExitNoFit
+	}
+	return ExitOK
+}
+
+func chainOutcome(r chainResult) string {
+	switch {
+	case len(r.Problems) > 0:
+		return "cannot connect"
+	case r.Status == video.StatusOK:
+		return "fits"
+	case r.Status == video.StatusDSC:
+		return "fits with DSC"
+	}
+	return "does not fit"
+}
+
+// sourceEnd resolves a catalog source with an optional port, or a
+// family=version interface.
+func sourceEnd(value, family string) (video.ChainEnd, error) {
+	if end, ok, err := interfaceEnd(value); ok {
+		return end, err
+	}
+	name, portName, _ := strings.Cut(value, ":")
+	s, ok := video.LookupSource(name)
+	if !ok {
+		return video.ChainEnd{}, fmt.Errorf("unknown source %q", name)
+	}
+	port, err := endPort(s.Name, s.Ports, portName, family)
+	if err != nil {
+		return video.ChainEnd{}, err
+	}
+	return video.ChainEnd{Name: s.Name + " " + port.Name, Port: port, DSC: s.DSCEncoders > 0}, nil
+}
+
+// sinkEnd is sourceEnd for the sink catalog.
+func sinkEnd(value, family string) (video.ChainEnd, error) {
+	if end, ok, err := interfaceEnd(value); ok {
+		return end, err
+	}
+	name, portName, _ := strings.Cut(value, ":")
+	s, ok := video.LookupSink(name)
+	if !ok {
+		return video.ChainEnd{}, fmt.Errorf("unknown sink %q", name)
+	}
+	port, err := endPort(s.Name, s.Inputs, portName, family)
+	if err != nil {
+		return video.ChainEnd{}, err
+	}
+	return video.ChainEnd{Name: s.Name + " " + port.Name, Port: port, DSC: s.DSC}, nil
+}
+
+// interfaceEnd resolves family=version; ok is false when value is not in
+// that form.
+func interfaceEnd(value string) (end video.ChainEnd, ok bool, err error) {
+	family, version, ok := strings.Cut(value, "=")
+	if !ok {
+		return video.ChainEnd{}, false, nil
+	}
+	f, found := video.LookupInterfaceFamily(family)
+	if !found {
+		return video.ChainEnd{}, true, fmt.Errorf("unknown interface family %q", family)
+	}
+	i, found := f.Version(version)
+	if !found {
+		return video.ChainEnd{}, true, fmt.Errorf("unknown %s version %q", f.Name, version)
+	}
+	return video.ChainEnd{Name: i.String(), Port: video.Port{Name: i.String(), Interface: i}, DSC: i.DSC}, true, nil
+}
+
+// endPort picks the named port, or the first one of family when name is
+// empty, or the first one when family is empty too.
+func endPort(device string, ports []video.Port, name, family string) (video.Port, error) {
+	for _, p := range ports {
+		if name != "" && strings.EqualFold(p.Name, name) || name == "" && (family == "" || p.Interface.Family == family) {
+			return p, nil
+		}
+	}
+	if name != "" {
+		return video.Port{}, fmt.Errorf("no port %q on %s", name, device)
+	}
+	return video.Port{}, fmt.Errorf("%s has no %s port", device, family)
+}
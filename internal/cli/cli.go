@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+const (
+	ExitOK    = 0
+	ExitNoFit = 1
+	ExitUsage = 2
+)
+
+type Command struct {
+	Name    string
+	Summary string
+	Run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands []Command
+
+func register(c Command) {
+	commands = append(commands, c)
+}
+
+func Commands() []Command {
+	return commands
+}
+
+func Lookup(name string) (Command, bool) {
+	for _, c := range commands {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Command{}, false
+}
+
+// LoadSpec applies the spec at path, or the user spec under the XDG config
+// directory when path is empty and that file exists.
+func LoadSpec(path string) error {
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil
+		}
+		path = filepath.Join(dir, "gvbc", "spec.yaml")
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+	}
+	return video.LoadSpecFile(path)
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("gvbc "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+type displayFlags struct {
+	width      int
+	height     int
+	refresh    int
+	fractional bool
+	bpc        int
+	encoding   string
+	timing     string
+	modeline   string
+	interlaced bool
+}
+
+func (f *displayFlags) register(fs *flag.FlagSet) {
+	fs.IntVar(&f.width, "width", 3840, "The horizontal resolution in pixels")
+	fs.IntVar(&f.height, "height", 2160, "The vertical resolution in pixels")
+	f.refresh = 60
+	fs.Func("refresh", "The refresh `rate` in Hz, e.g. 60 or 59.94 for 1000/1001 of 60, the field rate when interlaced (default 60)", func(s string) error {
+		var err error
+		f.refresh, f.fractional, err = video.ParseRefresh(s)
+		return err
+	})
+	fs.IntVar(&f.bpc, "bpc", 10, "The color depth in bits per component (8, 10, 12 or 16)")
+	fs.StringVar(&f.encoding, "encoding", "rgb", "The pixel encoding (rgb, 444, 422 or 420)")
+	fs.StringVar(&f.timing, "timing", "cvt-rbv2", "The timing standard ("+strings.Join(timingNames(), ", ")+")")
+	fs.StringVar(&f.modeline, "modeline", "", "An X11 `modeline` replacing the resolution, refresh rate and timing")
+	fs.BoolVar(&f.interlaced, "interlaced", false, "Send the display interlaced, as 1080i50")
+}
+
+// display builds the display of the flags, from the modeline when there
+// is one.
+func (f displayFlags) display() (video.Display, error) {
+	colorDepth, err := video.ColorDepthFromBPC(f.bpc)
+	if err != nil {
+		return video.Display{}, err
+	}
+	encoding, err := video.ParseEncoding(f.encoding)
+	if err != nil {
+		return video.Display{}, err
+	}
+	if f.modeline != "" {
+		t, _, err := video.ParseModeline(f.modeline)
+		if err != nil {
+			return video.Display{}, err
+		}
+		return t.Display(colorDepth, encoding), nil
+	}
+	if f.width <= 0 || f.height <= 0 || f.refresh <= 0 {
+		return video.Display{}, fmt.Errorf("width, height and refresh must be positive")
+	}
+	if f.interlaced && f.height%2 != 0 {
+		return video.Display{}, fmt.Errorf("an interlaced height must be even, got %d", f.height)
+	}
+	timing, ok := video.LookupTiming(f.timing)
+	if !ok {
+		return video.Display{}, fmt.Errorf("unknown timing %q", f.timing)
+	}
+	d := video.Display{
+		Width:       f.width,
+		Height:      f.height,
+		RefreshRate: f.refresh,
+		ColorDepth:  colorDepth,
+		Encoding:    encoding,
+		Timing:      timing,
+		Interlaced:  f.interlaced,
+		Fractional:  f.fractional,
+	}
+	if err := video.CheckTiming(d); err != nil {
+		return video.Display{}, err
+	}
+	return d, nil
+}
+
+func timingNames() []string {
+	var names []string
+	for _, t := range video.Timings() {
+		names = append(names, strings.ToLower(t.String()))
+	}
+	return names
+}
+
+// parseFlags registers the common -spec flag, parses args and loads the
+// spec. Errors are reported on the flag set output.
+func parseFlags(fs *flag.FlagSet, args []string) bool {
+	specFile := fs.String("spec", "", "The path to a spec file extending the built-in definitions")
+	if err := fs.Parse(args); err != nil {
+		return false
+	}
+	if err := LoadSpec(*specFile); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return false
+	}
+	return true
+}
+
+func formatFlag(fs *flag.FlagSet, format *report.Format) {
+	names := make([]string, len(report.Formats()))
+	for i, f := range report.Formats() {
+		names[i] = string(f)
+	}
+	fs.Func("format", "The output format ("+strings.Join(names, ", ")+") (default text)", func(v string) error {
+		f, err := report.ParseFormat(v)
+		if err != nil {
+			return err
+		}
+		*format = f
+		return nil
+	})
+}
+
+// interfaceFlags selects a single version per interface family, either with
+// a family shorthand such as -dp or with -interface family=version.
+type interfaceFlags struct {
+	selections [][2]string
+}
+
+func (f *interfaceFlags) register(fs *flag.FlagSet) {
+	fs.Func("dp", "Only evaluate this DisplayPort version", func(v string) error {
+		f.selections = append(f.selections, [2]string{"DisplayPort", v})
+		return nil
+	})
+	fs.Func("hdmi", "Only evaluate this HDMI version", func(v string) error {
+		f.selections = append(f.selections, [2]string{"HDMI", v})
+		return nil
+	})
+	fs.Func("interface", "Only evaluate this `family=version` (repeatable)", func(v string) error {
+		family, version, ok := strings.Cut(v, "=")
+		if !ok {
+			return fmt.Errorf("want family=version, got %q", v)
+		}
+		f.selections = append(f.selections, [2]string{family, version})
+		return nil
+	})
+}
+
+// resolve looks the selections up in the interface registry, keyed by
+// family name.
+func (f interfaceFlags) resolve() (map[string]video.Interface, error) {
+	selected := make(map[string]video.Interface, len(f.selections))
+	for _, s := range f.selections {
+		fam, ok := video.LookupInterfaceFamily(s[0])
+		if !ok {
+			return nil, fmt.Errorf("unknown interface family %q", s[0])
+		}
+		iface, ok := fam.Version(s[1])
+		if !ok {
+			return nil, fmt.Errorf("unknown %s version %q", fam.Name, s[1])
+		}
+		selected[fam.Name] = iface
+	}
+	return selected, nil
+}
+
+type familyResult struct {
+	Family  video.InterfaceFamily
+	Results []video.Compatibility
+}
+
+// evaluate returns the compatibility rows of every family, restricted to the
+// selected version when there is one, and whether the display fits. With a
+// selection, every selected interface must fit; otherwise any row will do.
+func evaluate(d video.Display, selected map[string]video.Interface) ([]familyResult, bool) {
+	families := video.InterfaceFamilies()
+	out := make([]familyResult, len(families))
+	fits := len(selected) > 0
+	for i, fam := range families {
+		out[i].Family = fam
+		iface, ok := selected[fam.Name]
+		if !ok {
+			out[i].Results = fam.Evaluate(d)
+			if len(selected) == 0 && anyFits(out[i].Results) {
+				fits = true
+			}
+			continue
+		}
+		out[i].Results = iface.Evaluate(d)
+		if !anyFits(out[i].Results) {
+			fits = false
+		}
+	}
+	return out, fits
+}
+
+func anyFits(results []video.Compatibility) bool {
+	for _, c := range results {
+		if c.Status.Fits() {
+			return true
+		}
+	}
+	return false
+}
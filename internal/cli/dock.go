@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "dock",
+		Summary: "Plan how a docking station splits its upstream bandwidth between displays",
+		Run:     runDock,
+	})
+	register(Command{
+		Name:    "docks",
+		Summary: "List the docking stations of the catalog",
+		Run:     runDocks,
+	})
+}
+
+type dockPort struct {
+	sourcePort `yaml:",inline"`
+	Tunnel     int `json:"tunnel" yaml:"tunnel"`
+}
+
+type dockEntry struct {
+	Name       string     `json:"name" yaml:"name"`
+	Upstream   string     `json:"upstream" yaml:"upstream"`
+	Interface  string     `json:"interface" yaml:"interface"`
+	Lanes      []int      `json:"lanes,omitempty" yaml:"lanes,omitempty"`
+	Bandwidth  uint64     `json:"bandwidth_bps,omitempty" yaml:"bandwidth_bps,omitempty"`
+	Tunnels    int        `json:"tunnels" yaml:"tunnels"`
+	MSTStreams int        `json:"mst_streams" yaml:"mst_streams"`
+	Ports      []dockPort `json:"ports" yaml:"ports"`
+}
+
+type dockDisplay struct {
+	Name      string          `json:"name" yaml:"name"`
+	Port      string          `json:"port" yaml:"port"`
+	Display   *report.Display `json:"display,omitempty" yaml:"display,omitempty"`
+	Bandwidth uint64          `json:"bandwidth_bps" yaml:"bandwidth_bps"`
+	Preferred bool            `json:"preferred" yaml:"preferred"`
+	Problems  []string        `json:"problems,omitempty" yaml:"problems,omitempty"`
+}
+
+type dockStream struct {
+	Link      string `json:"link" yaml:"link"`
+	Bandwidth uint64 `json:"bandwidth_bps" yaml:"bandwidth_bps"`
+	Used      uint64 `json:"used_bps" yaml:"used_bps"`
+	Displays  int    `json:"displays" yaml:"displays"`
+}
+
+type dockResult struct {
+	Dock     string        `json:"dock" yaml:"dock"`
+	OK       bool          `json:"ok" yaml:"ok"`
+	Lanes    int           `json:"lanes,omitempty" yaml:"lanes,omitempty"`
+	USB      string        `json:"usb" yaml:"usb"`
+	Streams  []dockStream  `json:"streams" yaml:"streams"`
+	Displays []dockDisplay `json:"displays" yaml:"displays"`
+}
+
+func runDock(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("dock", stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gvbc dock -dock name -display port=WxH@R|sink ... [flags]\n\n"+
+			"Assigns modes to the displays plugged into a dock, in order.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	// Modes come from -display, the other display flags apply to all.
+	df := displayFlags{encoding: "rgb"}
+	fs.IntVar(&df.bpc, "bpc", 10, "The color depth in bits per component (8, 10, 12 or 16)")
+	fs.StringVar(&df.timing, "timing", "cvt-rbv2", "The timing standard ("+strings.Join(timingNames(), ", ")+")")
+	format := report.FormatText
+	formatFlag(fs, &format)
+	dockFlag := fs.String("dock", "", "The catalog dock `name`")
+	sourceFlag := fs.String("source", "", "The catalog source `name[:port]` the dock is plugged into")
+	var displayFlagValues []string
+	fs.Func("display", "A display as `port=WxH@R` or port=sink, a catalog sink (repeatable)", func(v string) error {
+		displayFlagValues = append(displayFlagValues, v)
+		return nil
+	})
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	k, ok := video.LookupDock(*dockFlag)
+	if !ok {
+		fmt.Fprintf(stderr, "unknown dock %q\n", *dockFlag)
+		return ExitUsage
+	}
+	if len(displayFlagValues) == 0 {
+		fmt.Fprintln(stderr, "at least one -display is required")
+		return ExitUsage
+	}
+	var host *video.Port
+	if *sourceFlag != "" {
+		end, err := sourceEnd(*sourceFlag, k.Interface.Family)
+		if err != nil {
+			fmt.Fprintf(stderr, "-source: %v\n", err)
+			return ExitUsage
+		}
+		host = &end.Port
+	}
+	var displays []video.DockDisplay
+	for _, v := range displayFlagValues {
+		d, err := dockDisplayFlag(v, df)
+		if err != nil {
+			fmt.Fprintf(stderr, "-display: %v\n", err)
+			return ExitUsage
+		}
+		displays = append(displays, d)
+	}
+
+	p := k.Plan(displays, host)
+	res := dockResult{Dock: k.Name, OK: p.OK(), Lanes: p.Lanes, USB: p.USB}
+	assignments := report.Table{
+		Title:  k.Name,
+		Header: []string{"display", "port", "mode", "bandwidth", "preferred"},
+	}
+	problems := report.Table{Title: "Problems", Header: []string{"display", "problem"}}
+	for _, a := range p.Displays {
+		dd := dockDisplay{
+			Name:      a.Display.Name,
+			Port:      a.Display.Port,
+			Bandwidth: uint64(a.Bandwidth.Bits),
+			Preferred: a.Preferred(),
+			Problems:  a.Problems,
+		}
+		mode, bandwidth := "-", "-"
+		if a.Mode != nil {
+			d := report.NewDisplay(*a.Mode)
+			dd.Display = &d
+			mode, bandwidth = d.String(), a.Bandwidth.String()
+		}
+		res.Displays = append(res.Displays, dd)
+		assignments.Rows = append(assignments.Rows, []string{dd.Name, dd.Port, mode, bandwidth, yesNo(dd.Preferred)})
+		for _, pr := range a.Problems {
+			problems.Rows = append(problems.Rows, []string{dd.Name, pr})
+		}
+	}
+	streams := report.Table{
+		Title:  "Upstream",
+		Header: []string{"stream", "link", "used", "available", "displays"},
+	}
+	for i, s := range p.Streams {
+		res.Streams = append(res.Streams, dockStream{
+			Link:      s.Link,
+			Bandwidth: uint64(s.Bandwidth.Bits),
+			Used:      uint64(s.Used.Bits),
+			Displays:  s.Displays,
+		})
+		streams.Rows = append(streams.Rows, []string{fmt.Sprint(i), s.Link, s.Used.String(), s.Bandwidth.String(),
+			fmt.Sprint(s.Displays)})
+	}
+	tables := []report.Table{assignments, streams}
+	if len(problems.Rows) > 0 {
+		tables = append(tables, problems)
+	}
+	if format == report.FormatText {
+		upstream := k.Upstream.String()
+		if p.Lanes > 0 {
+			upstream += fmt.Sprintf(" on %d lanes", p.Lanes)
+		}
+		outcome := "every display gets its preferred mode"
+		if !res.OK {
+			outcome = "not every display gets its preferred mode"
+		}
+		fmt.Fprintf(stdout, "%s (%s): %s\nUSB data: %s\n\n", k.Name, upstream, outcome, p.USB)
+	}
+	if err := report.WriteTables(stdout, format, res, tables...); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if !res.OK {
+		return ExitNoFit
+	}
+	return ExitOK
+}
+
+// dockDisplayFlag parses port=WxH@R, falling back to lower refresh rates
+// at that resolution, or port=sink, trying the advertised modes of the
+// sink from its native one down. df provides the color depth, encoding
+// and timing.
+func dockDisplayFlag(value string, df displayFlags) (video.DockDisplay, error) {
+	port, name, ok := strings.Cut(value, "=")
+	if !ok || port == "" || name == "" {
+		return video.DockDisplay{}, fmt.Errorf("%q is not port=WxH@R or port=sink", value)
+	}
+	d := video.DockDisplay{Name: name, Port: port}
+	var modes []video.SinkMode
+	if m, err := video.ParseSinkMode(name); err == nil {
+		modes = append(modes, m)
+		for _, r := range []int{60, 30} {
+			if r < m.RefreshRate {
+				modes = append(modes, video.SinkMode{Width: m.Width, Height: m.Height, RefreshRate: r, Interlaced: m.Interlaced})
+			}
+		}
+	} else if s, ok := video.LookupSink(name); ok {
+		d.Name = s.Name
+		// The native mode comes first, then the largest and fastest ones.
+		modes = slices.Clone(s.Modes)
+		slices.SortStableFunc(modes[1:], func(a, b video.SinkMode) int {
+			return cmp.Or(-cmp.Compare(a.Width*a.Height, b.Width*b.Height), -cmp.Compare(a.RefreshRate, b.RefreshRate))
+		})
+		if s.MaxBPC > 0 {
+			df.bpc = min(df.bpc, s.MaxBPC)
+		}
+	} else {
+		return video.DockDisplay{}, fmt.Errorf("%q is neither a WxH@R mode nor a catalog sink", name)
+	}
+	for _, m := range modes {
+		df.width, df.height, df.refresh, df.interlaced, df.fractional = m.Width, m.Height, m.RefreshRate, m.Interlaced, false
+		display, err := df.display()
+		if err != nil {
+			return video.DockDisplay{}, err
+		}
+		d.Modes = append(d.Modes, display)
+	}
+	return d, nil
+}
+
+func runDocks(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("docks", stderr)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	entries := []dockEntry{}
+	t := report.Table{
+		Header: []string{"name", "upstream", "link", "mst", "ports"},
+	}
+	for _, k := range video.Docks() {
+		e := dockEntry{
+			Name:       k.Name,
+			Upstream:   k.Upstream.String(),
+			Interface:  k.Interface.String(),
+			Lanes:      k.Lanes,
+			Bandwidth:  uint64(k.Bandwidth.Bits),
+			Tunnels:    k.Tunnels,
+			MSTStreams: k.MSTStreams,
+		}
+		for _, p := range k.Ports {
+			e.Ports = append(e.Ports, dockPort{
+				sourcePort: sourcePort{Name: p.Name, Interface: p.Interface.Family, Version: p.Interface.Version, MaxRate: p.MaxRate.Name},
+				Tunnel:     p.Tunnel,
+			})
+		}
+		entries = append(entries, e)
+		upstream := k.Upstream.String()
+		if len(k.Lanes) > 0 {
+			var lanes []string
+			for _, n := range k.Lanes {
+				lanes = append(lanes, fmt.Sprint(n))
+			}
+			upstream += " (" + strings.Join(lanes, " or ") + " lanes)"
+		} else {
+			upstream += fmt.Sprintf(" (%s, %d streams)", k.Bandwidth, k.Tunnels)
+		}
+		mst := "No"
+		if k.MSTStreams > 1 {
+			mst = fmt.Sprintf("%d streams", k.MSTStreams)
+		}
+		t.Rows = append(t.Rows, []string{k.Name, upstream, k.Interface.String(), mst, k.PortSummary()})
+	}
+	if err := report.WriteTables(stdout, format, entries, t); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	return ExitOK
+}
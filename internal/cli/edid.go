@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/edid"
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "edid",
+		Summary: "Parse a binary or hex EDID dump into the displays it advertises",
+		Run:     runEDID,
+	})
+}
+
+type edidTiming struct {
+	Source       string         `json:"source" yaml:"source"`
+	Display      report.Display `json:"display" yaml:"display"`
+	Refresh      float64        `json:"refresh_hz" yaml:"refresh_hz"`
+	PixelClock   int            `json:"pixel_clock_hz" yaml:"pixel_clock_hz"`
+	HTotal       int            `json:"htotal" yaml:"htotal"`
+	VTotal       int            `json:"vtotal" yaml:"vtotal"`
+	Preferred    bool           `json:"preferred" yaml:"preferred"`
+	Native       bool           `json:"native" yaml:"native"`
+	Interlaced   bool           `json:"interlaced,omitempty" yaml:"interlaced,omitempty"`
+	YCbCr420     bool           `json:"ycbcr420" yaml:"ycbcr420"`
+	OnlyYCbCr420 bool           `json:"only_ycbcr420,omitempty" yaml:"only_ycbcr420,omitempty"`
+}
+
+type edidResult struct {
+	Manufacturer string                  `json:"manufacturer" yaml:"manufacturer"`
+	ProductCode  uint16                  `json:"product_code" yaml:"product_code"`
+	Name         string                  `json:"name,omitempty" yaml:"name,omitempty"`
+	Serial       string                  `json:"serial,omitempty" yaml:"serial,omitempty"`
+	Year         int                     `json:"year" yaml:"year"`
+	Version      string                  `json:"version" yaml:"version"`
+	Interface    string                  `json:"interface,omitempty" yaml:"interface,omitempty"`
+	ColorDepths  []int                   `json:"color_depths" yaml:"color_depths"`
+	Encodings    []string                `json:"encodings" yaml:"encodings"`
+	Timings      []edidTiming            `json:"timings" yaml:"timings"`
+	RangeLimits  *edid.RangeLimits       `json:"range_limits,omitempty" yaml:"range_limits,omitempty"`
+	HDMI         *edid.HDMI              `json:"hdmi,omitempty" yaml:"hdmi,omitempty"`
+	HDR          *edid.HDRStaticMetadata `json:"hdr,omitempty" yaml:"hdr,omitempty"`
+	DisplayID    *edid.DisplayID         `json:"displayid,omitempty" yaml:"displayid,omitempty"`
+	Warnings     []string                `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+func runEDID(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("edid", stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gvbc edid [flags] file\n\nParses a binary EDID, or a hex dump of one, into the displays it advertises.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	format := report.FormatText
+	formatFlag(fs, &format)
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+	e, err := edid.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if err := writeEDID(stdout, format, e); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	return ExitOK
+}
+
+func writeEDID(w io.Writer, format report.Format, e *edid.EDID) error {
+	res := edidResult{
+		Manufacturer: e.Manufacturer,
+		ProductCode:  e.ProductCode,
+		Name:         e.Name,
+		Serial:       e.Serial,
+		Year:         e.Year,
+		Version:      fmt.Sprintf("%d.%d", e.Version, e.Revision),
+		Interface:    e.Interface,
+		ColorDepths:  e.ColorDepths(),
+		RangeLimits:  e.RangeLimits,
+		DisplayID:    e.DisplayID,
+		Warnings:     e.Warnings,
+	}
+	var encodings []string
+	for _, enc := range e.Encodings() {
+		res.Encodings = append(res.Encodings, enc.ShortName())
+		encodings = append(encodings, enc.String())
+	}
+	if e.CTA != nil {
+		res.HDMI, res.HDR = e.CTA.HDMI, e.CTA.HDR
+	}
+
+	colorDepth, err := video.ColorDepthFromBPC(e.BitDepth)
+	if err != nil {
+		colorDepth = video.ColorDepth8bit()
+	}
+	timings := report.Table{
+		Title:  "Timings",
+		Header: []string{"source", "mode", "pixel clock", "total", "timing", "flags"},
+	}
+	for _, t := range e.Timings {
+		et := edidTiming{
+			Source:       t.Source,
+			Display:      report.NewDisplay(t.Display(colorDepth)),
+			Refresh:      t.Timing.RefreshRate(),
+			PixelClock:   t.Timing.PixelClock,
+			HTotal:       t.Timing.HTotal(),
+			VTotal:       t.Timing.VTotal(),
+			Preferred:    t.Preferred,
+			Native:       t.Native,
+			Interlaced:   t.Interlaced,
+			YCbCr420:     t.YCbCr420,
+			OnlyYCbCr420: t.OnlyYCbCr420,
+		}
+		res.Timings = append(res.Timings, et)
+		var flags []string
+		for _, f := range []struct {
+			set  bool
+			name string
+		}{{t.Preferred, "preferred"}, {t.Native, "native"}, {t.OnlyYCbCr420, "4:2:0 only"}, {t.YCbCr420 && !t.OnlyYCbCr420, "4:2:0"}} {
+			if f.set {
+				flags = append(flags, f.name)
+			}
+		}
+		timings.Rows = append(timings.Rows, []string{t.Source, t.String(),
+			fmt.Sprintf("%.2f MHz", float64(t.Timing.PixelClock)/1e6),
+			fmt.Sprintf("%dx%d", et.HTotal, et.VTotal), t.Timing.String(), strings.Join(flags, ", ")})
+	}
+
+	caps := report.Table{Title: "Capabilities", Header: []string{"capability", "value"}}
+	add := func(name, format string, args ...any) {
+		caps.Rows = append(caps.Rows, []string{name, fmt.Sprintf(format, args...)})
+	}
+	add("color depths", "%s bpc", joinInts(res.ColorDepths))
+	add("encodings", "%s", strings.Join(encodings, ", "))
+	if r := e.RangeLimits; r != nil {
+		add("vertical rate", "%d-%d Hz", r.MinVRate, r.MaxVRate)
+		add("horizontal rate", "%d-%d kHz", r.MinHRate, r.MaxHRate)
+		if r.MaxPixelClock > 0 {
+			add("max pixel clock", "%d MHz", r.MaxPixelClock/1e6)
+		}
+	}
+	if h := res.HDMI; h != nil {
+		if h.MaxTMDSClock > 0 {
+			add("max TMDS clock", "%d MHz", h.MaxTMDSClock/1e6)
+		}
+		if len(h.DeepColor) > 0 {
+			add("HDMI deep color", "%s bpc", joinInts(h.DeepColor))
+		}
+		if h.Forum {
+			if h.MaxTMDSCharacterRate > 0 {
+				add("max TMDS character rate", "%d MHz", h.MaxTMDSCharacterRate/1e6)
+			}
+			frl := h.FRL()
+			if frl == "" {
+				frl = "No"
+			}
+			add("max FRL rate", "%s", frl)
+			if len(h.DeepColor420) > 0 {
+				add("4:2:0 deep color", "%s bpc", joinInts(h.DeepColor420))
+			}
+			add("ALLM", "%s", yesNo(h.ALLM))
+			if d := h.DSC; d != nil {
+				add("DSC", "1.2a, %s bpc, %d slices, FRL %d", joinInts(d.BPC), d.MaxSlices, d.MaxFRLRate)
+			} else {
+				add("DSC", "No")
+			}
+		}
+	}
+	if d := res.DisplayID; d != nil {
+		add("DisplayID", "%s", d.Version)
+		if t := d.Tile; t != nil {
+			w, h := t.Size()
+			add("tiled display", "%dx%d tiles of %dx%d, %dx%d in all, this is tile %d,%d", t.HTiles, t.VTiles,
+				t.Width, t.Height, w, h, t.HLocation, t.VLocation)
+		}
+		if r := d.Range; r != nil {
+			add("DisplayID vertical rate", "%d-%d Hz", r.MinVRate, r.MaxVRate)
+			add("DisplayID max pixel clock", "%.2f MHz", float64(r.MaxPixelClock)/1e6)
+		}
+		add("DisplayID DSC", "%s", yesNo(d.DSC))
+	}
+	if minRate, maxRate := e.VRR(); maxRate > 0 {
+		add("VRR", "%d-%d Hz", minRate, maxRate)
+	}
+	if h := res.HDR; h != nil {
+		add("HDR EOTFs", "%s", strings.Join(h.EOTFs, ", "))
+		if h.MaxLuminance > 0 {
+			add("HDR luminance", "%.0f cd/m² max, %.0f frame average, %.4f min", h.MaxLuminance, h.MaxFrameAverage, h.MinLuminance)
+		}
+	}
+	tables := []report.Table{timings, caps}
+	if len(e.Warnings) > 0 {
+		warnings := report.Table{Title: "Warnings", Header: []string{"warning"}}
+		for _, msg := range e.Warnings {
+			warnings.Rows = append(warnings.Rows, []string{msg})
+		}
+		tables = append(tables, warnings)
+	}
+	if format == report.FormatText {
+		name := e.Name
+		if name == "" {
+			name = "Unnamed display"
+		}
+		fmt.Fprintf(w, "%s (%s %04X), EDID %s", name, e.Manufacturer, e.ProductCode, res.Version)
+		if e.Interface != "" {
+			fmt.Fprintf(w, ", %s", e.Interface)
+		}
+		if e.WidthCM > 0 && e.HeightCM > 0 {
+			fmt.Fprintf(w, ", %dx%d cm", e.WidthCM, e.HeightCM)
+		}
+		fmt.Fprintf(w, ", %d\n\n", e.Year)
+	}
+	return report.WriteTables(w, format, res, tables...)
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = fmt.Sprint(v)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/edid"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "edid-gen",
+		Summary: "Generate an EDID advertising the given modes",
+		Run:     runEDIDGen,
+	})
+}
+
+func runEDIDGen(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("edid-gen", stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gvbc edid-gen [flags] (WxH@R ... | -sink name)\n\n"+
+			"Generates an EDID 1.4 with a CTA-861 extension advertising the modes, the first\n"+
+			"one preferred, for drm.edid_firmware or a CRU import.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	// Modes come from the arguments, the other display flags apply to all.
+	df := displayFlags{}
+	fs.IntVar(&df.bpc, "bpc", 8, "The color depth in bits per component (8, 10, 12 or 16)")
+	fs.StringVar(&df.encoding, "encoding", "rgb", "The pixel encoding (rgb, 444, 422 or 420)")
+	fs.StringVar(&df.timing, "timing", "cvt-rbv2", "The timing standard ("+strings.Join(timingNames(), ", ")+")")
+	var ifaces interfaceFlags
+	ifaces.register(fs)
+	sinkFlag := fs.String("sink", "", "Advertise the modes of this catalog sink `name`")
+	name := fs.String("name", "", "The monitor name, at most 13 characters (default the sink name or gvbc)")
+	manufacturer := fs.String("manufacturer", "GVB", "The three-letter manufacturer `ID`")
+	product := fs.Uint("product", 0, "The product code")
+	vics := fs.Bool("vic", true, "Send modes with a CTA-861 video code at the CTA timing")
+	out := fs.String("o", "", "Write to this `file` instead of stdout")
+	as := fs.String("as", "hex", "The output format (bin, hex or c)")
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	if (fs.NArg() == 0) == (*sinkFlag == "") {
+		fmt.Fprintln(stderr, "either modes or -sink is required")
+		return ExitUsage
+	}
+	if *product > 0xffff {
+		fmt.Fprintln(stderr, "-product must fit 16 bits")
+		return ExitUsage
+	}
+	selected, err := ifaces.resolve()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if len(selected) == 0 {
+		ifaces.selections = [][2]string{{"HDMI", "2.0"}}
+		if selected, err = ifaces.resolve(); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+	}
+	if len(selected) > 1 {
+		fmt.Fprintln(stderr, "select a single interface")
+		return ExitUsage
+	}
+
+	o := edid.Options{Name: *name, Manufacturer: *manufacturer, ProductCode: uint16(*product), VICs: *vics}
+	for _, i := range selected {
+		o.Interface = i
+	}
+	modes := fs.Args()
+	if *sinkFlag != "" {
+		s, ok := video.LookupSink(*sinkFlag)
+		if !ok {
+			fmt.Fprintf(stderr, "unknown sink %q\n", *sinkFlag)
+			return ExitUsage
+		}
+		for _, m := range s.Modes {
+			modes = append(modes, fmt.Sprint(m))
+		}
+		if s.MaxBPC > 0 {
+			df.bpc = min(df.bpc, s.MaxBPC)
+		}
+		if o.Name == "" {
+			o.Name = s.Name[:min(len(s.Name), 13)]
+		}
+	}
+	var displays []video.Display
+	for _, v := range modes {
+		m, err := video.ParseSinkMode(v)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+		df.width, df.height, df.refresh, df.interlaced, df.fractional = m.Width, m.Height, m.RefreshRate, m.Interlaced, false
+		d, err := df.display()
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+		displays = append(displays, d)
+	}
+
+	data, err := edid.Build(displays, o)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	var text string
+	switch *as {
+	case "bin":
+		text = string(data)
+	case "hex":
+		text = hexDump(data)
+	case "c":
+		text = cArray(data, modes)
+	default:
+		fmt.Fprintf(stderr, "unknown output format %q\n", *as)
+		return ExitUsage
+	}
+	if *out == "" {
+		fmt.Fprint(stdout, text)
+		return ExitOK
+	}
+	if err := os.WriteFile(*out, []byte(text), 0o644); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	return ExitOK
+}
+
+// hexDump writes 16 space-separated bytes per line, which gvbc edid and
+// CRU both read back.
+func hexDump(data []byte) string {
+	var b strings.Builder
+	for i, v := range data {
+		sep := " "
+		if i%16 == 15 || i == len(data)-1 {
+			sep = "\n"
+		}
+		fmt.Fprintf(&b, "%02x%s", v, sep)
+	}
+	return b.String()
+}
+
+func cArray(data []byte, modes []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "/* Generated by gvbc edid-gen: %s */\n", strings.Join(modes, ", "))
+	fmt.Fprintf(&b, "unsigned char edid[%d] = {\n", len(data))
+	for i := 0; i < len(data); i += 8 {
+		var row []string
+		for _, v := range data[i:min(i+8, len(data))] {
+			row = append(row, fmt.Sprintf("0x%02x", v))
+		}
+		fmt.Fprintf(&b, "\t%s,\n", strings.Join(row, ", "))
+	}
+	b.WriteString("};\n")
+	return b.String()
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/edid"
+	"github.com/aloababa/gvbc/internal/export"
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "export",
+		Summary: "Print the sway, Hyprland, kanshi, xorg.conf or kernel line setting a display",
+		Run:     runExport,
+	})
+}
+
+type exportEntry struct {
+	Format string `json:"format" yaml:"format"`
+	Config string `json:"config" yaml:"config"`
+}
+
+type exportResult struct {
+	Output  string         `json:"output" yaml:"output"`
+	Display report.Display `json:"display" yaml:"display"`
+	Custom  bool           `json:"custom" yaml:"custom"`
+	Configs []exportEntry  `json:"configs" yaml:"configs"`
+}
+
+func runExport(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("export", stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gvbc export [flags]\n\n"+
+			"Prints the configuration setting the display on an output. Modelines, modes the\n"+
+			"monitor does not advertise per -edid or -sink, and -custom modes are added from\n"+
+			"their exact timing. A format that cannot set the mode, such as kanshi for an\n"+
+			"interlaced one, is left out and the exit status is 2.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	var df displayFlags
+	df.register(fs)
+	var names []string
+	for _, e := range export.Exporters() {
+		names = append(names, e.Name)
+	}
+	to := fs.String("to", "all", "The configuration format ("+strings.Join(names, ", ")+" or all)")
+	output := fs.String("output", "DP-1", "The DRM connector `name`, e.g. DP-1 or HDMI-A-1")
+	edidFlag := fs.String("edid", "", "The EDID `file` of the monitor, to tell whether the mode is custom")
+	sinkFlag := fs.String("sink", "", "The catalog sink `name` of the monitor, to tell whether the mode is custom")
+	custom := fs.Bool("custom", false, "Add the mode from its exact timing whatever the monitor advertises")
+	format := report.FormatText
+	formatFlag(fs, &format)
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	d, err := df.display()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	exporters := export.Exporters()
+	if *to != "all" {
+		e, ok := export.Lookup(*to)
+		if !ok {
+			fmt.Fprintf(stderr, "unknown format %q\n", *to)
+			return ExitUsage
+		}
+		exporters = []export.Exporter{e}
+	}
+	if *edidFlag != "" && *sinkFlag != "" {
+		fmt.Fprintln(stderr, "-edid and -sink are exclusive")
+		return ExitUsage
+	}
+	// A pasted modeline is custom unless the monitor says otherwise.
+	o := export.Output{Connector: *output, Display: d, Custom: *custom || df.modeline != ""}
+	var sink video.Sink
+	switch {
+	case *edidFlag != "":
+		e, err := edid.ReadFile(*edidFlag)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+		sink = e.Sink()
+		o.Custom = *custom || !sink.Advertises(d)
+	case *sinkFlag != "":
+		var ok bool
+		if sink, ok = video.LookupSink(*sinkFlag); !ok {
+			fmt.Fprintf(stderr, "unknown sink %q\n", *sinkFlag)
+			return ExitUsage
+		}
+		o.Custom = *custom || !sink.Advertises(d)
+	}
+
+	res := exportResult{Output: o.Connector, Display: report.NewDisplay(d), Custom: o.Custom}
+	t := report.Table{Header: []string{"format", "config"}}
+	// A format that cannot set the mode is left out, and fails the command
+	// once the others are printed.
+	var exported []export.Exporter
+	var problems []string
+	for _, e := range exporters {
+		config, err := e.Export(o)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		exported = append(exported, e)
+		res.Configs = append(res.Configs, exportEntry{Format: e.Name, Config: config})
+		t.Rows = append(t.Rows, []string{e.Name, strings.TrimSuffix(config, "\n")})
+	}
+	for _, p := range problems {
+		fmt.Fprintln(stderr, p)
+	}
+	if len(exported) == 0 {
+		return ExitUsage
+	}
+	if format != report.FormatText {
+		if err := report.WriteTables(stdout, format, res, t); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+		return exportExit(problems)
+	}
+	// Text output pastes as is: one block per format, titled by a comment.
+	for i, e := range res.Configs {
+		if len(res.Configs) > 1 {
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			fmt.Fprintf(stdout, "# %s\n", exported[i].Summary)
+		}
+		fmt.Fprint(stdout, e.Config)
+	}
+	if o.Custom && sink.Name != "" {
+		fmt.Fprintf(stderr, "%s does not advertise %s, exporting a custom mode\n", sink.Name, d.Mode())
+	}
+	return exportExit(problems)
+}
+
+// exportExit is the exit status of an export: a usage error when any
+// format could not set the mode.
+func exportExit(problems []string) int {
+	if len(problems) > 0 {
+		return ExitUsage
+	}
+	return ExitOK
+}
@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/aloababa/gvbc/internal/drm"
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "inspect",
+		Summary: "Check the current mode of every connected DRM output",
+		Run:     runInspect,
+	})
+}
+
+type inspectOutput struct {
+	Connector string          `json:"connector" yaml:"connector"`
+	Card      string          `json:"card" yaml:"card"`
+	Status    string          `json:"status" yaml:"status"`
+	Monitor   string          `json:"monitor,omitempty" yaml:"monitor,omitempty"`
+	Display   *report.Display `json:"display,omitempty" yaml:"display,omitempty"`
+	Refresh   float64         `json:"refresh_hz,omitempty" yaml:"refresh_hz,omitempty"`
+	// Guessed is set when the current mode is the preferred one of the
+	// EDID, debugfs being unreadable.
+	Guessed   bool   `json:"guessed,omitempty" yaml:"guessed,omitempty"`
+	Bandwidth uint64 `json:"bandwidth_bps,omitempty" yaml:"bandwidth_bps,omitempty"`
+	// Link is the link the connector trained to, e.g. HBR3 x4, when
+	// debugfs tells it.
+	Link string `json:"link,omitempty" yaml:"link,omitempty"`
+	// Interface and Mode are the version and link mode the mode is checked
+	// against: the trained link, or the version selected with a flag.
+	Interface string `json:"interface,omitempty" yaml:"interface,omitempty"`
+	Mode      string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	// Needs is the slowest link that carries the mode, among the versions
+	// the monitor accepts.
+	Needs    string        `json:"needs,omitempty" yaml:"needs,omitempty"`
+	Fit      *video.Status `json:"fit,omitempty" yaml:"fit,omitempty"`
+	Usage    float64       `json:"usage,omitempty" yaml:"usage,omitempty"`
+	Problems []string      `json:"problems,omitempty" yaml:"problems,omitempty"`
+}
+
+func runInspect(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("inspect", stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gvbc inspect [flags]\n\n"+
+			"Reads every connected output from /sys/class/drm, its EDID and its current\n"+
+			"mode, and checks the mode against the link the connector trained to, which\n"+
+			"amdgpu tells in debugfs, or else the version selected with -dp, -hdmi or\n"+
+			"-interface. Without either, the link is unknown: inspect only tells the\n"+
+			"slowest link the monitor accepts that carries the mode.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	root := fs.String("root", "/", "The `directory` holding sys/class/drm and sys/kernel/debug/dri, e.g. a captured copy")
+	bpc := fs.Int("bpc", 0, "The color depth in bits per component (default the max bpc of the connector, capped by the panel, or 8)")
+	all := fs.Bool("all", false, "Also list disconnected outputs")
+	var ifaces interfaceFlags
+	ifaces.register(fs)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	selected, err := ifaces.resolve()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	connectors, err := drm.Connectors(*root)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+
+	outputs := []inspectOutput{}
+	t := report.Table{Header: []string{"output", "status", "monitor", "mode", "bandwidth", "link", "needs", "usage", "fit"}}
+	var summaries []string
+	allFit := true
+	for _, c := range connectors {
+		if !c.Connected() && !*all {
+			continue
+		}
+		o := inspectOutput{Connector: c.Name, Card: c.Card, Status: c.Status}
+		row := []string{c.Card + "-" + c.Name, c.Status, "-", "-", "-", "-", "-", "-", "-"}
+		if c.EDID != nil {
+			o.Monitor = c.EDID.Sink().Name
+			row[2] = o.Monitor
+		} else if c.EDIDError != nil {
+			o.Problems = append(o.Problems, "unreadable EDID: "+c.EDIDError.Error())
+		}
+		switch {
+		case c.Connected() && c.Mode == nil && c.StateRead:
+			o.Problems = append(o.Problems, "connected but off")
+		case c.Connected() && c.Mode == nil:
+			o.Problems = append(o.Problems, "current mode unknown, debugfs is not readable and the EDID has no preferred mode")
+		}
+		if c.Connected() && c.Mode != nil {
+			d, err := inspectDisplay(c, *bpc)
+			if err != nil {
+				fmt.Fprintln(stderr, err)
+				return ExitUsage
+			}
+			rd := report.NewDisplay(d)
+			o.Display, o.Refresh, o.Guessed = &rd, c.Mode.RefreshRate(), c.CurrentModeGuessed
+			o.Bandwidth = uint64(d.Bandwidth().Bits)
+			row[3] = fmt.Sprintf("%dx%d%s@%.2f %d bpc", d.Width, d.Height, video.ScanSuffix(d.Interlaced), o.Refresh, d.ColorDepth.BPC())
+			if o.Guessed {
+				row[3] += " (preferred)"
+			}
+			row[4] = d.Bandwidth().String()
+			needs, found := inspectNeeds(c, d, selected)
+			if found {
+				o.Needs = needs.Interface.String() + " " + needs.Mode.GetName()
+				row[6] = o.Needs
+			}
+			if c.Link != nil {
+				o.Link = c.Link.LinkName()
+				row[5] = o.Link
+			}
+			_, chosen := selected[c.Family()]
+			comp, checked := video.Compatibility{}, false
+			switch {
+			case c.Link != nil:
+				var err error
+				if comp, err = inspectTrained(c, d, selected); err != nil {
+					o.Problems = append(o.Problems, err.Error())
+				} else {
+					checked = true
+					summaries = append(summaries, fmt.Sprintf("%s is at %.0f%% of %s (%s)", c.Name, comp.Usage, o.Link, comp.Interface))
+				}
+			case chosen && found:
+				comp, checked = needs, true
+				row[5] = o.Needs
+				summaries = append(summaries, fmt.Sprintf("%s is at %.0f%% of %s effective (%s)", c.Name, comp.Usage, comp.Mode.GetName(), comp.Interface))
+			case found:
+				o.Fit = &needs.Status
+				row[8] = needs.Status.String()
+				allFit = allFit && needs.Status.Fits()
+				o.Problems = append(o.Problems, "trained link unknown: only amdgpu tells it in debugfs, usage is not checked")
+				summaries = append(summaries, fmt.Sprintf("%s needs %s, its trained link is unknown", c.Name, o.Needs))
+			default:
+				o.Problems = append(o.Problems, fmt.Sprintf("no %s link to check against", c.Type()))
+			}
+			if checked {
+				o.Interface, o.Mode, o.Fit, o.Usage = comp.Interface.String(), comp.Mode.GetName(), &comp.Status, comp.Usage
+				row[7], row[8] = fmt.Sprintf("%.1f%%", o.Usage), comp.Status.String()
+				allFit = allFit && comp.Status.Fits()
+			}
+		}
+		outputs = append(outputs, o)
+		t.Rows = append(t.Rows, row)
+	}
+
+	problems := report.Table{Title: "Problems", Header: []string{"output", "problem"}}
+	for _, o := range outputs {
+		for _, p := range o.Problems {
+			problems.Rows = append(problems.Rows, []string{o.Card + "-" + o.Connector, p})
+		}
+	}
+	tables := []report.Table{t}
+	if len(problems.Rows) > 0 {
+		tables = append(tables, problems)
+	}
+	if format == report.FormatText {
+		if len(outputs) == 0 {
+			fmt.Fprintf(stdout, "no connected output under %s\n", *root)
+			return ExitOK
+		}
+		for _, s := range summaries {
+			fmt.Fprintln(stdout, s)
+		}
+		if len(summaries) > 0 {
+			fmt.Fprintln(stdout)
+		}
+	}
+	if err := report.WriteTables(stdout, format, outputs, tables...); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if !allFit {
+		return ExitNoFit
+	}
+	return ExitOK
+}
+
+// inspectDisplay is the current mode of c at bpc, or else at the max bpc
+// of the connector capped by the bit depth of the panel, or 8 bpc.
+func inspectDisplay(c drm.Connector, bpc int) (video.Display, error) {
+	if bpc == 0 {
+		bpc = 8
+		if c.MaxBPC > 0 && c.EDID != nil && c.EDID.BitDepth > 0 {
+			bpc = min(c.MaxBPC, c.EDID.BitDepth)
+		}
+	}
+	colorDepth, err := video.ColorDepthFromBPC(bpc)
+	if err != nil {
+		return video.Display{}, err
+	}
+	return c.Mode.Display(colorDepth, video.EncodingRGB), nil
+}
+
+// inspectTrained checks d against the link c trained to, on the selected
+// version of DisplayPort or else the oldest one with the link mode.
+func inspectTrained(c drm.Connector, d video.Display, selected map[string]video.Interface) (video.Compatibility, error) {
+	f, ok := video.LookupInterfaceFamily(c.Family())
+	if !ok || f.Name != "DisplayPort" || len(f.Versions) == 0 {
+		return video.Compatibility{}, fmt.Errorf("no %s link to check against", c.Type())
+	}
+	mode, known := video.LookupLinkMode(c.Link.Rate)
+	if !known {
+		return video.Compatibility{}, fmt.Errorf("link rate %s is not a known link mode", c.Link.Rate)
+	}
+	i, ok := selected[f.Name]
+	if !ok {
+		if i, ok = linkVersion(f.Versions[0], c.Link.Rate); !ok {
+			i = f.Versions[0]
+		}
+	}
+	bandwidth := i.LinkBandwidth(mode, c.Link.Lanes)
+	comp := video.Compatibility{
+		Interface: i,
+		Mode:      mode,
+		Usage:     float64(d.Bandwidth().Bits) * 100 / float64(bandwidth.Bits),
+		HDR:       i.CanHDR(d.ColorDepth),
+		Status:    video.StatusOK,
+	}
+	switch {
+	case d.Bandwidth().Bits <= bandwidth.Bits:
+	case !i.DSC:
+		comp.Status = video.StatusNoDSC
+	case d.DSC().Bits <= bandwidth.Bits:
+		comp.Status = video.StatusDSC
+	default:
+		comp.Status = video.StatusBandwidth
+	}
+	return comp, nil
+}
+
+// inspectNeeds checks d against the selected version of the family of the
+// connector, or else the slowest link mode that carries d uncompressed
+// among the versions the monitor accepts, then with DSC, then the newest.
+func inspectNeeds(c drm.Connector, d video.Display, selected map[string]video.Interface) (video.Compatibility, bool) {
+	f, ok := video.LookupInterfaceFamily(c.Family())
+	if !ok {
+		return video.Compatibility{}, false
+	}
+	if i, ok := selected[f.Name]; ok {
+		mode := i.LowestCompatibleMode(d)
+		if mode == nil {
+			return video.Compatibility{}, false
+		}
+		return i.Check(d, mode), true
+	}
+	if c.EDID != nil {
+		if restricted, ok := c.EDID.Sink().Family(f); ok {
+			f = restricted
+		}
+	}
+	results := f.Evaluate(d)
+	if len(results) == 0 {
+		return video.Compatibility{}, false
+	}
+	best := results[0]
+	for _, r := range results[1:] {
+		switch {
+		case r.Status == video.StatusOK && (best.Status != video.StatusOK || r.Usage > best.Usage):
+			best = r
+		case r.Status == video.StatusDSC && !best.Status.Fits():
+			best = r
+		}
+	}
+	return best, true
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "link",
+		Summary: "Predict the link configuration drivers negotiate for a display",
+		Run:     runLink,
+	})
+}
+
+type linkRow struct {
+	Policy          string  `json:"policy" yaml:"policy"`
+	Family          string  `json:"family" yaml:"family"`
+	Version         string  `json:"version" yaml:"version"`
+	Negotiated      bool    `json:"negotiated" yaml:"negotiated"`
+	Mode            string  `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Lanes           int     `json:"lanes,omitempty" yaml:"lanes,omitempty"`
+	Bandwidth       uint64  `json:"bandwidth_bps,omitempty" yaml:"bandwidth_bps,omitempty"`
+	BPC             int     `json:"bpc,omitempty" yaml:"bpc,omitempty"`
+	Encoding        string  `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	DSCBitsPerPixel float64 `json:"dsc_bits_per_pixel,omitempty" yaml:"dsc_bits_per_pixel,omitempty"`
+	Usage           float64 `json:"usage_percent,omitempty" yaml:"usage_percent,omitempty"`
+}
+
+func runLink(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("link", stderr)
+	var df displayFlags
+	df.register(fs)
+	var ifaces interfaceFlags
+	ifaces.register(fs)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	policyName := fs.String("policy", "", "The driver policy to emulate (default all)")
+	sourceCaps := fs.String("source", "", "The source link capabilities, e.g. `rate=HBR3,lanes=4,bpc=10,dsc=no,420=no`")
+	sinkCaps := fs.String("sink", "", "The sink link capabilities, in the -source syntax")
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	d, err := df.display()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	selected, err := ifaces.resolve()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	source, err := video.ParseLinkCaps(*sourceCaps)
+	if err != nil {
+		fmt.Fprintf(stderr, "-source: %v\n", err)
+		return ExitUsage
+	}
+	sink, err := video.ParseLinkCaps(*sinkCaps)
+	if err != nil {
+		fmt.Fprintf(stderr, "-sink: %v\n", err)
+		return ExitUsage
+	}
+	policies := video.Policies()
+	if *policyName != "" {
+		p, ok := video.LookupPolicy(*policyName)
+		if !ok {
+			fmt.Fprintf(stderr, "unknown policy %q (want %s)\n", *policyName, strings.Join(policyNames(), ", "))
+			return ExitUsage
+		}
+		policies = []video.Policy{p}
+	}
+
+	var rows []linkRow
+	var tables []report.Table
+	negotiated := false
+	for _, fam := range video.InterfaceFamilies() {
+		i, ok := selected[fam.Name]
+		if !ok {
+			if len(selected) > 0 || len(fam.Versions) == 0 {
+				continue
+			}
+			i = fam.Versions[0]
+		}
+		t := report.Table{
+			Title:  i.String(),
+			Header: []string{"policy", "link", "bandwidth", "bpc", "encoding", "dsc", "usage"},
+		}
+		for _, p := range policies {
+			row := linkRow{Policy: p.Name, Family: i.Family, Version: i.Version}
+			c, ok := p.Negotiate(d, i, source, sink)
+			if !ok {
+				rows = append(rows, row)
+				t.Rows = append(t.Rows, []string{p.Name, "-", "-", "-", "-", "-", "-"})
+				continue
+			}
+			negotiated = true
+			row.Negotiated = true
+			row.Mode = c.Mode.GetName()
+			row.Lanes = c.Lanes
+			row.Bandwidth = uint64(c.Bandwidth.Bits)
+			row.BPC = c.Display.ColorDepth.BPC()
+			row.Encoding = c.Display.Encoding.ShortName()
+			row.DSCBitsPerPixel = c.DSCBitsPerPixel
+			row.Usage = c.Usage()
+			rows = append(rows, row)
+			dsc := "No"
+			if c.DSCBitsPerPixel > 0 {
+				dsc = fmt.Sprintf("%g bpp", c.DSCBitsPerPixel)
+			}
+			t.Rows = append(t.Rows, []string{p.Name, c.LinkName(), c.Bandwidth.String(),
+				fmt.Sprint(row.BPC), c.Display.Encoding.String(), dsc, fmt.Sprintf("%.1f%%", row.Usage)})
+		}
+		tables = append(tables, t)
+	}
+	if format == report.FormatText {
+		fmt.Fprintf(stdout, "Negotiated link for %s, %s, %s\n\n", d.Mode(), d.ColorDepth, d.Timing)
+	}
+	if err := report.WriteTables(stdout, format, rows, tables...); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if !negotiated {
+		return ExitNoFit
+	}
+	return ExitOK
+}
+
+func policyNames() []string {
+	var names []string
+	for _, p := range video.Policies() {
+		names = append(names, p.Name)
+	}
+	return names
+}
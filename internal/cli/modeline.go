@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "modeline",
+		Summary: "Generate the X11 modelines of a display, one per timing standard",
+		Run:     runModeline,
+	})
+}
+
+type modelineEntry struct {
+	Timing     string  `json:"timing" yaml:"timing"`
+	Name       string  `json:"name" yaml:"name"`
+	Modeline   string  `json:"modeline" yaml:"modeline"`
+	PixelClock int     `json:"pixel_clock_hz" yaml:"pixel_clock_hz"`
+	Refresh    float64 `json:"refresh_hz" yaml:"refresh_hz"`
+	HSync      float64 `json:"hsync_khz" yaml:"hsync_khz"`
+}
+
+func runModeline(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("modeline", stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gvbc modeline [flags]\n\n"+
+			"Prints the X11 modelines of a display, like cvt, for xorg.conf or xrandr --newmode:\n"+
+			"one per timing standard, or the -timing one only when it is given.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	var df displayFlags
+	df.register(fs)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	d, err := df.display()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	displays := []video.Display{d}
+	timingSet := false
+	fs.Visit(func(f *flag.Flag) { timingSet = timingSet || f.Name == "timing" })
+	if !timingSet && df.modeline == "" {
+		// Every standard that has a timing for this mode.
+		displays = nil
+		for _, t := range video.Timings() {
+			d.Timing = t
+			if video.CheckTiming(d) == nil {
+				displays = append(displays, d)
+			}
+		}
+	}
+
+	var entries []modelineEntry
+	for _, d := range displays {
+		t := d.ExactTiming()
+		name := d.ModelineName()
+		if df.modeline != "" {
+			if _, n, _ := video.ParseModeline(df.modeline); n != "" {
+				name = n
+			}
+		}
+		entries = append(entries, modelineEntry{
+			Timing:     d.Timing.String(),
+			Name:       name,
+			Modeline:   t.Modeline(name),
+			PixelClock: t.PixelClock,
+			Refresh:    t.RefreshRate(),
+			HSync:      float64(t.PixelClock) / float64(t.HTotal()) / 1e3,
+		})
+	}
+	if format != report.FormatText {
+		t := report.Table{Header: []string{"timing", "name", "modeline"}}
+		for _, e := range entries {
+			t.Rows = append(t.Rows, []string{e.Timing, e.Name, e.Modeline})
+		}
+		if err := report.WriteTables(stdout, format, entries, t); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+		return ExitOK
+	}
+	// Comment lines like cvt, so the output pastes into xorg.conf as is.
+	for _, e := range entries {
+		fmt.Fprintf(stdout, "# %dx%d%s %.2f Hz (%s) hsync: %.2f kHz; pclk: %.2f MHz\n",
+			d.Width, d.Height, video.ScanSuffix(d.Interlaced), e.Refresh, e.Timing, e.HSync, float64(e.PixelClock)/1e6)
+		fmt.Fprintln(stdout, e.Modeline)
+	}
+	return ExitOK
+}
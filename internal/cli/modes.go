@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/edid"
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "modes",
+		Summary: "Check every mode a monitor advertises against DisplayPort and HDMI",
+		Run:     runModes,
+	})
+}
+
+type modeCheck struct {
+	Family   string       `json:"family" yaml:"family"`
+	Version  string       `json:"version" yaml:"version"`
+	Mode     string       `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Status   video.Status `json:"status" yaml:"status"`
+	NeedsDSC bool         `json:"needs_dsc" yaml:"needs_dsc"`
+	LowerBPC int          `json:"lower_bpc,omitempty" yaml:"lower_bpc,omitempty"`
+	Usage    float64      `json:"usage" yaml:"usage"`
+}
+
+type modeEntry struct {
+	Source    string         `json:"source,omitempty" yaml:"source,omitempty"`
+	Display   report.Display `json:"display" yaml:"display"`
+	Refresh   float64        `json:"refresh_hz,omitempty" yaml:"refresh_hz,omitempty"`
+	Bandwidth uint64         `json:"bandwidth_bps" yaml:"bandwidth_bps"`
+	Checks    []modeCheck    `json:"checks" yaml:"checks"`
+}
+
+type modesResult struct {
+	Sink  string      `json:"sink" yaml:"sink"`
+	BPC   int         `json:"bpc" yaml:"bpc"`
+	Modes []modeEntry `json:"modes" yaml:"modes"`
+}
+
+func runModes(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("modes", stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gvbc modes (-edid file | -sink name) [flags]\n\n"+
+			"Checks every mode a monitor advertises against the newest DisplayPort and HDMI\n"+
+			"versions it accepts, or the selected ones.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	edidFlag := fs.String("edid", "", "A binary or hex EDID `file`")
+	sinkFlag := fs.String("sink", "", "A catalog sink `name`")
+	bpc := fs.Int("bpc", 0, "The color depth in bits per component (default the deepest the monitor supports)")
+	timingFlag := fs.String("timing", "cvt-rbv2", "The timing standard of catalog modes ("+strings.Join(timingNames(), ", ")+")")
+	var ifaces interfaceFlags
+	ifaces.register(fs)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	if (*edidFlag == "") == (*sinkFlag == "") {
+		fmt.Fprintln(stderr, "exactly one of -edid and -sink is required")
+		return ExitUsage
+	}
+	selected, err := ifaces.resolve()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+
+	var sink video.Sink
+	var displays []video.Display
+	// sources are the EDID blocks the displays come from.
+	var sources []string
+	var e *edid.EDID
+	if *edidFlag != "" {
+		if e, err = edid.ReadFile(*edidFlag); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+		sink = e.Sink()
+		for _, t := range e.Timings {
+			displays = append(displays, t.Display(video.ColorDepth8bit()))
+			sources = append(sources, t.Source)
+		}
+	} else {
+		var ok bool
+		if sink, ok = video.LookupSink(*sinkFlag); !ok {
+			fmt.Fprintf(stderr, "unknown sink %q\n", *sinkFlag)
+			return ExitUsage
+		}
+		timing, ok := video.LookupTiming(*timingFlag)
+		if !ok {
+			fmt.Fprintf(stderr, "unknown timing %q\n", *timingFlag)
+			return ExitUsage
+		}
+		for _, m := range sink.Modes {
+			displays = append(displays, m.Display(video.ColorDepth8bit(), video.EncodingRGB, timing))
+		}
+	}
+	if *bpc == 0 {
+		*bpc = max(sink.MaxBPC, 8)
+	}
+	colorDepth, err := video.ColorDepthFromBPC(*bpc)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	links := modeLinks(sink, selected)
+	if len(links) == 0 {
+		fmt.Fprintf(stderr, "%s has no DisplayPort or HDMI input, select one with -dp, -hdmi or -interface\n", sink.Name)
+		return ExitUsage
+	}
+
+	res := modesResult{Sink: sink.Name, BPC: *bpc}
+	t := report.Table{Header: []string{"mode", "bandwidth"}}
+	if sources != nil {
+		t.Header = append([]string{"source"}, t.Header...)
+	}
+	for _, i := range links {
+		t.Header = append(t.Header, i.String())
+	}
+	allFit := true
+	for j, d := range displays {
+		d.ColorDepth = colorDepth
+		e := modeEntry{Display: report.NewDisplay(d), Bandwidth: uint64(d.Bandwidth().Bits)}
+		scan := video.ScanSuffix(d.Interlaced)
+		mode := fmt.Sprintf("%dx%d%s@%s", d.Width, d.Height, scan, video.FormatRefresh(d.RefreshRate, d.Fractional))
+		if et, ok := d.Timing.(video.ExactTiming); ok {
+			e.Refresh = et.RefreshRate()
+			mode = fmt.Sprintf("%dx%d%s@%.2f", d.Width, d.Height, scan, e.Refresh)
+		}
+		if d.Encoding != video.EncodingRGB {
+			mode += " " + d.Encoding.ShortName()
+		}
+		row := []string{mode, d.Bandwidth().String()}
+		if sources != nil {
+			e.Source = sources[j]
+			row = append([]string{e.Source}, row...)
+		}
+		fits := false
+		for _, i := range links {
+			c := video.CheckMode(d, i)
+			mc := modeCheck{
+				Family:   i.Family,
+				Version:  i.Version,
+				Status:   c.Compatibility.Status,
+				NeedsDSC: c.NeedsDSC(),
+				LowerBPC: c.ColorDepth.BPC(),
+				Usage:    c.Compatibility.Usage,
+			}
+			if c.Compatibility.Mode != nil {
+				mc.Mode = c.Compatibility.Mode.GetName()
+			}
+			fits = fits || mc.Status.Fits()
+			e.Checks = append(e.Checks, mc)
+			cell := mc.Status.String()
+			if mc.LowerBPC > 0 {
+				cell += fmt.Sprintf(" · %d bpc", mc.LowerBPC)
+			}
+			row = append(row, cell)
+		}
+		allFit = allFit && fits
+		res.Modes = append(res.Modes, e)
+		t.Rows = append(t.Rows, row)
+	}
+	// A tile of a tiled display is checked again as the whole display.
+	if e != nil {
+		if tiled, ok := e.TiledDisplay(colorDepth); ok {
+			entry, row, fits := checkTiledModes(tiled, links)
+			allFit = allFit && fits
+			res.Modes = append(res.Modes, entry)
+			t.Rows = append(t.Rows, row)
+		}
+	}
+	if format == report.FormatText {
+		fmt.Fprintf(stdout, "%s at %d bpc: %d modes\n", sink.Name, *bpc, len(displays))
+		fmt.Fprintln(stdout, "❗ (DSC) needs DSC; · N bpc fits uncompressed at that color depth")
+		fmt.Fprintln(stdout)
+	}
+	if err := report.WriteTables(stdout, format, res, t); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if !allFit {
+		return ExitNoFit
+	}
+	return ExitOK
+}
+
+// checkTiledModes checks the whole of a tiled display over one link of
+// each interface per tile.
+func checkTiledModes(tiled video.TiledDisplay, links []video.Interface) (modeEntry, []string, bool) {
+	w, h := tiled.Size()
+	e := modeEntry{Source: "DisplayID tile", Display: report.NewDisplay(tiled.Tile), Bandwidth: uint64(tiled.Bandwidth().Bits)}
+	e.Display.Width, e.Display.Height = w, h
+	row := []string{e.Source, fmt.Sprintf("%dx%d@%d as %dx%d tiles", w, h, tiled.Tile.RefreshRate, tiled.HTiles, tiled.VTiles), tiled.Bandwidth().String()}
+	fits := false
+	for _, i := range links {
+		mc := modeCheck{Family: i.Family, Version: i.Version}
+		checked, err := i.CheckTiles(tiled, tiled.Tiles())
+		if err != nil {
+			mc.Status = video.StatusBandwidth
+		}
+		for _, l := range checked {
+			c := l.Compatibility
+			mc.Status = max(mc.Status, c.Status)
+			mc.NeedsDSC = mc.NeedsDSC || c.Status == video.StatusDSC
+			mc.Usage = max(mc.Usage, c.Usage)
+			if c.Mode != nil {
+				mc.Mode = c.Mode.GetName()
+			}
+		}
+		fits = fits || mc.Status.Fits()
+		e.Checks = append(e.Checks, mc)
+		row = append(row, mc.Status.String())
+	}
+	return e, row, fits
+}
+
+// modeLinks returns the selected version of each family, without DSC when
+// the sink has no decoder, or else the newest version of each DisplayPort
+// or HDMI input of the sink.
+func modeLinks(sink video.Sink, selected map[string]video.Interface) []video.Interface {
+	var out []video.Interface
+	for _, f := range video.InterfaceFamilies() {
+		if i, ok := selected[f.Name]; ok {
+			i.DSC = i.DSC && sink.DSC
+			out = append(out, i)
+			continue
+		}
+		if len(selected) > 0 || !slices.Contains([]string{"DisplayPort", "HDMI"}, f.Name) {
+			continue
+		}
+		if restricted, ok := sink.Family(f); ok && len(restricted.Versions) > 0 {
+			out = append(out, restricted.Versions[0])
+		}
+	}
+	return out
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"io"
+
+	"github.com/aloababa/gvbc/internal/report"
+)
+
+func init() {
+	register(Command{
+		Name:    "schema",
+		Summary: "Print the JSON schema of the structured output formats",
+		Run:     runSchema,
+	})
+}
+
+func runSchema(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("schema", stderr)
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	stdout.Write(report.Schema())
+	return ExitOK
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/setup"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "setup",
+		Summary: "Validate a multi-display setup against the heads, pixel clock and DSC limits of its source",
+		Run:     runSetup,
+	})
+}
+
+type setupOutput struct {
+	Name     string         `json:"name" yaml:"name"`
+	Port     string         `json:"port" yaml:"port"`
+	Display  report.Display `json:"display" yaml:"display"`
+	Mode     string         `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Status   video.Status   `json:"status" yaml:"status"`
+	Streams  int            `json:"streams,omitempty" yaml:"streams,omitempty"`
+	Heads    int            `json:"heads" yaml:"heads"`
+	DSC      bool           `json:"dsc" yaml:"dsc"`
+	Problems []string       `json:"problems,omitempty" yaml:"problems,omitempty"`
+}
+
+type setupResult struct {
+	Source      string        `json:"source" yaml:"source"`
+	OK          bool          `json:"ok" yaml:"ok"`
+	Heads       int           `json:"heads" yaml:"heads"`
+	MaxHeads    int           `json:"max_heads" yaml:"max_heads"`
+	DSCEncoders int           `json:"dsc_encoders" yaml:"dsc_encoders"`
+	MaxEncoders int           `json:"max_dsc_encoders" yaml:"max_dsc_encoders"`
+	Outputs     []setupOutput `json:"outputs" yaml:"outputs"`
+	Problems    []string      `json:"problems,omitempty" yaml:"problems,omitempty"`
+}
+
+func runSetup(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("setup", stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gvbc setup [flags] file\n\nValidates the displays of a YAML setup file against its source.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	format := report.FormatText
+	formatFlag(fs, &format)
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+	s, err := setup.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	u := s.Source.Validate(s.Outputs)
+	if err := writeSourceUsage(stdout, format, u); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if !u.OK() {
+		return ExitNoFit
+	}
+	return ExitOK
+}
+
+func writeSourceUsage(w io.Writer, format report.Format, u video.SourceUsage) error {
+	res := setupResult{
+		Source:      u.Source.Name,
+		OK:          u.OK(),
+		Heads:       u.Heads,
+		MaxHeads:    u.Source.Heads,
+		DSCEncoders: u.DSCEncoders,
+		MaxEncoders: u.Source.DSCEncoders,
+		Problems:    u.Problems,
+	}
+	outputs := report.Table{
+		Title:  u.Source.Name,
+		Header: []string{"output", "port", "display", "interface", "status", "heads", "dsc"},
+	}
+	problems := report.Table{
+		Title:  "Problems",
+		Header: []string{"output", "problem"},
+	}
+	for _, o := range u.Outputs {
+		so := setupOutput{
+			Name:     o.Output.Name,
+			Port:     o.Output.Port,
+			Display:  report.NewDisplay(o.Output.Display),
+			Status:   o.Compatibility.Status,
+			Streams:  o.Output.Streams,
+			Heads:    o.Heads,
+			DSC:      o.DSC,
+			Problems: o.Problems,
+		}
+		iface, status := "-", "-"
+		if o.Compatibility.Mode != nil {
+			so.Mode = o.Compatibility.Mode.GetName()
+			iface = o.Port.Interface.String() + " " + so.Mode
+			status = o.Compatibility.Status.String()
+		}
+		res.Outputs = append(res.Outputs, so)
+		outputs.Rows = append(outputs.Rows, []string{so.Name, so.Port, so.Display.String(), iface, status,
+			fmt.Sprint(so.Heads), yesNo(so.DSC)})
+		for _, p := range o.Problems {
+			problems.Rows = append(problems.Rows, []string{so.Name, p})
+		}
+	}
+	budget := report.Table{
+		Title:  "Budget",
+		Header: []string{"resource", "used", "available"},
+		Rows: [][]string{
+			{"heads", fmt.Sprint(u.Heads), fmt.Sprint(u.Source.Heads)},
+			{"dsc encoders", fmt.Sprint(u.DSCEncoders), fmt.Sprint(u.Source.DSCEncoders)},
+		},
+	}
+	for _, p := range u.Problems {
+		problems.Rows = append(problems.Rows, []string{"-", p})
+	}
+	tables := []report.Table{outputs, budget}
+	if len(problems.Rows) > 0 {
+		tables = append(tables, problems)
+	}
+	return report.WriteTables(w, format, res, tables...)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
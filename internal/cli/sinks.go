@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "sinks",
+		Summary: "Search the monitors and TVs of the catalog",
+		Run:     runSinks,
+	})
+}
+
+type sinkEntry struct {
+	Name     string       `json:"name" yaml:"name"`
+	Category string       `json:"category" yaml:"category"`
+	Modes    []string     `json:"modes" yaml:"modes"`
+	MaxBPC   int          `json:"max_bpc,omitempty" yaml:"max_bpc,omitempty"`
+	DSC      bool         `json:"dsc" yaml:"dsc"`
+	VRRMin   int          `json:"vrr_min_hz,omitempty" yaml:"vrr_min_hz,omitempty"`
+	VRRMax   int          `json:"vrr_max_hz,omitempty" yaml:"vrr_max_hz,omitempty"`
+	Inputs   []sourcePort `json:"inputs" yaml:"inputs"`
+	Quirks   []string     `json:"quirks,omitempty" yaml:"quirks,omitempty"`
+}
+
+func runSinks(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("sinks", stderr)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	sinks := video.SearchSinks(strings.Join(fs.Args(), " "))
+	entries := []sinkEntry{}
+	t := report.Table{
+		Header: []string{"name", "category", "native", "bpc", "dsc", "vrr", "inputs"},
+	}
+	var quirks report.Table
+	quirks.Title = "Quirks"
+	quirks.Header = []string{"name", "quirk"}
+	for _, s := range sinks {
+		e := sinkEntry{
+			Name:     s.Name,
+			Category: s.Category,
+			MaxBPC:   s.MaxBPC,
+			DSC:      s.DSC,
+			VRRMin:   s.VRRMin,
+			VRRMax:   s.VRRMax,
+			Quirks:   s.Quirks,
+		}
+		for _, m := range s.Modes {
+			e.Modes = append(e.Modes, m.String())
+		}
+		for _, p := range s.Inputs {
+			e.Inputs = append(e.Inputs, sourcePort{Name: p.Name, Interface: p.Interface.Family, Version: p.Interface.Version, MaxRate: p.MaxRate.Name})
+		}
+		entries = append(entries, e)
+		vrr := s.VRR()
+		if vrr == "" {
+			vrr = "No"
+		}
+		t.Rows = append(t.Rows, []string{s.Name, s.Category, s.Native().String() + "Hz", fmt.Sprint(s.MaxBPC),
+			yesNo(s.DSC), vrr, s.InputSummary()})
+		for _, q := range s.Quirks {
+			quirks.Rows = append(quirks.Rows, []string{s.Name, q})
+		}
+	}
+	tables := []report.Table{t}
+	if len(quirks.Rows) > 0 {
+		tables = append(tables, quirks)
+	}
+	if err := report.WriteTables(stdout, format, entries, tables...); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if len(sinks) == 0 {
+		return ExitNoFit
+	}
+	return ExitOK
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "solve",
+		Summary: "Find the highest refresh rate or largest resolution that fits each interface",
+		Run:     runSolve,
+	})
+}
+
+type solveLimit struct {
+	Mode    string `json:"mode" yaml:"mode"`
+	Refresh int    `json:"refresh_hz" yaml:"refresh_hz"`
+	Width   int    `json:"width" yaml:"width"`
+	Height  int    `json:"height" yaml:"height"`
+}
+
+func (l *solveLimit) String(target string) string {
+	if l == nil {
+		return "-"
+	}
+	if target == "resolution" {
+		return fmt.Sprintf("%dx%d", l.Width, l.Height)
+	}
+	return fmt.Sprintf("%d Hz", l.Refresh)
+}
+
+type solveRow struct {
+	Family       string      `json:"family" yaml:"family"`
+	Version      string      `json:"version" yaml:"version"`
+	Uncompressed *solveLimit `json:"uncompressed" yaml:"uncompressed"`
+	DSC          *solveLimit `json:"dsc" yaml:"dsc"`
+}
+
+func runSolve(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("solve", stderr)
+	var df displayFlags
+	df.register(fs)
+	var ifaces interfaceFlags
+	ifaces.register(fs)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	target := fs.String("for", "refresh", "What to maximize: refresh (at -width x -height) or resolution (at -refresh and -aspect)")
+	aspect := fs.String("aspect", "16:9", "The aspect ratio `w:h` of the resolution search")
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	d, err := df.display()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	selected, err := ifaces.resolve()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	var aspectW, aspectH int
+	switch *target {
+	case "refresh":
+	case "resolution":
+		if aspectW, aspectH, err = parseAspect(*aspect); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+	default:
+		fmt.Fprintf(stderr, "unknown -for %q (want refresh or resolution)\n", *target)
+		return ExitUsage
+	}
+
+	solve := func(i video.Interface, dsc bool) *solveLimit {
+		if *target == "resolution" {
+			r, mode, ok := i.BestMaxResolution(d, aspectW, aspectH, dsc)
+			if !ok {
+				return nil
+			}
+			return &solveLimit{Mode: mode.GetName(), Refresh: r.RefreshRate, Width: r.Width, Height: r.Height}
+		}
+		n, mode, ok := i.BestMaxRefreshRate(d, dsc)
+		if !ok {
+			return nil
+		}
+		return &solveLimit{Mode: mode.GetName(), Refresh: n, Width: d.Width, Height: d.Height}
+	}
+
+	var rows []solveRow
+	var tables []report.Table
+	found := false
+	for _, fam := range video.InterfaceFamilies() {
+		versions := fam.Versions
+		if i, ok := selected[fam.Name]; ok {
+			versions = []video.Interface{i}
+		} else if len(selected) > 0 {
+			continue
+		}
+		t := report.Table{
+			Title:  fam.Name,
+			Header: []string{"version", "uncompressed", "mode", "dsc", "mode"},
+		}
+		for _, i := range versions {
+			row := solveRow{Family: fam.Name, Version: i.Version, Uncompressed: solve(i, false)}
+			if i.DSC {
+				row.DSC = solve(i, true)
+			}
+			found = found || row.Uncompressed != nil || row.DSC != nil
+			rows = append(rows, row)
+			t.Rows = append(t.Rows, []string{i.Version,
+				row.Uncompressed.String(*target), limitMode(row.Uncompressed),
+				row.DSC.String(*target), limitMode(row.DSC)})
+		}
+		tables = append(tables, t)
+	}
+	if format == report.FormatText {
+		if *target == "resolution" {
+			fmt.Fprintf(stdout, "Largest %s resolution at %sHz, %s, %s\n\n", *aspect, video.FormatRefresh(d.RefreshRate, d.Fractional), d.ColorDepth, d.Timing)
+		} else {
+			fmt.Fprintf(stdout, "Highest refresh rate at %dx%d, %s, %s\n\n", d.Width, d.Height, d.ColorDepth, d.Timing)
+		}
+	}
+	if err := report.WriteTables(stdout, format, rows, tables...); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if !found {
+		return ExitNoFit
+	}
+	return ExitOK
+}
+
+func limitMode(l *solveLimit) string {
+	if l == nil {
+		return "-"
+	}
+	return l.Mode
+}
+
+func parseAspect(s string) (int, int, error) {
+	w, h, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid aspect ratio %q (want w:h)", s)
+	}
+	aw, err1 := strconv.Atoi(w)
+	ah, err2 := strconv.Atoi(h)
+	if err1 != nil || err2 != nil || aw <= 0 || ah <= 0 {
+		return 0, 0, fmt.Errorf("invalid aspect ratio %q (want w:h)", s)
+	}
+	return aw, ah, nil
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "sources",
+		Summary: "List the GPUs and source devices of the catalog",
+		Run:     runSources,
+	})
+}
+
+type sourcePort struct {
+	Name      string `json:"name" yaml:"name"`
+	Interface string `json:"interface" yaml:"interface"`
+	Version   string `json:"version" yaml:"version"`
+	MaxRate   string `json:"max_rate,omitempty" yaml:"max_rate,omitempty"`
+}
+
+type sourceEntry struct {
+	Name          string       `json:"name" yaml:"name"`
+	Category      string       `json:"category" yaml:"category"`
+	Heads         int          `json:"heads" yaml:"heads"`
+	MaxPixelClock int          `json:"max_pixel_clock_hz" yaml:"max_pixel_clock_hz"`
+	DSCEncoders   int          `json:"dsc_encoders" yaml:"dsc_encoders"`
+	MaxWidth      int          `json:"max_width,omitempty" yaml:"max_width,omitempty"`
+	MaxHeight     int          `json:"max_height,omitempty" yaml:"max_height,omitempty"`
+	MaxRefresh    int          `json:"max_refresh_hz,omitempty" yaml:"max_refresh_hz,omitempty"`
+	Ports         []sourcePort `json:"ports" yaml:"ports"`
+}
+
+func runSources(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("sources", stderr)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	var entries []sourceEntry
+	t := report.Table{
+		Header: []string{"name", "category", "heads", "dsc", "max", "ports"},
+	}
+	for _, s := range video.Sources() {
+		e := sourceEntry{
+			Name:          s.Name,
+			Category:      s.Category,
+			Heads:         s.Heads,
+			MaxPixelClock: s.MaxPixelClock,
+			DSCEncoders:   s.DSCEncoders,
+			MaxWidth:      s.MaxWidth,
+			MaxHeight:     s.MaxHeight,
+			MaxRefresh:    s.MaxRefreshRate,
+		}
+		for _, p := range s.Ports {
+			e.Ports = append(e.Ports, sourcePort{Name: p.Name, Interface: p.Interface.Family, Version: p.Interface.Version, MaxRate: p.MaxRate.Name})
+		}
+		entries = append(entries, e)
+		dsc := "No"
+		if s.DSCEncoders > 0 {
+			dsc = fmt.Sprint(s.DSCEncoders)
+		}
+		t.Rows = append(t.Rows, []string{s.Name, s.Category, fmt.Sprint(s.Heads), dsc,
+			fmt.Sprintf("%dx%d@%dHz", s.MaxWidth, s.MaxHeight, s.MaxRefreshRate), s.PortSummary()})
+	}
+	if err := report.WriteTables(stdout, format, entries, t); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	return ExitOK
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/edid"
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "tiles",
+		Summary: "Check a display driven as several tiles, over separate links or MST",
+		Run:     runTiles,
+	})
+}
+
+type tileLink struct {
+	// Tiles are numbered from 1, left to right then top to bottom.
+	Tiles     []int        `json:"tiles" yaml:"tiles"`
+	Bandwidth uint64       `json:"bandwidth_bps" yaml:"bandwidth_bps"`
+	Mode      string       `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Usage     float64      `json:"usage" yaml:"usage"`
+	Status    video.Status `json:"status" yaml:"status"`
+}
+
+type tilesResult struct {
+	Family  string     `json:"family" yaml:"family"`
+	Version string     `json:"version" yaml:"version"`
+	Fits    bool       `json:"fits" yaml:"fits"`
+	Links   []tileLink `json:"links,omitempty" yaml:"links,omitempty"`
+	Problem string     `json:"problem,omitempty" yaml:"problem,omitempty"`
+}
+
+func runTiles(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("tiles", stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gvbc tiles (-tiles HxV | -edid file) [flags]\n\n"+
+			"Splits the display into tiles of the same size, each sent as its own stream\n"+
+			"with its own timing, and checks the links carrying them: one link per tile by\n"+
+			"default, or fewer links carrying several tiles over DisplayPort MST.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	var df displayFlags
+	df.register(fs)
+	var ifaces interfaceFlags
+	ifaces.register(fs)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	layout := fs.String("tiles", "", "The tile `layout`, columns x rows, e.g. 2x1 for 5K as 2 tiles of 2560x2880")
+	edidFlag := fs.String("edid", "", "A binary or hex EDID `file` of one tile, whose DisplayID tiled display block replaces -tiles, the resolution, refresh rate and timing")
+	links := fs.Int("links", 0, "The number of links carrying the tiles, 1 for a single MST link (default one per tile)")
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	if (*layout == "") == (*edidFlag == "") {
+		fmt.Fprintln(stderr, "exactly one of -tiles and -edid is required")
+		return ExitUsage
+	}
+	d, err := df.display()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	var t video.TiledDisplay
+	if *edidFlag != "" {
+		e, err := edid.ReadFile(*edidFlag)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+		var ok bool
+		if t, ok = e.TiledDisplay(d.ColorDepth); !ok {
+			fmt.Fprintf(stderr, "%s: no DisplayID tile with a timing of its size\n", *edidFlag)
+			return ExitUsage
+		}
+		t.Tile.Encoding = d.Encoding
+		d = t.Tile
+	} else {
+		hTiles, vTiles, err := video.ParseTileLayout(*layout)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+		if t, err = video.NewTiledDisplay(d, hTiles, vTiles); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+	}
+	if *links == 0 {
+		*links = t.Tiles()
+	}
+	if *links < 0 || *links > t.Tiles() {
+		fmt.Fprintf(stderr, "-links must be between 1 and %d\n", t.Tiles())
+		return ExitUsage
+	}
+	selected, err := ifaces.resolve()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+
+	var results []tilesResult
+	var tables []report.Table
+	fits := len(selected) > 0
+	for _, fam := range video.InterfaceFamilies() {
+		i, ok := selected[fam.Name]
+		if !ok {
+			if len(selected) > 0 || len(fam.Versions) == 0 {
+				continue
+			}
+			i = fam.Versions[0]
+		}
+		res := tilesResult{Family: i.Family, Version: i.Version}
+		tbl := report.Table{
+			Title:  i.String(),
+			Header: []string{"link", "tiles", "bandwidth", "mode", "usage", "status"},
+		}
+		checked, err := i.CheckTiles(t, *links)
+		if err != nil {
+			res.Problem = err.Error()
+			tbl.Rows = append(tbl.Rows, []string{"-", "-", "-", "-", "-", err.Error()})
+		}
+		res.Fits = err == nil
+		for n, l := range checked {
+			c := l.Compatibility
+			row := tileLink{
+				Bandwidth: uint64(l.Bandwidth.Bits),
+				Usage:     c.Usage,
+				Status:    c.Status,
+			}
+			mode := "-"
+			if c.Mode != nil {
+				row.Mode = c.Mode.GetName()
+				mode = row.Mode
+			}
+			res.Links = append(res.Links, row)
+			res.Fits = res.Fits && c.Status.Fits()
+			var tiles []string
+			for _, tile := range l.Tiles {
+				row.Tiles = append(row.Tiles, tile+1)
+				tiles = append(tiles, fmt.Sprint(tile+1))
+			}
+			tbl.Rows = append(tbl.Rows, []string{fmt.Sprint(n + 1), strings.Join(tiles, ", "), l.Bandwidth.String(), mode,
+				fmt.Sprintf("%.1f%%", c.Usage), c.Status.String()})
+		}
+		if len(selected) > 0 {
+			fits = fits && res.Fits
+		} else {
+			fits = fits || res.Fits
+		}
+		results = append(results, res)
+		tables = append(tables, tbl)
+	}
+	if format == report.FormatText {
+		fmt.Fprintf(stdout, "%s, %s, %s\n", t, d.ColorDepth, d.Timing)
+		over := fmt.Sprintf("%d links", *links)
+		if *links == 1 {
+			over = "one MST link"
+		}
+		fmt.Fprintf(stdout, "%s per tile, %s in all, over %s\n\n", t.Tile.Bandwidth(), t.Bandwidth(), over)
+	}
+	if err := report.WriteTables(stdout, format, results, tables...); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if !fits {
+		return ExitNoFit
+	}
+	return ExitOK
+}
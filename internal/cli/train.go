@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/aloababa/gvbc/internal/report"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func init() {
+	register(Command{
+		Name:    "train",
+		Summary: "Simulate link training fallback and check the display against the resulting link",
+		Run:     runTrain,
+	})
+}
+
+type trainStep struct {
+	Mode      string `json:"mode" yaml:"mode"`
+	Lanes     int    `json:"lanes,omitempty" yaml:"lanes,omitempty"`
+	Bandwidth uint64 `json:"bandwidth_bps" yaml:"bandwidth_bps"`
+	Trained   bool   `json:"trained" yaml:"trained"`
+}
+
+type trainResult struct {
+	Family          string       `json:"family" yaml:"family"`
+	Version         string       `json:"version" yaml:"version"`
+	Steps           []trainStep  `json:"steps" yaml:"steps"`
+	Trained         bool         `json:"trained" yaml:"trained"`
+	Status          video.Status `json:"status" yaml:"status"`
+	DSCBitsPerPixel float64      `json:"dsc_bits_per_pixel,omitempty" yaml:"dsc_bits_per_pixel,omitempty"`
+	MaxBPC          int          `json:"max_bpc,omitempty" yaml:"max_bpc,omitempty"`
+	MaxRefresh      int          `json:"max_refresh_hz,omitempty" yaml:"max_refresh_hz,omitempty"`
+}
+
+func runTrain(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("train", stderr)
+	var df displayFlags
+	df.register(fs)
+	var ifaces interfaceFlags
+	ifaces.register(fs)
+	format := report.FormatText
+	formatFlag(fs, &format)
+	sourceCaps := fs.String("source", "", "The source link capabilities, e.g. `rate=HBR3,lanes=4,bpc=10,dsc=no`")
+	sinkCaps := fs.String("sink", "", "The sink link capabilities, in the -source syntax")
+	cableCaps := fs.String("cable", "", "The cable rating, in the -source syntax")
+	topsOut := fs.String("tops-out", "", "The fastest link `mode` the cable actually carries")
+	if !parseFlags(fs, args) {
+		return ExitUsage
+	}
+	d, err := df.display()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	selected, err := ifaces.resolve()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	var caps [3]video.LinkCaps
+	for n, c := range []struct{ flag, value string }{{"source", *sourceCaps}, {"sink", *sinkCaps}, {"cable", *cableCaps}} {
+		if caps[n], err = video.ParseLinkCaps(c.value); err != nil {
+			fmt.Fprintf(stderr, "-%s: %v\n", c.flag, err)
+			return ExitUsage
+		}
+	}
+	if _, ok := video.LookupLinkMode(*topsOut); *topsOut != "" && !ok {
+		fmt.Fprintf(stderr, "-tops-out: unknown link mode %q\n", *topsOut)
+		return ExitUsage
+	}
+
+	var results []trainResult
+	var tables []report.Table
+	fits := false
+	for _, fam := range video.InterfaceFamilies() {
+		i, ok := selected[fam.Name]
+		if !ok {
+			if len(selected) > 0 || len(fam.Versions) == 0 {
+				continue
+			}
+			i = fam.Versions[0]
+		}
+		r := video.SimulateLinkTraining(d, i, caps[0], caps[1], caps[2], *topsOut)
+		res := trainResult{
+			Family:          i.Family,
+			Version:         i.Version,
+			Trained:         r.Trained,
+			Status:          r.Status,
+			DSCBitsPerPixel: r.DSCBitsPerPixel,
+			MaxBPC:          r.ColorDepth.BPC(),
+			MaxRefresh:      r.RefreshRate,
+		}
+		t := report.Table{
+			Title:  i.String(),
+			Header: []string{"step", "link", "bandwidth", "result"},
+		}
+		for n, s := range r.Steps {
+			res.Steps = append(res.Steps, trainStep{Mode: s.Mode.GetName(), Lanes: s.Lanes, Bandwidth: uint64(s.Bandwidth.Bits), Trained: s.Trained})
+			result := "failed"
+			if s.Trained {
+				result = "trained"
+			}
+			t.Rows = append(t.Rows, []string{fmt.Sprint(n + 1), s.LinkName(), s.Bandwidth.String(), result})
+		}
+		fits = fits || r.Status.Fits()
+		results = append(results, res)
+		tables = append(tables, t)
+		if format == report.FormatText {
+			t.Title = i.String() + ": " + trainingOutcome(d, r)
+			tables[len(tables)-1] = t
+		}
+	}
+	if format == report.FormatText {
+		fmt.Fprintf(stdout, "Link training for %s, %s, %s\n\n", d.Mode(), d.ColorDepth, d.Timing)
+	}
+	if err := report.WriteTables(stdout, format, results, tables...); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if !fits {
+		return ExitNoFit
+	}
+	return ExitOK
+}
+
+// trainingOutcome summarizes how the display fares on the trained link.
+func trainingOutcome(d video.Display, r video.TrainingResult) string {
+	if !r.Trained {
+		return "link training failed"
+	}
+	link := r.Link().LinkName()
+	switch r.Status {
+	case video.StatusOK:
+		return "fits on " + link
+	case video.StatusDSC:
+		return fmt.Sprintf("needs DSC at %g bpp on %s", r.DSCBitsPerPixel, link)
+	}
+	var fallbacks []string
+	if r.ColorDepth > 0 {
+		fallbacks = append(fallbacks, fmt.Sprintf("%d bpc", r.ColorDepth.BPC()))
+	}
+	if r.RefreshRate > 0 {
+		fallbacks = append(fallbacks, fmt.Sprintf("%d Hz", r.RefreshRate))
+	}
+	if len(fallbacks) == 0 {
+		return "does not fit " + link
+	}
+	out := "does not fit " + link + ", falls back to " + fallbacks[0]
+	if len(fallbacks) > 1 {
+		out += " or " + fallbacks[1]
+	}
+	return out
+}
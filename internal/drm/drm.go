@@ -0,0 +1,265 @@
+// Package drm reads the connectors of the Linux DRM subsystem from sysfs,
+// and their current mode from the atomic state in debugfs.
+package drm
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/edid"
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+// Connector is an output of a DRM card, e.g. card0-DP-1.
+type Connector struct {
+	Card string
+	// Name is the connector name without the card, e.g. DP-1 or HDMI-A-1.
+	Name    string
+	Status  string
+	Enabled bool
+	// Modes are the mode names the connector lists, preferred first.
+	Modes []string
+	// EDID is nil when the connector has none, and EDIDError is set when it
+	// does not parse.
+	EDID      *edid.EDID
+	EDIDError error
+	// Mode is the current mode, nil when the output is off or the mode
+	// unknown. It comes from the atomic state when debugfs is readable,
+	// which sets StateRead, and is otherwise the preferred mode of the
+	// EDID, with CurrentModeGuessed set.
+	Mode               *video.ExactTiming
+	StateRead          bool
+	CurrentModeGuessed bool
+	// MaxBPC is the max bpc property of the connector, zero when unknown.
+	MaxBPC int
+	// Link is the DisplayPort link the connector trained to, from the
+	// link_settings file amdgpu has in debugfs. It is nil when unknown, as
+	// with i915, which has no such file, or when the link is down.
+	Link *Link
+}
+
+// Link is a trained DisplayPort link.
+type Link struct {
+	// Rate is the link mode name, e.g. HBR2, or the dc_link_rate of amdgpu
+	// when it is not one gvbc models.
+	Rate  string
+	Lanes int
+}
+
+func (l Link) LinkName() string {
+	return l.Rate + " x" + strconv.Itoa(l.Lanes)
+}
+
+// Connected reports whether a monitor is plugged in.
+func (c Connector) Connected() bool {
+	return c.Status == "connected"
+}
+
+// Type is the connector type, the name without its index, e.g. HDMI-A.
+func (c Connector) Type() string {
+	return connectorType(c.Name)
+}
+
+// Family is the interface family of the connector type, empty for the
+// types gvbc does not model.
+func (c Connector) Family() string {
+	return connectorFamily(c.Name)
+}
+
+func connectorType(name string) string {
+	if i := strings.LastIndexByte(name, '-'); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+func connectorFamily(name string) string {
+	switch connectorType(name) {
+	case "DP", "eDP", "USB-C":
+		return "DisplayPort"
+	case "HDMI-A", "HDMI-B":
+		return "HDMI"
+	case "DVI-D", "DVI-I":
+		return "DVI"
+	}
+	return ""
+}
+
+// Connectors reads the connectors of every card under root, the root of
+// a live system or of a captured copy of its sysfs and debugfs files.
+func Connectors(root string) ([]Connector, error) {
+	dir := filepath.Join(root, "sys", "class", "drm")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	// states are the current modes and max bpc of each card, by
+	// connector name.
+	states := map[string]map[string]connectorState{}
+	var out []Connector
+	for _, entry := range entries {
+		card, name, ok := strings.Cut(entry.Name(), "-")
+		if !ok || !strings.HasPrefix(card, "card") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		c := Connector{Card: card, Name: name}
+		c.Status = readLine(filepath.Join(path, "status"))
+		c.Enabled = readLine(filepath.Join(path, "enabled")) == "enabled"
+		if modes, err := os.ReadFile(filepath.Join(path, "modes")); err == nil {
+			c.Modes = strings.Fields(string(modes))
+		}
+		if data, err := os.ReadFile(filepath.Join(path, "edid")); err == nil && len(data) > 0 {
+			c.EDID, c.EDIDError = edid.Parse(data)
+		}
+
+		if _, ok := states[card]; !ok {
+			states[card] = readState(root, card)
+		}
+		c.StateRead = states[card] != nil
+		if s, ok := states[card][name]; ok {
+			c.Mode, c.MaxBPC = s.mode, s.maxBPC
+		} else if !c.StateRead && c.Connected() && c.EDID != nil {
+			if i := slices.IndexFunc(c.EDID.Timings, func(t edid.Timing) bool { return t.Preferred }); i >= 0 {
+				mode := c.EDID.Timings[i].Timing
+				c.Mode, c.CurrentModeGuessed = &mode, true
+			}
+		}
+		c.Link = readLinkSettings(root, card, name)
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func readLine(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+type connectorState struct {
+	mode   *video.ExactTiming
+	maxBPC int
+}
+
+// readState reads the atomic state of a card from debugfs, as printed by
+// drm_state_dump: crtc sections with their mode, and connector sections
+// with their crtc. It returns nil when debugfs is not readable.
+func readState(root, card string) map[string]connectorState {
+	data, err := os.ReadFile(filepath.Join(root, "sys", "kernel", "debug", "dri", strings.TrimPrefix(card, "card"), "state"))
+	if err != nil {
+		return nil
+	}
+	modes := map[string]*video.ExactTiming{}
+	connectors := map[string]string{}
+	bpc := map[string]int{}
+	var section, name string
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		text := s.Text()
+		if !strings.HasPrefix(text, "\t") {
+			// Section headers read e.g. "crtc[51]: pipe A".
+			kind, rest, _ := strings.Cut(text, "[")
+			_, name, _ = strings.Cut(rest, ": ")
+			section = kind
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimSpace(text), "=")
+		switch {
+		case section == "crtc" && strings.HasPrefix(text, "\tmode: "):
+			if t, err := parseMode(strings.TrimPrefix(text, "\tmode: ")); err == nil {
+				modes[name] = &t
+			}
+		case section == "connector" && ok && key == "crtc":
+			connectors[name] = value
+		case section == "connector" && ok && key == "max_requested_bpc":
+			bpc[name], _ = strconv.Atoi(value)
+		}
+	}
+	out := map[string]connectorState{}
+	for connector, crtc := range connectors {
+		if mode, ok := modes[crtc]; ok {
+			out[connector] = connectorState{mode: mode, maxBPC: bpc[connector]}
+		}
+	}
+	return out
+}
+
+// readLinkSettings reads the current link of a connector from the
+// link_settings file amdgpu has in debugfs, which reads e.g.
+// "Current:  4  0x14  0  Verified: ...": the lane count, the dc_link_rate
+// in hex and the spread.
+func readLinkSettings(root, card, name string) *Link {
+	data, err := os.ReadFile(filepath.Join(root, "sys", "kernel", "debug", "dri", strings.TrimPrefix(card, "card"), name, "link_settings"))
+	if err != nil {
+		return nil
+	}
+	_, current, ok := strings.Cut(string(data), "Current:")
+	fields := strings.Fields(current)
+	if !ok || len(fields) < 2 {
+		return nil
+	}
+	lanes, err := strconv.Atoi(fields[0])
+	if err != nil || lanes == 0 {
+		return nil
+	}
+	rate, err := strconv.ParseUint(fields[1], 0, 32)
+	if err != nil || rate == 0 {
+		return nil
+	}
+	l := &Link{Rate: strconv.FormatUint(rate, 10), Lanes: lanes}
+	if name, ok := amdgpuRates[l.Rate]; ok {
+		l.Rate = name
+	}
+	return l
+}
+
+// parseMode parses a mode as printed by DRM_MODE_FMT: the quoted name, the
+// refresh rate, the pixel clock in kHz, the horizontal and vertical
+// timings, the type and the flags. Older kernels print the mode id and a
+// colon before the name rather than a colon after it.
+func parseMode(s string) (video.ExactTiming, error) {
+	i := strings.LastIndexByte(s, '"')
+	if i < 0 {
+		return video.ExactTiming{}, fmt.Errorf("invalid mode %q", s)
+	}
+	fields := strings.Fields(strings.TrimPrefix(s[i+1:], ":"))
+	if len(fields) != 12 {
+		return video.ExactTiming{}, fmt.Errorf("invalid mode %q", s)
+	}
+	var v [9]int
+	for i := range v {
+		n, err := strconv.Atoi(fields[1+i])
+		if err != nil {
+			return video.ExactTiming{}, fmt.Errorf("invalid mode %q", s)
+		}
+		v[i] = n
+	}
+	flags, err := strconv.ParseUint(strings.TrimPrefix(fields[11], "0x"), 16, 32)
+	if err != nil {
+		return video.ExactTiming{}, fmt.Errorf("invalid mode %q", s)
+	}
+	return video.ExactTiming{
+		Name:          "DRM",
+		Width:         v[1],
+		Height:        v[5],
+		HFrontPorch:   v[2] - v[1],
+		HSync:         v[3] - v[2],
+		HBackPorch:    v[4] - v[3],
+		VFrontPorch:   v[6] - v[5],
+		VSync:         v[7] - v[6],
+		VBackPorch:    v[8] - v[7],
+		HSyncPositive: flags&0x01 != 0,
+		VSyncPositive: flags&0x04 != 0,
+		Interlaced:    flags&0x10 != 0,
+		PixelClock:    v[0] * 1000,
+	}, nil
+}
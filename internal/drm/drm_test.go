@@ -0,0 +1,134 @@
+package drm
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func TestConnectors(t *testing.T) {
+	connectors, err := Connectors("testdata/root")
+	if err != nil {
+		t.Fatalf("Connectors: %v", err)
+	}
+	var names []string
+	for _, c := range connectors {
+		names = append(names, c.Card+"-"+c.Name)
+	}
+	if want := []string{"card0-DP-1", "card0-HDMI-A-1", "card1-eDP-1"}; !slices.Equal(names, want) {
+		t.Fatalf("connectors = %q, want %q", names, want)
+	}
+
+	dp := connectors[0]
+	if !dp.Connected() || !dp.Enabled || dp.Family() != "DisplayPort" || !slices.Equal(dp.Modes, []string{"3840x2160", "3840x4320"}) {
+		t.Errorf("DP-1 = %+v", dp)
+	}
+	if dp.EDID == nil || dp.EDIDError != nil || dp.EDID.Name != "GVBC TILE" {
+		t.Errorf("DP-1 EDID = %+v, %v", dp.EDID, dp.EDIDError)
+	}
+	if !dp.StateRead || dp.CurrentModeGuessed || dp.MaxBPC != 10 || dp.Mode == nil || dp.Mode.PixelClock != 594_000_000 {
+		t.Errorf("DP-1 state = %v, guessed %v, %d bpc, mode %+v", dp.StateRead, dp.CurrentModeGuessed, dp.MaxBPC, dp.Mode)
+	}
+	if dp.Link == nil || *dp.Link != (Link{Rate: "HBR3", Lanes: 4}) {
+		t.Errorf("DP-1 link = %+v, want HBR3 x4", dp.Link)
+	}
+
+	hdmi := connectors[1]
+	if hdmi.Connected() || hdmi.Enabled || hdmi.Type() != "HDMI-A" || hdmi.EDID != nil || hdmi.Mode != nil || len(hdmi.Modes) != 0 {
+		t.Errorf("HDMI-A-1 = %+v", hdmi)
+	}
+
+	// card1 has no atomic state: the mode is the preferred one of the EDID.
+	edp := connectors[2]
+	if edp.StateRead || !edp.CurrentModeGuessed || edp.Mode == nil || edp.Mode.Width != 1920 || edp.Mode.Height != 1080 {
+		t.Errorf("eDP-1 state = %v, guessed %v, mode %+v", edp.StateRead, edp.CurrentModeGuessed, edp.Mode)
+	}
+	if edp.Link != nil {
+		t.Errorf("eDP-1 link = %+v, want nil without debugfs", edp.Link)
+	}
+
+	if _, err := Connectors(t.TempDir()); err == nil {
+		t.Error("Connectors of a root without sysfs succeeded")
+	}
+}
+
+func TestReadState(t *testing.T) {
+	state := readState("testdata/root", "card0")
+	if len(state) != 1 {
+		t.Fatalf("state = %+v, want DP-1 only", state)
+	}
+	s, ok := state["DP-1"]
+	if !ok || s.maxBPC != 10 || s.mode == nil || s.mode.Width != 3840 || s.mode.VTotal() != 2250 {
+		t.Errorf("DP-1 state = %+v", s)
+	}
+	if state := readState("testdata/root", "card1"); state != nil {
+		t.Errorf("state of card1 = %+v, want nil without debugfs", state)
+	}
+}
+
+func TestReadLinkSettings(t *testing.T) {
+	tests := []struct {
+		settings string
+		want     *Link
+	}{
+		{"Current:  4  0x14  0  Verified:  4  0x1e  0  Reported:  4  0x1e  16  Preferred:  0  0x0  0  \n", &Link{Rate: "HBR2", Lanes: 4}},
+		{"Current:  2  0x3e8  0  Verified:  4  0x3e8  0\n", &Link{Rate: "UHBR10", Lanes: 2}},
+		{"Current:  4  0x51  0\n", &Link{Rate: "81", Lanes: 4}},
+		{"Current:  0  0x0  0  Verified:  4  0x14  0\n", nil},
+		{"Verified:  4  0x14  0\n", nil},
+	}
+	for _, tt := range tests {
+		root := t.TempDir()
+		dir := filepath.Join(root, "sys", "kernel", "debug", "dri", "0", "DP-2")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "link_settings"), []byte(tt.settings), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got := readLinkSettings(root, "card0", "DP-2")
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("readLinkSettings(%q) = %+v, want %+v", tt.settings, got, tt.want)
+		}
+	}
+	if l := readLinkSettings("testdata/root", "card1", "eDP-1"); l != nil {
+		t.Errorf("link of eDP-1 = %+v, want nil without debugfs", l)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	uhd := video.ExactTiming{Name: "DRM", Width: 3840, Height: 2160, HFrontPorch: 176, HSync: 88, HBackPorch: 296,
+		VFrontPorch: 8, VSync: 10, VBackPorch: 72, HSyncPositive: true, VSyncPositive: true, PixelClock: 594_000_000}
+	interlaced := video.ExactTiming{Name: "DRM", Width: 1920, Height: 1080, HFrontPorch: 88, HSync: 44, HBackPorch: 148,
+		VFrontPorch: 4, VSync: 10, VBackPorch: 31, HSyncPositive: true, VSyncPositive: true, Interlaced: true, PixelClock: 74_250_000}
+	tests := []struct {
+		name string
+		mode string
+		want video.ExactTiming
+		err  bool
+	}{
+		{name: "current", mode: `"3840x2160": 60 594000 3840 4016 4104 4400 2160 2168 2178 2250 0x48 0x5`, want: uhd},
+		{name: "old kernel", mode: `0:"3840x2160" 60 594000 3840 4016 4104 4400 2160 2168 2178 2250 0x48 0x5`, want: uhd},
+		{name: "interlaced", mode: `"1920x1080i": 60 74250 1920 2008 2052 2200 1080 1084 1094 1125 0x40 0x15`, want: interlaced},
+		{name: "unquoted", mode: `3840x2160 60 594000`, err: true},
+		{name: "short", mode: `"3840x2160": 60 594000 3840 4016 4104 4400`, err: true},
+		{name: "flags", mode: `"3840x2160": 60 594000 3840 4016 4104 4400 2160 2168 2178 2250 0x48 0xz`, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMode(tt.mode)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("parseMode = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("parseMode = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
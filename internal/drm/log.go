@@ -0,0 +1,221 @@
+package drm
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+// LogTraining is one link training attempt found in a kernel log.
+type LogTraining struct {
+	// Rate is the link mode name, e.g. HBR2, or the link rate in kHz when
+	// it is not one gvbc models, e.g. 216000 on eDP.
+	Rate   string
+	Lanes  int
+	Passed bool
+}
+
+func (t LogTraining) LinkName() string {
+	return t.Rate + " x" + strconv.Itoa(t.Lanes)
+}
+
+// LogLink is what a kernel log tells about the link of a connector.
+type LogLink struct {
+	Connector string
+	// Driver is i915 or amdgpu, empty when no line names it.
+	Driver string
+	// Trainings are the link training attempts, in log order.
+	Trainings []LogTraining
+	// Mode is the last mode set on the connector, nil when the log has none.
+	Mode *video.ExactTiming
+	// BPP is the pipe bits per pixel, and MaxBPP the most the driver
+	// allowed for the mode; both are zero when unknown.
+	BPP    int
+	MaxBPP int
+	// Encoding is the output format of the pipe, RGB when unknown.
+	Encoding video.Encoding
+	// DSCBitsPerPixel is the compressed bits per pixel, zero without DSC.
+	DSCBitsPerPixel float64
+}
+
+// Family is the interface family of the connector type, empty for the
+// types gvbc does not model.
+func (l LogLink) Family() string {
+	return connectorFamily(l.Connector)
+}
+
+// Link is the last training that passed; ok is false when none did.
+func (l LogLink) Link() (LogTraining, bool) {
+	for i := len(l.Trainings) - 1; i >= 0; i-- {
+		if l.Trainings[i].Passed {
+			return l.Trainings[i], true
+		}
+	}
+	return LogTraining{}, false
+}
+
+var (
+	connectorTag = regexp.MustCompile(`\[CONNECTOR:\d+:([^\]]+)\]`)
+	crtcTag      = regexp.MustCompile(`\[CRTC:(\d+):[^\]]*\]`)
+	// i915: "Link Training passed at link rate = 540000, lane count = 4".
+	i915Training = regexp.MustCompile(`Link Training (passed|failed) at link rate = (\d+), lane count = (\d+)`)
+	// i915: "DP lane count 4 clock 540000 bpp input 30 compressed 0".
+	i915LinkConfig = regexp.MustCompile(`DP lane count (\d+) clock (\d+) bpp (?:input )?(\d+)`)
+	i915MaxBPP     = regexp.MustCompile(`DP link computation with .*max bpp (\d+)`)
+	i915PipeBPP    = regexp.MustCompile(`pipe bpp: (\d+)`)
+	i915Format     = regexp.MustCompile(`output format: (\w+)`)
+	// i915: "DP DSC computed with Input Bpp = 30 Compressed Bpp = 12".
+	i915DSCInput = regexp.MustCompile(`Input Bpp = (\d+)`)
+	i915DSC      = regexp.MustCompile(`Compressed Bpp = ([\d.]+)`)
+	// amdgpu: "training attempt 1 of 4 failed @ rate(20) x lane(4)".
+	amdgpuFailure = regexp.MustCompile(`training attempt \d+ of \d+ failed @ rate\((\d+)\) x lane\((\d+)\)`)
+	// amdgpu: "Link Training: HBR2x4 pass VS=0, PE=0".
+	amdgpuResult = regexp.MustCompile(`\b(RBR|HBR|HBR2|HBR3|UHBR10|UHBR13\.5|UHBR20)x(\d) (pass|fail)`)
+)
+
+// i915Rates are the link rates in kHz i915 prints, by link mode name.
+var i915Rates = map[string]string{
+	"162000":  "RBR",
+	"270000":  "HBR",
+	"540000":  "HBR2",
+	"810000":  "HBR3",
+	"1000000": "UHBR10",
+	"1350000": "UHBR13.5",
+	"2000000": "UHBR20",
+}
+
+// i915Formats are the output formats i915 prints.
+var i915Formats = map[string]video.Encoding{
+	"RGB":      video.EncodingRGB,
+	"YCBCR444": video.EncodingYCbCr444,
+	"YCBCR420": video.EncodingYCbCr420,
+}
+
+// amdgpuRates are the dc_link_rate values amdgpu prints, by link mode name.
+var amdgpuRates = map[string]string{
+	"6":    "RBR",
+	"10":   "HBR",
+	"20":   "HBR2",
+	"30":   "HBR3",
+	"1000": "UHBR10",
+	"1350": "UHBR13.5",
+	"2000": "UHBR20",
+}
+
+// ParseLog reads the link training and mode set messages i915 and amdgpu
+// print with drm.debug set, e.g. in dmesg or journalctl output, and
+// returns the links in order of appearance. Messages without a connector
+// tag belong to the connector of the last tagged line, or of the last
+// CRTC it was routed to.
+func ParseLog(r io.Reader) ([]LogLink, error) {
+	var links []*LogLink
+	byName := map[string]*LogLink{}
+	crtcs := map[string]string{}
+	var current *LogLink
+	probing := false
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for s.Scan() {
+		text := s.Text()
+		if m := connectorTag.FindStringSubmatch(text); m != nil {
+			if _, ok := byName[m[1]]; !ok {
+				byName[m[1]] = &LogLink{Connector: m[1]}
+				links = append(links, byName[m[1]])
+			}
+			current = byName[m[1]]
+			if c := crtcTag.FindStringSubmatch(text); c != nil {
+				crtcs[c[1]] = m[1]
+			}
+		} else if c := crtcTag.FindStringSubmatch(text); c != nil {
+			if name, ok := crtcs[c[1]]; ok {
+				current = byName[name]
+			}
+		}
+		// drm lists the probed modes of a connector after this line, one
+		// Modeline each; they are not mode sets.
+		if strings.Contains(strings.ToLower(text), "probed modes") {
+			probing = true
+			continue
+		}
+		if probing && !strings.Contains(text, "Modeline") {
+			probing = false
+		}
+		if current == nil {
+			continue
+		}
+		switch {
+		case strings.Contains(text, "[i915]") || strings.Contains(text, "i915 "):
+			current.Driver = "i915"
+		case strings.Contains(text, "[amdgpu]") || strings.Contains(text, "amdgpu "):
+			current.Driver = "amdgpu"
+		}
+
+		if m := i915Training.FindStringSubmatch(text); m != nil {
+			current.addTraining(newLogTraining(i915Rates, m[2], m[3], m[1] == "passed"))
+		} else if m := amdgpuFailure.FindStringSubmatch(text); m != nil {
+			current.addTraining(newLogTraining(amdgpuRates, m[1], m[2], false))
+		} else if m := amdgpuResult.FindStringSubmatch(text); m != nil {
+			lanes, _ := strconv.Atoi(m[2])
+			current.addTraining(LogTraining{Rate: m[1], Lanes: lanes, Passed: m[3] == "pass"})
+		}
+		if m := i915LinkConfig.FindStringSubmatch(text); m != nil {
+			current.BPP, _ = strconv.Atoi(m[3])
+		}
+		if m := i915MaxBPP.FindStringSubmatch(text); m != nil {
+			// A new link computation, which enables DSC again if needed.
+			current.MaxBPP, _ = strconv.Atoi(m[1])
+			current.DSCBitsPerPixel = 0
+		}
+		if m := i915PipeBPP.FindStringSubmatch(text); m != nil {
+			current.BPP, _ = strconv.Atoi(m[1])
+		}
+		if m := i915Format.FindStringSubmatch(text); m != nil {
+			current.Encoding = i915Formats[m[1]]
+		}
+		if m := i915DSCInput.FindStringSubmatch(text); m != nil {
+			current.BPP, _ = strconv.Atoi(m[1])
+		}
+		if m := i915DSC.FindStringSubmatch(text); m != nil {
+			current.DSCBitsPerPixel, _ = strconv.ParseFloat(m[1], 64)
+		}
+		// i915 dumps the requested then the adjusted mode of a mode set,
+		// and drm prints a Modeline for modes it debugs outside probing.
+		for _, prefix := range []string{"requested mode: ", "adjusted mode: ", "Modeline "} {
+			if _, mode, ok := strings.Cut(text, prefix); ok && !probing {
+				if t, err := parseMode(mode); err == nil {
+					current.Mode = &t
+				}
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	out := make([]LogLink, len(links))
+	for i, l := range links {
+		out[i] = *l
+	}
+	return out, nil
+}
+
+// addTraining appends t unless it repeats the last failure, which amdgpu
+// reports twice: in the training result and in the retry error.
+func (l *LogLink) addTraining(t LogTraining) {
+	if n := len(l.Trainings); n > 0 && !t.Passed && l.Trainings[n-1] == t {
+		return
+	}
+	l.Trainings = append(l.Trainings, t)
+}
+
+func newLogTraining(rates map[string]string, rate, lanes string, passed bool) LogTraining {
+	t := LogTraining{Rate: rate, Passed: passed}
+	if name, ok := rates[rate]; ok {
+		t.Rate = name
+	}
+	t.Lanes, _ = strconv.Atoi(lanes)
+	return t
+}
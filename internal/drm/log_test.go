@@ -0,0 +1,93 @@
+package drm
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func parseLogFile(t *testing.T, path string) []LogLink {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	links, err := ParseLog(f)
+	if err != nil {
+		t.Fatalf("ParseLog: %v", err)
+	}
+	return links
+}
+
+func TestParseLogI915(t *testing.T) {
+	links := parseLogFile(t, "testdata/i915.log")
+	if len(links) != 2 {
+		t.Fatalf("%d links, want DP-1 and eDP-1", len(links))
+	}
+
+	dp := links[0]
+	if dp.Connector != "DP-1" || dp.Driver != "i915" || dp.Family() != "DisplayPort" {
+		t.Errorf("DP-1 = %+v", dp)
+	}
+	want := []LogTraining{{Rate: "HBR3", Lanes: 4}, {Rate: "HBR2", Lanes: 4, Passed: true}}
+	if !slices.Equal(dp.Trainings, want) {
+		t.Errorf("DP-1 trainings = %+v, want %+v", dp.Trainings, want)
+	}
+	if link, ok := dp.Link(); !ok || link.LinkName() != "HBR2 x4" {
+		t.Errorf("DP-1 link = %v, %v", link, ok)
+	}
+	// The probed 1080p mode is not a mode set.
+	if dp.Mode == nil || dp.Mode.Width != 3840 || dp.Mode.PixelClock != 594_000_000 {
+		t.Errorf("DP-1 mode = %+v", dp.Mode)
+	}
+	if dp.BPP != 30 || dp.MaxBPP != 30 || dp.Encoding != video.EncodingRGB || dp.DSCBitsPerPixel != 0 {
+		t.Errorf("DP-1 pipe = %d bpp of %d, %v, DSC %g", dp.BPP, dp.MaxBPP, dp.Encoding, dp.DSCBitsPerPixel)
+	}
+
+	edp := links[1]
+	// DSC takes 10 bpc in, at 30 bpp, and sends 12 bpp.
+	if edp.BPP != 30 || edp.DSCBitsPerPixel != 12 {
+		t.Errorf("eDP-1 DSC = %d bpp in, %g bpp out, want 30 and 12", edp.BPP, edp.DSCBitsPerPixel)
+	}
+	// eDP rates outside the DisplayPort modes keep their kHz value.
+	if link, ok := edp.Link(); !ok || link.Rate != "216000" {
+		t.Errorf("eDP-1 link = %+v, %v", link, ok)
+	}
+	// The mode comes from a Modeline in the format of older kernels.
+	if m := edp.Mode; m == nil || m.Width != 2560 || m.Height != 1600 || !m.HSyncPositive || m.VSyncPositive {
+		t.Errorf("eDP-1 mode = %+v", m)
+	}
+}
+
+func TestParseLogAMDGPU(t *testing.T) {
+	links := parseLogFile(t, "testdata/amdgpu.log")
+	if len(links) != 2 {
+		t.Fatalf("%d links, want DP-2 and HDMI-A-1", len(links))
+	}
+	dp := links[0]
+	if dp.Connector != "DP-2" || dp.Driver != "amdgpu" {
+		t.Errorf("DP-2 = %+v", dp)
+	}
+	// amdgpu reports the HBR3 failure twice.
+	want := []LogTraining{{Rate: "HBR3", Lanes: 4}, {Rate: "HBR2", Lanes: 4, Passed: true}}
+	if !slices.Equal(dp.Trainings, want) {
+		t.Errorf("DP-2 trainings = %+v, want %+v", dp.Trainings, want)
+	}
+	if m := dp.Mode; m == nil || m.Width != 2560 || m.PixelClock != 586_590_000 {
+		t.Errorf("DP-2 mode = %+v", m)
+	}
+	if hdmi := links[1]; hdmi.Mode != nil || len(hdmi.Trainings) != 0 {
+		t.Errorf("HDMI-A-1 = %+v, want nothing set", hdmi)
+	}
+}
+
+func TestParseLogWithoutLinks(t *testing.T) {
+	links, err := ParseLog(strings.NewReader("[    0.000000] Linux version 6.8.0\n"))
+	if err != nil || len(links) != 0 {
+		t.Errorf("ParseLog = %+v, %v, want no links", links, err)
+	}
+}
@@ -0,0 +1,315 @@
+package edid
+
+import (
+	"fmt"
+	"math"
+)
+
+// CTA is what the CTA-861 extension blocks declare beyond timings.
+type CTA struct {
+	Revision   int
+	Underscan  bool
+	BasicAudio bool
+	YCbCr444   bool
+	YCbCr422   bool
+	HDMI       *HDMI
+	HDR        *HDRStaticMetadata
+}
+
+// HDMI gathers the HDMI vendor-specific data blocks: the HDMI 1.x one of
+// HDMI Licensing and the HDMI Forum one (HF-VSDB) of HDMI 2.x sinks.
+type HDMI struct {
+	PhysicalAddress string `json:"physical_address,omitempty" yaml:"physical_address,omitempty"`
+	// DeepColor lists the bits per component above 8 supported in RGB, and
+	// in YCbCr 4:4:4 when DeepColor444 is set.
+	DeepColor    []int `json:"deep_color,omitempty" yaml:"deep_color,omitempty"`
+	DeepColor444 bool  `json:"deep_color_444,omitempty" yaml:"deep_color_444,omitempty"`
+	// MaxTMDSClock is in Hz, zero when undefined.
+	MaxTMDSClock int `json:"max_tmds_clock_hz,omitempty" yaml:"max_tmds_clock_hz,omitempty"`
+
+	// Forum is set when the sink has an HDMI Forum VSDB, which sets the
+	// fields below.
+	Forum bool `json:"forum,omitempty" yaml:"forum,omitempty"`
+	// MaxTMDSCharacterRate is in Hz, zero when at most 340 MHz.
+	MaxTMDSCharacterRate int  `json:"max_tmds_character_rate_hz,omitempty" yaml:"max_tmds_character_rate_hz,omitempty"`
+	SCDC                 bool `json:"scdc,omitempty" yaml:"scdc,omitempty"`
+	// MaxFRLRate is the fastest FRL rate index, 1 to 6, zero without FRL.
+	MaxFRLRate int `json:"max_frl_rate,omitempty" yaml:"max_frl_rate,omitempty"`
+	// DeepColor420 lists the bits per component above 8 supported in
+	// YCbCr 4:2:0.
+	DeepColor420 []int `json:"deep_color_420,omitempty" yaml:"deep_color_420,omitempty"`
+	ALLM         bool  `json:"allm,omitempty" yaml:"allm,omitempty"`
+	// VRRMin and VRRMax are in Hz, zero without VRR.
+	VRRMin int  `json:"vrr_min_hz,omitempty" yaml:"vrr_min_hz,omitempty"`
+	VRRMax int  `json:"vrr_max_hz,omitempty" yaml:"vrr_max_hz,omitempty"`
+	DSC    *DSC `json:"dsc,omitempty" yaml:"dsc,omitempty"`
+}
+
+// FRL is the name of the fastest FRL link mode, as in the spec data.
+func (h HDMI) FRL() string {
+	if h.MaxFRLRate == 0 {
+		return ""
+	}
+	return fmt.Sprintf("FRL %d", h.MaxFRLRate)
+}
+
+// DSC is the DSC decoder of an HDMI 2.1 sink.
+type DSC struct {
+	Version12 bool `json:"version_1_2,omitempty" yaml:"version_1_2,omitempty"`
+	Native420 bool `json:"native_420,omitempty" yaml:"native_420,omitempty"`
+	AllBPP    bool `json:"all_bpp,omitempty" yaml:"all_bpp,omitempty"`
+	// BPC lists the supported bits per component, from 8.
+	BPC []int `json:"bpc,omitempty" yaml:"bpc,omitempty"`
+	// MaxFRLRate is the fastest FRL rate index with DSC.
+	MaxFRLRate int `json:"max_frl_rate,omitempty" yaml:"max_frl_rate,omitempty"`
+	MaxSlices  int `json:"max_slices,omitempty" yaml:"max_slices,omitempty"`
+	// TotalChunkKBytes is the size of the decoder buffer.
+	TotalChunkKBytes int `json:"total_chunk_kbytes,omitempty" yaml:"total_chunk_kbytes,omitempty"`
+}
+
+// HDRStaticMetadata is the HDR static metadata data block. Luminances are
+// in cd/m², zero when undefined.
+type HDRStaticMetadata struct {
+	EOTFs           []string `json:"eotfs,omitempty" yaml:"eotfs,omitempty"`
+	MaxLuminance    float64  `json:"max_luminance,omitempty" yaml:"max_luminance,omitempty"`
+	MaxFrameAverage float64  `json:"max_frame_average,omitempty" yaml:"max_frame_average,omitempty"`
+	MinLuminance    float64  `json:"min_luminance,omitempty" yaml:"min_luminance,omitempty"`
+}
+
+const (
+	ouiHDMI      = 0x000c03
+	ouiHDMIForum = 0xc45dd8
+)
+
+func (e *EDID) parseCTA(b []byte) {
+	if e.CTA == nil {
+		e.CTA = &CTA{}
+	}
+	c := e.CTA
+	c.Revision = int(b[1])
+	dtdOffset := int(b[2])
+	if c.Revision >= 2 {
+		c.Underscan = b[3]&0x80 != 0
+		c.BasicAudio = b[3]&0x40 != 0
+		c.YCbCr444 = c.YCbCr444 || b[3]&0x20 != 0
+		c.YCbCr422 = c.YCbCr422 || b[3]&0x10 != 0
+	}
+	if dtdOffset == 0 {
+		return
+	}
+	if dtdOffset < 4 || dtdOffset > blockSize-1 {
+		e.warn("CTA-861 block with invalid DTD offset %d", dtdOffset)
+		return
+	}
+
+	var svds []Timing
+	var only420, map420 []byte
+	hasMap420 := false
+	for i := 4; i < dtdOffset; {
+		tag, n := int(b[i]>>5), int(b[i]&0x1f)
+		if i+1+n > dtdOffset {
+			e.warn("CTA-861 data block at offset %d overruns the block", i)
+			break
+		}
+		data := b[i+1 : i+1+n]
+		i += 1 + n
+		switch tag {
+		case 2:
+			for _, svd := range data {
+				svds = append(svds, svdTiming(svd, false))
+			}
+		case 3:
+			if len(data) < 3 {
+				continue
+			}
+			switch int(data[0]) | int(data[1])<<8 | int(data[2])<<16 {
+			case ouiHDMI:
+				c.hdmi().parseVSDB(data)
+			case ouiHDMIForum:
+				c.hdmi().parseForumVSDB(data)
+			}
+		case 7:
+			if len(data) == 0 {
+				continue
+			}
+			switch data[0] {
+			case 6:
+				c.HDR = parseHDRStaticMetadata(data[1:])
+			case 14:
+				only420 = append(only420, data[1:]...)
+			case 15:
+				hasMap420 = true
+				map420 = append(map420, data[1:]...)
+			}
+		}
+	}
+	// The capability map flags SVDs by index; an empty map flags them all.
+	for i := range svds {
+		if hasMap420 && (len(map420) == 0 || i/8 < len(map420) && map420[i/8]>>(i%8)&1 != 0) {
+			svds[i].YCbCr420 = true
+		}
+	}
+	for _, svd := range only420 {
+		t := svdTiming(svd, true)
+		svds = append(svds, t)
+	}
+
+	for i := dtdOffset; i+18 <= blockSize-1; i += 18 {
+		d := b[i : i+18]
+		if d[0] == 0 && d[1] == 0 {
+			break
+		}
+		t := parseDetailedTiming(d)
+		t.Source = fmt.Sprintf("DTD %d", len(e.Timings)+1)
+		e.Timings = append(e.Timings, t)
+	}
+	// The native formats are the first detailed timings, from the base
+	// block on.
+	if natives := int(b[3] & 0x0f); c.Revision >= 2 {
+		for i := range e.Timings {
+			if e.Timings[i].VIC == 0 && i < natives {
+				e.Timings[i].Native = true
+			}
+		}
+	}
+	for _, t := range svds {
+		if t.VIC == 0 {
+			continue
+		}
+		if _, ok := vics[t.VIC]; !ok {
+			e.warn("unknown VIC %d ignored", t.VIC)
+			continue
+		}
+		e.Timings = append(e.Timings, t)
+	}
+}
+
+func (c *CTA) hdmi() *HDMI {
+	if c.HDMI == nil {
+		c.HDMI = &HDMI{}
+	}
+	return c.HDMI
+}
+
+// svdTiming resolves a short video descriptor; VIC is zero when the
+// descriptor is reserved.
+func svdTiming(svd byte, only420 bool) Timing {
+	var vic int
+	var native bool
+	// Codes 129 to 192 are native VICs 1 to 64; the codes above are VICs
+	// 193 and up.
+	switch {
+	case svd == 0 || svd == 128 || svd >= 254:
+	case svd < 128:
+		vic = int(svd)
+	case svd <= 192:
+		vic, native = int(svd&0x7f), true
+	default:
+		vic = int(svd)
+	}
+	t := Timing{
+		Source:       fmt.Sprintf("VIC %d", vic),
+		VIC:          vic,
+		Native:       native,
+		YCbCr420:     only420,
+		OnlyYCbCr420: only420,
+	}
+	if timing, ok := vics[vic]; ok {
+		t.Timing = timing
+		t.Interlaced = timing.Interlaced
+	}
+	return t
+}
+
+func (h *HDMI) parseVSDB(data []byte) {
+	if len(data) >= 5 {
+		h.PhysicalAddress = fmt.Sprintf("%d.%d.%d.%d", data[3]>>4, data[3]&0x0f, data[4]>>4, data[4]&0x0f)
+	}
+	if len(data) >= 6 {
+		flags := data[5]
+		for _, dc := range []struct {
+			bit byte
+			bpc int
+		}{{0x10, 10}, {0x20, 12}, {0x40, 16}} {
+			if flags&dc.bit != 0 {
+				h.DeepColor = append(h.DeepColor, dc.bpc)
+			}
+		}
+		h.DeepColor444 = flags&0x08 != 0
+	}
+	if len(data) >= 7 {
+		h.MaxTMDSClock = int(data[6]) * 5e6
+	}
+}
+
+func (h *HDMI) parseForumVSDB(data []byte) {
+	h.Forum = true
+	if len(data) < 7 {
+		return
+	}
+	h.MaxTMDSCharacterRate = int(data[4]) * 5e6
+	h.SCDC = data[5]&0x80 != 0
+	for _, dc := range []struct {
+		bit byte
+		bpc int
+	}{{0x01, 10}, {0x02, 12}, {0x04, 16}} {
+		if data[6]&dc.bit != 0 {
+			h.DeepColor420 = append(h.DeepColor420, dc.bpc)
+		}
+	}
+	h.MaxFRLRate = int(data[6] >> 4)
+	if len(data) >= 8 {
+		h.ALLM = data[7]&0x02 != 0
+	}
+	if len(data) >= 10 {
+		h.VRRMin = int(data[8] & 0x3f)
+		h.VRRMax = int(data[8]>>6)<<8 | int(data[9])
+	}
+	if len(data) >= 11 && data[10]&0x80 != 0 {
+		d := &DSC{
+			Version12: true,
+			Native420: data[10]&0x40 != 0,
+			AllBPP:    data[10]&0x08 != 0,
+			BPC:       []int{8},
+		}
+		for _, dc := range []struct {
+			bit byte
+			bpc int
+		}{{0x01, 10}, {0x02, 12}, {0x04, 16}} {
+			if data[10]&dc.bit != 0 {
+				d.BPC = append(d.BPC, dc.bpc)
+			}
+		}
+		if len(data) >= 12 {
+			d.MaxFRLRate = int(data[11] >> 4)
+			d.MaxSlices = [...]int{0, 1, 2, 4, 8, 8, 12, 16, 0, 0, 0, 0, 0, 0, 0, 0}[data[11]&0x0f]
+		}
+		if len(data) >= 13 {
+			d.TotalChunkKBytes = int(data[12]&0x3f) + 1
+		}
+		h.DSC = d
+	}
+}
+
+func parseHDRStaticMetadata(data []byte) *HDRStaticMetadata {
+	h := &HDRStaticMetadata{}
+	if len(data) >= 1 {
+		for i, name := range []string{"SDR", "HDR", "PQ", "HLG"} {
+			if data[0]>>i&1 != 0 {
+				h.EOTFs = append(h.EOTFs, name)
+			}
+		}
+	}
+	// Luminances are coded as 50·2^(cv/32) cd/m², the minimum relative to
+	// the maximum.
+	if len(data) >= 3 && data[2] != 0 {
+		h.MaxLuminance = 50 * math.Pow(2, float64(data[2])/32)
+	}
+	if len(data) >= 4 && data[3] != 0 {
+		h.MaxFrameAverage = 50 * math.Pow(2, float64(data[3])/32)
+	}
+	if len(data) >= 5 && h.MaxLuminance > 0 {
+		h.MinLuminance = h.MaxLuminance * math.Pow(float64(data[4])/255, 2) / 100
+	}
+	return h
+}
@@ -0,0 +1,193 @@
+package edid
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+// DisplayID is what the DisplayID 1.3 and 2.0 extension blocks declare
+// beyond timings, which join the timings of the EDID.
+type DisplayID struct {
+	// Version is e.g. "1.3" or "2.0".
+	Version string       `json:"version" yaml:"version"`
+	Tile    *Tile        `json:"tile,omitempty" yaml:"tile,omitempty"`
+	Range   *RangeLimits `json:"range_limits,omitempty" yaml:"range_limits,omitempty"`
+	// VRRMin and VRRMax are the widest adaptive-sync range, in Hz, zero
+	// without adaptive sync.
+	VRRMin int `json:"vrr_min_hz,omitempty" yaml:"vrr_min_hz,omitempty"`
+	VRRMax int `json:"vrr_max_hz,omitempty" yaml:"vrr_max_hz,omitempty"`
+	// DSC is set when the Type VII timings may be sent DSC compressed.
+	DSC bool `json:"dsc,omitempty" yaml:"dsc,omitempty"`
+}
+
+// Tile is the place of this EDID in a display made of several tiles, each
+// driven by its own stream.
+type Tile struct {
+	HTiles    int `json:"h_tiles" yaml:"h_tiles"`
+	VTiles    int `json:"v_tiles" yaml:"v_tiles"`
+	HLocation int `json:"h_location" yaml:"h_location"`
+	VLocation int `json:"v_location" yaml:"v_location"`
+	// Width and Height are the size of one tile, in pixels.
+	Width           int  `json:"width" yaml:"width"`
+	Height          int  `json:"height" yaml:"height"`
+	SingleEnclosure bool `json:"single_enclosure" yaml:"single_enclosure"`
+}
+
+// Size is the resolution of the whole tiled display.
+func (t Tile) Size() (width, height int) {
+	return t.HTiles * t.Width, t.VTiles * t.Height
+}
+
+// DisplayID data block tags.
+const (
+	displayIDType1Timing  = 0x03
+	displayIDRangeLimits  = 0x09
+	displayIDTiled        = 0x12
+	displayIDType7Timing  = 0x22
+	displayIDDynamicRange = 0x25
+	displayIDTiled2       = 0x28
+	displayIDAdaptiveSync = 0x2b
+)
+
+// parseDisplayID parses the DisplayID section of an extension block, which
+// follows the extension tag.
+func (e *EDID) parseDisplayID(section []byte) {
+	n := int(section[1])
+	if 5+n > len(section) {
+		e.warn("DisplayID section of %d bytes overruns the block", n)
+		return
+	}
+	if !checksum(section[:5+n]) {
+		e.warn("bad DisplayID section checksum")
+	}
+	if e.DisplayID == nil {
+		e.DisplayID = &DisplayID{Version: fmt.Sprintf("%d.%d", section[0]>>4, section[0]&0x0f)}
+	}
+	d := e.DisplayID
+	for i := 4; i+3 <= 4+n; {
+		tag, revision, size := section[i], section[i+1], int(section[i+2])
+		if tag == 0 && size == 0 {
+			// Padding.
+			break
+		}
+		if i+3+size > 4+n {
+			e.warn("DisplayID data block 0x%02x overruns the section", tag)
+			break
+		}
+		data := section[i+3 : i+3+size]
+		i += 3 + size
+		switch tag {
+		case displayIDType1Timing, displayIDType7Timing:
+			// Type I counts pixel clocks in 10 kHz, Type VII in 1 kHz.
+			unit := 10_000
+			if tag == displayIDType7Timing {
+				unit = 1000
+				d.DSC = d.DSC || revision&0x08 != 0
+			}
+			for j := 0; j+20 <= len(data); j += 20 {
+				t := parseDisplayIDTiming(data[j:j+20], unit)
+				t.Source = fmt.Sprintf("DisplayID %d", len(e.Timings)+1)
+				e.Timings = append(e.Timings, t)
+			}
+		case displayIDTiled, displayIDTiled2:
+			if len(data) >= 8 {
+				d.Tile = parseTile(data)
+			}
+		case displayIDRangeLimits:
+			if len(data) >= 15 {
+				d.Range = &RangeLimits{
+					MinHRate:      int(data[6]),
+					MaxHRate:      int(data[7]),
+					MinVRate:      int(data[10]),
+					MaxVRate:      int(data[11]),
+					MaxPixelClock: (int(data[3]) | int(data[4])<<8 | int(data[5])<<16 + 1) * 10_000,
+				}
+			}
+		case displayIDDynamicRange:
+			if len(data) >= 9 {
+				maxV := int(data[7])
+				if revision >= 1 {
+					maxV |= int(data[8]&0x03) << 8
+				}
+				d.Range = &RangeLimits{
+					MinVRate:      int(data[6]),
+					MaxVRate:      maxV,
+					MaxPixelClock: (int(data[3]) | int(data[4])<<8 | int(data[5])<<16) * 1000,
+				}
+			}
+		case displayIDAdaptiveSync:
+			// Descriptors of 6 bytes, plus the extra bytes of newer revisions.
+			stride := 6 + int(revision>>4&0x07)
+			for j := 0; j+6 <= len(data); j += stride {
+				minV := int(data[j+2])
+				maxV := int(data[j+3]) | int(data[j+4]&0x03)<<8 + 1
+				if d.VRRMax == 0 || maxV-minV > d.VRRMax-d.VRRMin {
+					d.VRRMin, d.VRRMax = minV, maxV
+				}
+			}
+		}
+	}
+}
+
+func parseDisplayIDTiming(d []byte, unit int) Timing {
+	word := func(i int) int { return int(binary.LittleEndian.Uint16(d[i:i+2])) & 0x7fff }
+	hActive, hBlank, hFront, hSync := word(4)+1, word(6)+1, word(8)+1, word(10)+1
+	vActive, vBlank, vFront, vSync := word(12)+1, word(14)+1, word(16)+1, word(18)+1
+	t := exactTiming("DisplayID", hActive, vActive,
+		hFront, hSync, hBlank-hFront-hSync, vFront, vSync, vBlank-vFront-vSync,
+		d[9]&0x80 != 0, d[17]&0x80 != 0,
+		(int(d[0])|int(d[1])<<8|int(d[2])<<16+1)*unit)
+	// Like detailed timings, interlaced timings give the lines of a field.
+	if d[3]&0x10 != 0 {
+		t = fieldToFrame(t)
+	}
+	return Timing{
+		Preferred:  d[3]&0x80 != 0,
+		Interlaced: t.Interlaced,
+		Timing:     t,
+	}
+}
+
+func parseTile(d []byte) *Tile {
+	high := int(d[3])
+	return &Tile{
+		SingleEnclosure: d[0]&0x80 != 0,
+		HTiles:          (int(d[1]>>4) | high>>6&0x03<<4) + 1,
+		VTiles:          (int(d[1]&0x0f) | high>>4&0x03<<4) + 1,
+		HLocation:       int(d[2]>>4) | high>>2&0x03<<4,
+		VLocation:       int(d[2]&0x0f) | high&0x03<<4,
+		Width:           int(binary.LittleEndian.Uint16(d[4:6])) + 1,
+		Height:          int(binary.LittleEndian.Uint16(d[6:8])) + 1,
+	}
+}
+
+// TiledDisplay is the whole display an EDID with a DisplayID tile belongs
+// to, with the timing of one tile: the first timing of the tile size,
+// preferred ones first. ok is false without a tile or a timing of its size.
+func (e *EDID) TiledDisplay(colorDepth video.ColorDepth) (t video.TiledDisplay, ok bool) {
+	if e.DisplayID == nil || e.DisplayID.Tile == nil {
+		return video.TiledDisplay{}, false
+	}
+	tile := e.DisplayID.Tile
+	timings := slices.Clone(e.Timings)
+	slices.SortStableFunc(timings, func(a, b Timing) int {
+		switch {
+		case a.Preferred == b.Preferred:
+			return 0
+		case a.Preferred:
+			return -1
+		}
+		return 1
+	})
+	for _, tm := range timings {
+		if tm.Timing.Width != tile.Width || tm.Timing.Height != tile.Height || tm.Interlaced {
+			continue
+		}
+		t, err := video.NewTiledDisplay(tm.Display(colorDepth), tile.HTiles, tile.VTiles)
+		return t, err == nil
+	}
+	return video.TiledDisplay{}, false
+}
@@ -0,0 +1,116 @@
+package edid
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func TestParseDisplayID20(t *testing.T) {
+	e, err := ReadFile("testdata/tiled.hex")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(e.Warnings) > 0 {
+		t.Errorf("warnings: %q", e.Warnings)
+	}
+	d := e.DisplayID
+	if d == nil || d.Version != "2.0" || !d.DSC || d.VRRMin != 40 || d.VRRMax != 60 {
+		t.Fatalf("DisplayID = %+v", d)
+	}
+	want := Tile{HTiles: 2, VTiles: 1, HLocation: 1, Width: 3840, Height: 4320, SingleEnclosure: true}
+	if d.Tile == nil || *d.Tile != want {
+		t.Fatalf("tile = %+v, want %+v", d.Tile, want)
+	}
+	if w, h := d.Tile.Size(); w != 7680 || h != 4320 {
+		t.Errorf("tiled size = %dx%d", w, h)
+	}
+	if len(e.Timings) != 2 {
+		t.Fatalf("%d timings, want 2", len(e.Timings))
+	}
+	tm := e.Timings[1]
+	if tm.Source != "DisplayID 2" || !tm.Preferred || tm.Timing.PixelClock != 1_039_584_000 ||
+		tm.Timing.HTotal() != 3920 || tm.Timing.VTotal() != 4420 || !tm.Timing.HSyncPositive || tm.Timing.VSyncPositive {
+		t.Errorf("Type VII timing = %+v", tm)
+	}
+}
+
+func TestParseDisplayID13(t *testing.T) {
+	// A Type I timing of 1920x1080i, in field lines, and the range limits.
+	blocks := []byte{
+		0x03, 0x00, 20,
+		0x00, 0x1d, 0x00, 0x10,
+		0x7f, 0x07, 0x17, 0x01, 0x57, 0x80, 0x2b, 0x00,
+		0x1b, 0x02, 0x15, 0x00, 0x01, 0x80, 0x04, 0x00,
+		0x09, 0x00, 15,
+		0x00, 0x00, 0x00, 0x97, 0x3a, 0x00, 0x1e, 0x96, 0x00, 0x00, 0x18, 0x4b, 0x00, 0x00, 0x00,
+	}
+	section := append([]byte{0x13, byte(len(blocks)), 0x00, 0x00}, blocks...)
+	section = append(section, sectionChecksum(section))
+	e := &EDID{}
+	e.parseDisplayID(section)
+	if len(e.Warnings) > 0 {
+		t.Errorf("warnings: %q", e.Warnings)
+	}
+	if e.DisplayID == nil || e.DisplayID.Version != "1.3" {
+		t.Fatalf("DisplayID = %+v", e.DisplayID)
+	}
+	if r := e.DisplayID.Range; r == nil || *r != (RangeLimits{MinVRate: 24, MaxVRate: 75, MinHRate: 30, MaxHRate: 150, MaxPixelClock: 150_000_000}) {
+		t.Errorf("range limits = %+v", r)
+	}
+	if len(e.Timings) != 1 {
+		t.Fatalf("%d timings, want 1", len(e.Timings))
+	}
+	tm := e.Timings[0]
+	if !tm.Interlaced || tm.Timing.Width != 1920 || tm.Timing.Height != 1080 || tm.Timing.PixelClock != 74_250_000 || tm.Timing.VTotal() != 1125 {
+		t.Errorf("Type I timing = %+v, total %dx%d", tm.Timing, tm.Timing.HTotal(), tm.Timing.VTotal())
+	}
+}
+
+func TestParseDisplayIDOverrun(t *testing.T) {
+	// A tiled display block that claims more bytes than the section has.
+	section := []byte{0x20, 6, 0x00, 0x00, 0x28, 0x00, 22, 0x80, 0x10, 0x10}
+	section = append(section, sectionChecksum(section))
+	e := &EDID{}
+	e.parseDisplayID(append(section, make([]byte, 16)...))
+	if !slices.Contains(e.Warnings, "DisplayID data block 0x28 overruns the section") {
+		t.Errorf("warnings = %q", e.Warnings)
+	}
+	if e.DisplayID.Tile != nil {
+		t.Errorf("tile = %+v from a truncated block", e.DisplayID.Tile)
+	}
+}
+
+func sectionChecksum(section []byte) byte {
+	var sum byte
+	for _, b := range section {
+		sum += b
+	}
+	return -sum
+}
+
+func TestTiledDisplay(t *testing.T) {
+	e, err := ReadFile("testdata/tiled.hex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tiled, ok := e.TiledDisplay(video.ColorDepth8bit())
+	if !ok {
+		t.Fatal("no tiled display")
+	}
+	if w, h := tiled.Size(); w != 7680 || h != 4320 || tiled.Tiles() != 2 {
+		t.Errorf("tiled display = %v", tiled)
+	}
+	if tm, ok := tiled.Tile.Timing.(video.ExactTiming); !ok || tm.PixelClock != 1_039_584_000 {
+		t.Errorf("tile timing = %v, want the Type VII timing", tiled.Tile.Timing)
+	}
+
+	if _, ok := (&EDID{}).TiledDisplay(video.ColorDepth8bit()); ok {
+		t.Error("TiledDisplay of an EDID without DisplayID succeeded")
+	}
+	e.Timings = e.Timings[:1]
+	if _, ok := e.TiledDisplay(video.ColorDepth8bit()); ok {
+		t.Error("TiledDisplay without a timing of the tile size succeeded")
+	}
+}
@@ -0,0 +1,450 @@
+// Package edid parses EDID 1.3 and 1.4 base blocks and their CTA-861 and
+// DisplayID extension blocks into the displays a monitor advertises.
+package edid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+const blockSize = 128
+
+var header = []byte{0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00}
+
+// EDID is a parsed EDID with its extension blocks.
+type EDID struct {
+	Manufacturer string
+	ProductCode  uint16
+	SerialNumber uint32
+	Week         int
+	Year         int
+	Version      int
+	Revision     int
+	// Name and Serial come from the display descriptors, empty when absent.
+	Name    string
+	Serial  string
+	Digital bool
+	// BitDepth is the bits per color component of a digital input, zero
+	// when undefined.
+	BitDepth int
+	// Interface is the digital interface of an EDID 1.4, e.g. "HDMI-a".
+	Interface string
+	WidthCM   int
+	HeightCM  int
+	YCbCr444  bool
+	YCbCr422  bool
+	// Timings are the timings of each block in order: for a CTA-861 block,
+	// its detailed timings then its short video descriptors.
+	Timings     []Timing
+	RangeLimits *RangeLimits
+	CTA         *CTA
+	DisplayID   *DisplayID
+	// Extensions is the number of extension blocks the base block declares.
+	Extensions int
+	// Warnings lists the recoverable problems, such as bad checksums.
+	Warnings []string
+}
+
+// Timing is a mode the EDID advertises.
+type Timing struct {
+	// Source is where the timing comes from, e.g. "DTD 1" or "VIC 97".
+	Source string
+	// VIC is the CTA-861 video identification code, zero for a detailed
+	// timing.
+	VIC       int
+	Preferred bool
+	// Native is set for the native formats of a CTA-861 extension.
+	Native     bool
+	Interlaced bool
+	// YCbCr420 is set when the mode supports 4:2:0, OnlyYCbCr420 when it
+	// supports nothing else.
+	YCbCr420     bool
+	OnlyYCbCr420 bool
+	// WidthMM and HeightMM are the image size of a detailed timing.
+	WidthMM  int
+	HeightMM int
+	Timing   video.ExactTiming
+}
+
+func (t Timing) String() string {
+	return fmt.Sprintf("%dx%d%s@%.2fHz", t.Timing.Width, t.Timing.Height, video.ScanSuffix(t.Interlaced), t.Timing.RefreshRate())
+}
+
+// RangeLimits is the display range limits descriptor.
+type RangeLimits struct {
+	MinVRate int `json:"min_vrate_hz,omitempty" yaml:"min_vrate_hz,omitempty"`
+	MaxVRate int `json:"max_vrate_hz,omitempty" yaml:"max_vrate_hz,omitempty"`
+	// MinHRate and MaxHRate are in kHz.
+	MinHRate int `json:"min_hrate_khz,omitempty" yaml:"min_hrate_khz,omitempty"`
+	MaxHRate int `json:"max_hrate_khz,omitempty" yaml:"max_hrate_khz,omitempty"`
+	// MaxPixelClock is in Hz, zero when undefined.
+	MaxPixelClock int `json:"max_pixel_clock_hz,omitempty" yaml:"max_pixel_clock_hz,omitempty"`
+}
+
+// ReadFile reads a binary EDID or a hex dump of one.
+func ReadFile(path string) (*EDID, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	e, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return e, nil
+}
+
+// Decode parses data as a binary EDID when it starts with the EDID header,
+// and as a hex dump otherwise, such as the output of xrandr --verbose or a
+// C array of 0x.. bytes.
+func Decode(data []byte) (*EDID, error) {
+	if !bytes.HasPrefix(data, header) {
+		raw, err := decodeHex(string(data))
+		if err != nil {
+			return nil, err
+		}
+		data = raw
+	}
+	return Parse(data)
+}
+
+func decodeHex(s string) ([]byte, error) {
+	s = strings.NewReplacer("0x", "", "0X", "", ",", " ").Replace(s)
+	var b strings.Builder
+	for _, field := range strings.Fields(s) {
+		// Labels such as the "EDID:" of xrandr --verbose.
+		if !strings.HasSuffix(field, ":") {
+			b.WriteString(field)
+		}
+	}
+	data, err := hex.DecodeString(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("neither a binary EDID nor a hex dump: %w", err)
+	}
+	return data, nil
+}
+
+// Parse parses a binary EDID of one or more 128-byte blocks.
+func Parse(data []byte) (*EDID, error) {
+	if len(data) < blockSize {
+		return nil, fmt.Errorf("EDID too short: %d bytes", len(data))
+	}
+	if !bytes.HasPrefix(data, header) {
+		return nil, errors.New("missing EDID header")
+	}
+	e := &EDID{}
+	if len(data)%blockSize != 0 {
+		e.warn("%d trailing bytes ignored", len(data)%blockSize)
+	}
+	e.parseBase(data[:blockSize])
+	blocks := len(data) / blockSize
+	if blocks-1 != e.Extensions {
+		e.warn("%d extension blocks declared, %d present", e.Extensions, blocks-1)
+	}
+	for i := 1; i < blocks; i++ {
+		block := data[i*blockSize : (i+1)*blockSize]
+		if !checksum(block) {
+			e.warn("bad checksum in block %d", i)
+		}
+		switch block[0] {
+		case 0x02:
+			e.parseCTA(block)
+		case 0x70:
+			e.parseDisplayID(block[1 : blockSize-1])
+		default:
+			e.warn("unsupported extension block %d with tag 0x%02x", i, block[0])
+		}
+	}
+	return e, nil
+}
+
+func (e *EDID) warn(format string, args ...any) {
+	e.Warnings = append(e.Warnings, fmt.Sprintf(format, args...))
+}
+
+func checksum(block []byte) bool {
+	var sum byte
+	for _, b := range block {
+		sum += b
+	}
+	return sum == 0
+}
+
+func (e *EDID) parseBase(b []byte) {
+	if !checksum(b) {
+		e.warn("bad checksum in the base block")
+	}
+	id := binary.BigEndian.Uint16(b[8:10])
+	e.Manufacturer = string([]byte{
+		byte(id>>10&0x1f) + 'A' - 1,
+		byte(id>>5&0x1f) + 'A' - 1,
+		byte(id&0x1f) + 'A' - 1,
+	})
+	e.ProductCode = binary.LittleEndian.Uint16(b[10:12])
+	e.SerialNumber = binary.LittleEndian.Uint32(b[12:16])
+	e.Week = int(b[16])
+	e.Year = int(b[17]) + 1990
+	e.Version, e.Revision = int(b[18]), int(b[19])
+	if e.Version != 1 || e.Revision < 3 || e.Revision > 4 {
+		e.warn("EDID %d.%d is parsed as 1.4", e.Version, e.Revision)
+	}
+
+	input := b[20]
+	e.Digital = input&0x80 != 0
+	if e.Digital && e.Revision >= 4 {
+		if depth := int(input >> 4 & 0x07); depth > 0 && depth < 7 {
+			e.BitDepth = 4 + 2*depth
+		}
+		e.Interface = [...]string{"", "DVI", "HDMI-a", "HDMI-b", "MDDI", "DisplayPort", "", "", "", "", "", "", "", "", "", ""}[input&0x0f]
+	}
+	e.WidthCM, e.HeightCM = int(b[21]), int(b[22])
+	features := b[24]
+	if e.Digital && e.Revision >= 4 {
+		e.YCbCr444 = features&0x08 != 0
+		e.YCbCr422 = features&0x10 != 0
+	}
+	e.Extensions = int(b[126])
+
+	// The first detailed timing is the preferred one, always in EDID 1.4.
+	for i := range 4 {
+		d := b[54+18*i : 54+18*(i+1)]
+		if d[0] != 0 || d[1] != 0 {
+			t := parseDetailedTiming(d)
+			t.Source = fmt.Sprintf("DTD %d", len(e.Timings)+1)
+			t.Preferred = len(e.Timings) == 0 && (features&0x02 != 0 || e.Revision >= 4)
+			e.Timings = append(e.Timings, t)
+			continue
+		}
+		switch d[3] {
+		case 0xfc:
+			e.Name = descriptorText(d)
+		case 0xff:
+			e.Serial = descriptorText(d)
+		case 0xfd:
+			e.RangeLimits = parseRangeLimits(d)
+		}
+	}
+}
+
+func descriptorText(d []byte) string {
+	text, _, _ := bytes.Cut(d[5:18], []byte{'\n'})
+	return strings.TrimSpace(string(text))
+}
+
+func parseRangeLimits(d []byte) *RangeLimits {
+	// EDID 1.4 adds 255 to the rates whose offset flag is set.
+	offset := func(bit uint) int {
+		if d[4]>>bit&1 != 0 {
+			return 255
+		}
+		return 0
+	}
+	return &RangeLimits{
+		MinVRate:      int(d[5]) + offset(0),
+		MaxVRate:      int(d[6]) + offset(1),
+		MinHRate:      int(d[7]) + offset(2),
+		MaxHRate:      int(d[8]) + offset(3),
+		MaxPixelClock: int(d[9]) * 10e6,
+	}
+}
+
+func parseDetailedTiming(d []byte) Timing {
+	hActive := int(d[2]) | int(d[4]>>4)<<8
+	hBlank := int(d[3]) | int(d[4]&0x0f)<<8
+	vActive := int(d[5]) | int(d[7]>>4)<<8
+	vBlank := int(d[6]) | int(d[7]&0x0f)<<8
+	hFront := int(d[8]) | int(d[11]>>6&0x03)<<8
+	hSync := int(d[9]) | int(d[11]>>4&0x03)<<8
+	vFront := int(d[10]>>4) | int(d[11]>>2&0x03)<<4
+	vSync := int(d[10]&0x0f) | int(d[11]&0x03)<<4
+	flags := d[17]
+	// Only digital separate sync carries both polarities.
+	separate := flags>>3&0x03 == 0x03
+	t := exactTiming("EDID", hActive, vActive, hFront, hSync, hBlank-hFront-hSync, vFront, vSync, vBlank-vFront-vSync,
+		separate && flags&0x02 != 0, separate && flags&0x04 != 0, int(binary.LittleEndian.Uint16(d[0:2]))*10e3)
+	if flags&0x80 != 0 {
+		t = fieldToFrame(t)
+	}
+	return Timing{
+		Interlaced: t.Interlaced,
+		WidthMM:    int(d[12]) | int(d[14]>>4)<<8,
+		HeightMM:   int(d[13]) | int(d[14]&0x0f)<<8,
+		Timing:     t,
+	}
+}
+
+// fieldToFrame turns the vertical timings of one field of an interlaced
+// mode, as detailed timings give them, into those of a frame: both fields
+// and the half line between them.
+func fieldToFrame(t video.ExactTiming) video.ExactTiming {
+	vTotal := 2*t.VTotal() + 1
+	t.Height *= 2
+	t.VFrontPorch *= 2
+	t.VSync *= 2
+	t.VBackPorch = vTotal - t.Height - t.VFrontPorch - t.VSync
+	t.Interlaced = true
+	return t
+}
+
+// frameToField is the reverse of fieldToFrame.
+func frameToField(t video.ExactTiming) video.ExactTiming {
+	vBlank := (t.VTotal() - t.Height - 1) / 2
+	t.Height /= 2
+	t.VFrontPorch /= 2
+	t.VSync /= 2
+	t.VBackPorch = vBlank - t.VFrontPorch - t.VSync
+	return t
+}
+
+func exactTiming(name string, width, height, hFront, hSync, hBack, vFront, vSync, vBack int, hPositive, vPositive bool, clock int) video.ExactTiming {
+	return video.ExactTiming{
+		Name:          name,
+		Width:         width,
+		Height:        height,
+		HFrontPorch:   hFront,
+		HSync:         hSync,
+		HBackPorch:    hBack,
+		VFrontPorch:   vFront,
+		VSync:         vSync,
+		VBackPorch:    vBack,
+		HSyncPositive: hPositive,
+		VSyncPositive: vPositive,
+		PixelClock:    clock,
+	}
+}
+
+// VRR is the adaptive-sync range of DisplayID, or else the VRR range of
+// the HDMI Forum block, in Hz; zero without VRR.
+func (e *EDID) VRR() (minRate, maxRate int) {
+	if d := e.DisplayID; d != nil && d.VRRMax > 0 {
+		return d.VRRMin, d.VRRMax
+	}
+	if e.CTA != nil && e.CTA.HDMI != nil && e.CTA.HDMI.VRRMax > 0 {
+		return e.CTA.HDMI.VRRMin, e.CTA.HDMI.VRRMax
+	}
+	return 0, 0
+}
+
+// ColorDepths lists the supported bits per component, ascending.
+func (e *EDID) ColorDepths() []int {
+	depths := []int{8}
+	if e.BitDepth > 0 {
+		depths = append(depths, e.BitDepth)
+	}
+	if e.CTA != nil && e.CTA.HDMI != nil {
+		depths = append(depths, e.CTA.HDMI.DeepColor...)
+	}
+	slices.Sort(depths)
+	return slices.Compact(depths)
+}
+
+// Encodings lists the supported pixel encodings.
+func (e *EDID) Encodings() []video.Encoding {
+	out := []video.Encoding{video.EncodingRGB}
+	if e.YCbCr444 || e.CTA != nil && e.CTA.YCbCr444 {
+		out = append(out, video.EncodingYCbCr444)
+	}
+	if e.YCbCr422 || e.CTA != nil && e.CTA.YCbCr422 {
+		out = append(out, video.EncodingYCbCr422)
+	}
+	if slices.ContainsFunc(e.Timings, func(t Timing) bool { return t.YCbCr420 }) {
+		out = append(out, video.EncodingYCbCr420)
+	}
+	return out
+}
+
+// Displays returns the timings as displays with exact timings, at the
+// color depth of the panel or 8 bpc when it is undefined or not supported
+// by gvbc. 4:2:0-only modes use that encoding.
+func (e *EDID) Displays() []video.Display {
+	colorDepth, err := video.ColorDepthFromBPC(e.BitDepth)
+	if err != nil {
+		colorDepth = video.ColorDepth8bit()
+	}
+	var out []video.Display
+	for _, t := range e.Timings {
+		out = append(out, t.Display(colorDepth))
+	}
+	return out
+}
+
+// Sink describes the monitor in the terms of the sink catalog. The inputs
+// are guessed: HDMI from the vendor blocks, up to the FRL rate of the HDMI
+// Forum one, and DisplayPort from the interface of an EDID 1.4 or a
+// DisplayID block, at the newest version since EDIDs do not tell.
+func (e *EDID) Sink() video.Sink {
+	s := video.Sink{Name: e.Name, Category: "EDID"}
+	if s.Name == "" {
+		s.Name = fmt.Sprintf("%s %04X", e.Manufacturer, e.ProductCode)
+	}
+	for _, d := range e.Displays() {
+		m := video.SinkMode{Width: d.Width, Height: d.Height, RefreshRate: d.RefreshRate, Interlaced: d.Interlaced}
+		if !slices.Contains(s.Modes, m) {
+			s.Modes = append(s.Modes, m)
+		}
+	}
+	for _, bpc := range e.ColorDepths() {
+		if _, err := video.ColorDepthFromBPC(bpc); err == nil {
+			s.MaxBPC = bpc
+		}
+	}
+	s.VRRMin, s.VRRMax = e.VRR()
+	var hdmi *HDMI
+	if e.CTA != nil {
+		hdmi = e.CTA.HDMI
+	}
+	s.DSC = hdmi != nil && hdmi.DSC != nil || e.DisplayID != nil && e.DisplayID.DSC
+	if hdmi != nil || strings.HasPrefix(e.Interface, "HDMI") {
+		version, rate := "1.4", ""
+		switch {
+		case hdmi != nil && hdmi.MaxFRLRate > 0:
+			version, rate = "2.1", hdmi.FRL()
+		case hdmi != nil && (hdmi.Forum || hdmi.MaxTMDSClock > 340e6):
+			version = "2.0"
+		}
+		if p, ok := inputPort("HDMI", version, rate); ok {
+			s.Inputs = append(s.Inputs, p)
+		}
+	}
+	if e.Interface == "DisplayPort" || e.DisplayID != nil && hdmi == nil {
+		if f, ok := video.LookupInterfaceFamily("DisplayPort"); ok && len(f.Versions) > 0 {
+			if p, ok := inputPort("DisplayPort", f.Versions[0].Version, ""); ok {
+				s.Inputs = append(s.Inputs, p)
+			}
+		}
+	}
+	return s
+}
+
+func inputPort(family, version, rate string) (video.Port, bool) {
+	f, ok := video.LookupInterfaceFamily(family)
+	if !ok {
+		return video.Port{}, false
+	}
+	i, ok := f.Version(version)
+	if !ok {
+		return video.Port{}, false
+	}
+	p := video.Port{Name: family, Interface: i}
+	if m, ok := video.LookupLinkMode(rate); ok {
+		p.MaxRate = m
+		p.Interface = p.Capped(i)
+	}
+	return p, true
+}
+
+func (t Timing) Display(colorDepth video.ColorDepth) video.Display {
+	encoding := video.EncodingRGB
+	if t.OnlyYCbCr420 {
+		encoding = video.EncodingYCbCr420
+	}
+	return t.Timing.Display(colorDepth, encoding)
+}
@@ -0,0 +1,132 @@
+package edid
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadFile(t *testing.T) {
+	e, err := ReadFile("testdata/hdmi.hex")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(e.Warnings) > 0 {
+		t.Errorf("warnings: %q", e.Warnings)
+	}
+	if e.Manufacturer != "GVB" || e.ProductCode != 0x1234 || e.Name != "GVBC TEST" || e.Serial != "SN123" || e.Year != 2024 {
+		t.Errorf("identity = %s %04X %q %q %d", e.Manufacturer, e.ProductCode, e.Name, e.Serial, e.Year)
+	}
+	if !e.Digital || e.BitDepth != 10 || e.Interface != "HDMI-a" || !e.YCbCr444 || !e.YCbCr422 {
+		t.Errorf("input = digital %v, %d bpc, %q, 4:4:4 %v, 4:2:2 %v", e.Digital, e.BitDepth, e.Interface, e.YCbCr444, e.YCbCr422)
+	}
+	if r := e.RangeLimits; r == nil || *r != (RangeLimits{MinVRate: 48, MaxVRate: 300, MinHRate: 30, MaxHRate: 160, MaxPixelClock: 600e6}) {
+		t.Errorf("range limits = %+v", r)
+	}
+
+	var got []string
+	for _, tm := range e.Timings {
+		got = append(got, tm.Source+" "+tm.String())
+	}
+	want := []string{
+		"DTD 1 1920x1080@60.00Hz",
+		"DTD 2 1280x720@60.00Hz",
+		"VIC 16 1920x1080@60.00Hz",
+		"VIC 4 1280x720@60.00Hz",
+		"VIC 5 1920x1080i@60.00Hz",
+		"VIC 97 3840x2160@60.00Hz",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("timings = %q, want %q", got, want)
+	}
+	if dtd := e.Timings[0]; !dtd.Preferred || !dtd.Native || dtd.WidthMM != 600 || dtd.HeightMM != 340 ||
+		!dtd.Timing.HSyncPositive || !dtd.Timing.VSyncPositive || dtd.Timing.HTotal() != 2200 || dtd.Timing.VTotal() != 1125 {
+		t.Errorf("DTD 1 = %+v", dtd)
+	}
+	if e.Timings[1].Native || e.Timings[1].Preferred {
+		t.Errorf("DTD 2 = %+v, want neither native nor preferred", e.Timings[1])
+	}
+	if vic := e.Timings[2]; !vic.Native || vic.VIC != 16 {
+		t.Errorf("VIC 16 = %+v, want native", vic)
+	}
+	if i := e.Timings[4]; !i.Interlaced || i.Timing.Height != 1080 {
+		t.Errorf("VIC 5 = %+v, want 1080 interlaced lines", i)
+	}
+	if uhd := e.Timings[5]; !uhd.YCbCr420 || uhd.OnlyYCbCr420 {
+		t.Errorf("VIC 97 = %+v, want 4:2:0 besides RGB", uhd)
+	}
+
+	c := e.CTA
+	if c == nil || c.Revision != 3 || !c.Underscan || !c.BasicAudio || c.HDMI == nil || c.HDR == nil {
+		t.Fatalf("CTA = %+v", c)
+	}
+	h := c.HDMI
+	if h.PhysicalAddress != "1.0.0.0" || !slices.Equal(h.DeepColor, []int{10, 12}) || !h.DeepColor444 || h.MaxTMDSClock != 300e6 {
+		t.Errorf("HDMI VSDB = %+v", h)
+	}
+	if !h.Forum || h.MaxTMDSCharacterRate != 600e6 || !h.SCDC || h.FRL() != "FRL 5" || !slices.Equal(h.DeepColor420, []int{10}) ||
+		!h.ALLM || h.VRRMin != 48 || h.VRRMax != 120 || h.DSC != nil {
+		t.Errorf("HDMI Forum VSDB = %+v", h)
+	}
+	if !slices.Equal(c.HDR.EOTFs, []string{"SDR", "PQ"}) {
+		t.Errorf("HDR EOTFs = %q", c.HDR.EOTFs)
+	}
+	if !slices.Equal(e.ColorDepths(), []int{8, 10, 12}) {
+		t.Errorf("color depths = %v", e.ColorDepths())
+	}
+	if minRate, maxRate := e.VRR(); minRate != 48 || maxRate != 120 {
+		t.Errorf("VRR = %d-%d Hz", minRate, maxRate)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse(make([]byte, 64)); err == nil || !strings.Contains(err.Error(), "too short") {
+		t.Errorf("short EDID error = %v", err)
+	}
+	if _, err := Parse(make([]byte, blockSize)); err == nil || !strings.Contains(err.Error(), "header") {
+		t.Errorf("headerless EDID error = %v", err)
+	}
+	if _, err := Decode([]byte("not an EDID")); err == nil {
+		t.Error("Decode of text succeeded")
+	}
+}
+
+func TestParseWarnings(t *testing.T) {
+	data := readHex(t, "testdata/hdmi.hex")
+	tests := []struct {
+		name string
+		edit func([]byte) []byte
+		want string
+	}{
+		{"base checksum", func(b []byte) []byte { b[127]++; return b }, "bad checksum in the base block"},
+		{"extension checksum", func(b []byte) []byte { b[255]++; return b }, "bad checksum in block 1"},
+		{"missing extension", func(b []byte) []byte { return b[:blockSize] }, "1 extension blocks declared, 0 present"},
+		{"trailing bytes", func(b []byte) []byte { return append(b, 0) }, "1 trailing bytes ignored"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Parse(tt.edit(slices.Clone(data)))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !slices.Contains(e.Warnings, tt.want) {
+				t.Fatalf("warnings = %q, want %q", e.Warnings, tt.want)
+			}
+		})
+	}
+}
+
+// readHex reads a hex dump from testdata.
+func readHex(t *testing.T, path string) []byte {
+	t.Helper()
+	text, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := decodeHex(string(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
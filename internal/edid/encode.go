@@ -0,0 +1,514 @@
+package edid
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+// Options are what a generated EDID declares beyond its modes.
+type Options struct {
+	// Name is the monitor name, at most 13 characters.
+	Name string
+	// Manufacturer is the three-letter PNP ID, "GVB" when empty.
+	Manufacturer string
+	ProductCode  uint16
+	SerialNumber uint32
+	// Year is the year of manufacture, the current one when zero.
+	Year int
+	// Interface sets the digital interface of the base block, the range
+	// limits and, for HDMI, the vendor-specific data blocks.
+	Interface video.Interface
+	// VICs sends the modes that have a CTA-861 video code at the CTA timing
+	// and lists them as short video descriptors, unless their timing is
+	// exact.
+	VICs bool
+}
+
+// Build generates an EDID 1.4 with a CTA-861 extension block advertising
+// displays, the first one as the preferred mode. Modes too fast for a
+// detailed timing go into a DisplayID 2.0 extension block; when none fits
+// one, the base block gets a slower mode, and the preferred one stays
+// preferred in the DisplayID block.
+func Build(displays []video.Display, o Options) ([]byte, error) {
+	if len(displays) == 0 {
+		return nil, errors.New("no mode to advertise")
+	}
+	if o.Manufacturer == "" {
+		o.Manufacturer = "GVB"
+	}
+	if len(o.Manufacturer) != 3 || strings.Trim(o.Manufacturer, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return nil, fmt.Errorf("invalid manufacturer ID %q: want three capital letters", o.Manufacturer)
+	}
+	if len(o.Name) > 13 {
+		return nil, fmt.Errorf("monitor name %q is longer than 13 characters", o.Name)
+	}
+	if o.Year == 0 {
+		o.Year = time.Now().Year()
+	}
+	if o.Year < 1990 || o.Year > 1990+255 {
+		return nil, fmt.Errorf("invalid year %d", o.Year)
+	}
+
+	// all are the timings of every mode, which the range limits span.
+	var all, dtds, displayID []video.ExactTiming
+	// displayIDPreferred is set when the preferred mode only fits the
+	// DisplayID block.
+	displayIDPreferred := false
+	var svds []byte
+	// only420 are the SVDs of modes sent in YCbCr 4:2:0 only.
+	var only420 []byte
+	bpc := 8
+	for i, d := range displays {
+		bpc = max(bpc, d.ColorDepth.BPC())
+		t := d.ExactTiming()
+		vic, ok := o.lookupVIC(d)
+		if ok {
+			t = vics[vic]
+		}
+		all = append(all, t)
+		t = shortenPorches(t)
+		if ok {
+			if d.Encoding == video.EncodingYCbCr420 {
+				// VICs are never flagged native, so the code is the VIC.
+				only420 = append(only420, byte(vic))
+				continue
+			}
+			svds = append(svds, byte(vic))
+		}
+		switch {
+		case fitsDetailedTiming(t) && (i == 0 || !ok):
+			dtds = append(dtds, t)
+		case !ok:
+			if len(displayID) == 5 {
+				return nil, fmt.Errorf("too many modes: %s does not fit the DisplayID block", d)
+			}
+			displayID = append(displayID, t)
+			displayIDPreferred = displayIDPreferred || i == 0
+		}
+	}
+	if len(dtds) == 0 {
+		// The base block needs a detailed timing: the first mode that fits
+		// one, even when it is also a VIC, or else the preferred mode at a
+		// lower refresh rate, or 1080p60 for a mode too large for any.
+		if i := slices.IndexFunc(all, func(t video.ExactTiming) bool { return fitsDetailedTiming(shortenPorches(t)) }); i >= 0 {
+			dtds = append(dtds, shortenPorches(all[i]))
+		} else if t, ok := slowerTiming(displays[0]); ok {
+			dtds = append(dtds, t)
+			all = append(all, t)
+		} else {
+			dtds = append(dtds, vics[16])
+			all = append(all, vics[16])
+		}
+	}
+
+	b := make([]byte, blockSize)
+	copy(b, header)
+	id := uint16(o.Manufacturer[0]-'A'+1)<<10 | uint16(o.Manufacturer[1]-'A'+1)<<5 | uint16(o.Manufacturer[2]-'A'+1)
+	binary.BigEndian.PutUint16(b[8:10], id)
+	binary.LittleEndian.PutUint16(b[10:12], o.ProductCode)
+	binary.LittleEndian.PutUint32(b[12:16], o.SerialNumber)
+	b[17] = byte(o.Year - 1990)
+	b[18], b[19] = 1, 4
+	depth := byte(0)
+	if bpc <= 16 {
+		depth = byte(bpc-4) / 2
+	}
+	b[20] = 0x80 | depth<<4
+	// The preferred timing is native and the colorimetry sRGB; HDMI sinks
+	// accept YCbCr 4:4:4 and 4:2:2.
+	b[24] = 0x06
+	switch o.Interface.Family {
+	case "HDMI":
+		b[20] |= 0x02
+		b[24] |= 0x18
+	case "DisplayPort":
+		b[20] |= 0x05
+	case "DVI":
+		b[20] |= 0x01
+	}
+	// The sRGB primaries and white point.
+	copy(b[25:35], []byte{0xee, 0x91, 0xa3, 0x54, 0x4c, 0x99, 0x26, 0x0f, 0x50, 0x54})
+	for i := 38; i < 54; i++ {
+		// Unused standard timings.
+		b[i] = 0x01
+	}
+
+	n := min(len(dtds), 2)
+	descriptors := make([][]byte, 0, 4)
+	for _, t := range dtds[:n] {
+		descriptors = append(descriptors, detailedTiming(t))
+	}
+	if n == 1 {
+		descriptors = append(descriptors, []byte{0, 0, 0, 0x10, 0})
+	}
+	descriptors = append(descriptors, rangeLimits(all, o.Interface))
+	name := o.Name
+	if name == "" {
+		name = "gvbc"
+	}
+	descriptors = append(descriptors, textDescriptor(0xfc, name))
+	for i, d := range descriptors {
+		copy(b[54+18*i:], d)
+	}
+
+	cta, err := ctaBlock(svds, only420, dtds[n:], bpc, o.Interface)
+	if err != nil {
+		return nil, err
+	}
+	blocks := [][]byte{b, cta}
+	if len(displayID) > 0 {
+		blocks = append(blocks, displayIDBlock(displayID, displayIDPreferred, o.Interface))
+	}
+	b[126] = byte(len(blocks) - 1)
+	var out []byte
+	for _, block := range blocks {
+		setChecksum(block)
+		out = append(out, block...)
+	}
+	return out, nil
+}
+
+// lookupVIC returns the video code of the mode of d, the lowest when
+// several aspect ratios share it.
+func (o Options) lookupVIC(d video.Display) (int, bool) {
+	exact, isExact := d.Timing.(video.ExactTiming)
+	if !o.VICs && !isExact {
+		return 0, false
+	}
+	codes := make([]int, 0, len(vics))
+	for vic := range vics {
+		codes = append(codes, vic)
+	}
+	slices.Sort(codes)
+	for _, vic := range codes {
+		t := vics[vic]
+		if isExact {
+			exact.Name = t.Name
+			if exact == t {
+				return vic, true
+			}
+			continue
+		}
+		if t.Width == d.Width && t.Height == d.Height && t.Interlaced == d.Interlaced &&
+			int(math.Round(t.RefreshRate())) == d.RefreshRate {
+			return vic, true
+		}
+	}
+	return 0, false
+}
+
+// shortenPorches moves the front porch lines and pixels a detailed timing
+// cannot hold into the back porch, such as the long vertical front porch
+// of CVT-RBv2 at high refresh rates.
+func shortenPorches(t video.ExactTiming) video.ExactTiming {
+	if t.VFrontPorch > 63 {
+		t.VBackPorch += t.VFrontPorch - 63
+		t.VFrontPorch = 63
+	}
+	if t.HFrontPorch > 1023 {
+		t.HBackPorch += t.HFrontPorch - 1023
+		t.HFrontPorch = 1023
+	}
+	return t
+}
+
+// slowerTiming returns the timing of d at the fastest refresh rate below
+// its own that fits a detailed timing. An exact timing keeps its blanking
+// and gets a slower pixel clock.
+func slowerTiming(d video.Display) (video.ExactTiming, bool) {
+	if _, ok := d.Timing.(video.ExactTiming); ok {
+		t := shortenPorches(d.ExactTiming())
+		frame := t.HTotal() * t.VTotal()
+		if t.Interlaced {
+			frame /= 2
+		}
+		t.PixelClock = math.MaxUint16 * 10e3 / frame * frame
+		return t, fitsDetailedTiming(t)
+	}
+	for d.RefreshRate--; d.RefreshRate > 0; d.RefreshRate-- {
+		if t := shortenPorches(d.ExactTiming()); fitsDetailedTiming(t) {
+			return t, true
+		}
+	}
+	return video.ExactTiming{}, false
+}
+
+func fitsDetailedTiming(t video.ExactTiming) bool {
+	if t.Interlaced {
+		t = frameToField(t)
+	}
+	hBlank, vBlank := t.HTotal()-t.Width, t.VTotal()-t.Height
+	return t.PixelClock > 0 && (t.PixelClock+5000)/10e3 <= math.MaxUint16 &&
+		t.Width < 4096 && hBlank < 4096 && t.Height < 4096 && vBlank < 4096 &&
+		t.HFrontPorch < 1024 && t.HSync < 1024 && t.VFrontPorch < 64 && t.VSync < 64
+}
+
+// detailedTiming encodes t, which must fit, as parseDetailedTiming reads
+// it back, with the pixel clock rounded to 10 kHz.
+func detailedTiming(t video.ExactTiming) []byte {
+	d := make([]byte, 18)
+	interlaced := t.Interlaced
+	if interlaced {
+		t = frameToField(t)
+	}
+	hBlank, vBlank := t.HTotal()-t.Width, t.VTotal()-t.Height
+	binary.LittleEndian.PutUint16(d[0:2], uint16((t.PixelClock+5000)/10e3))
+	d[2], d[3] = byte(t.Width), byte(hBlank)
+	d[4] = byte(t.Width>>8)<<4 | byte(hBlank>>8)
+	d[5], d[6] = byte(t.Height), byte(vBlank)
+	d[7] = byte(t.Height>>8)<<4 | byte(vBlank>>8)
+	d[8], d[9] = byte(t.HFrontPorch), byte(t.HSync)
+	d[10] = byte(t.VFrontPorch&0x0f)<<4 | byte(t.VSync&0x0f)
+	d[11] = byte(t.HFrontPorch>>8)<<6 | byte(t.HSync>>8)<<4 | byte(t.VFrontPorch>>4)<<2 | byte(t.VSync>>4)
+	// Digital separate sync.
+	d[17] = 0x18
+	if interlaced {
+		d[17] |= 0x80
+	}
+	if t.VSyncPositive {
+		d[17] |= 0x04
+	}
+	if t.HSyncPositive {
+		d[17] |= 0x02
+	}
+	return d
+}
+
+func textDescriptor(tag byte, text string) []byte {
+	d := []byte{0, 0, 0, tag, 0}
+	d = append(d, text...)
+	if len(d) < 18 {
+		d = append(d, '\n')
+	}
+	for len(d) < 18 {
+		d = append(d, ' ')
+	}
+	return d
+}
+
+// rangeLimits spans the rates of timings, and the pixel clock up to what
+// the fastest link mode of i carries at 8 bpc RGB.
+func rangeLimits(timings []video.ExactTiming, i video.Interface) []byte {
+	minV, maxV, minH, maxH := math.MaxInt, 0, math.MaxInt, 0
+	clock := 0
+	for _, t := range timings {
+		v := t.RefreshRate()
+		h := float64(t.PixelClock) / float64(t.HTotal()) / 1e3
+		minV, maxV = min(minV, int(math.Floor(v))), max(maxV, int(math.Ceil(v)))
+		minH, maxH = min(minH, int(math.Floor(h))), max(maxH, int(math.Ceil(h)))
+		clock = max(clock, t.PixelClock)
+	}
+	for _, m := range i.Modes {
+		clock = max(clock, int(m.EffectiveBandwidth().Bits/24))
+	}
+
+	d := []byte{0, 0, 0, 0xfd, 0}
+	// Rates above 255 are stored with an offset of 255, flagged in byte 4,
+	// and clamped to the 510 the offset reaches.
+	for bit, rate := range []int{minV, maxV, minH, maxH} {
+		if rate > 255 {
+			d[4] |= 1 << bit
+			rate -= 255
+		}
+		d = append(d, byte(min(max(rate, 1), 255)))
+	}
+	d = append(d, byte(min((clock+10e6-1)/10e6, 255)))
+	// Range limits only, no timing formula.
+	d = append(d, 0x01, '\n', ' ', ' ', ' ', ' ', ' ', ' ')
+	return d
+}
+
+// ctaBlock builds the CTA-861 extension block with the video data blocks,
+// the HDMI vendor-specific blocks and HDR metadata of i, then the detailed
+// timings that did not fit the base block.
+func ctaBlock(svds, only420 []byte, dtds []video.ExactTiming, bpc int, i video.Interface) ([]byte, error) {
+	b := make([]byte, blockSize)
+	b[0], b[1] = 0x02, 3
+	var blocks []byte
+	dataBlock := func(tag byte, data ...byte) {
+		blocks = append(blocks, tag<<5|byte(len(data)))
+		blocks = append(blocks, data...)
+	}
+	if len(svds) > 0 {
+		dataBlock(2, svds...)
+	}
+	if len(only420) > 0 {
+		dataBlock(7, append([]byte{14}, only420...)...)
+	}
+	if i.Family == "HDMI" {
+		// The first DTD is native and HDMI sinks take YCbCr.
+		b[3] = 0x30 | 0x01
+		dataBlock(3, hdmiVSDB(bpc, i)...)
+		if forum := forumVSDB(bpc, len(only420) > 0, i); forum != nil {
+			dataBlock(3, forum...)
+		}
+	} else {
+		b[3] = 0x01
+	}
+	if i.HDR && bpc >= 10 {
+		// SDR, PQ and HLG with static metadata type 1.
+		dataBlock(7, 6, 0x0d, 0x01)
+	}
+	offset := 4 + len(blocks)
+	if offset+18*len(dtds) > blockSize-1 {
+		return nil, fmt.Errorf("too many modes: %d detailed timings do not fit the CTA-861 block", len(dtds))
+	}
+	copy(b[4:], blocks)
+	b[2] = byte(offset)
+	for j, t := range dtds {
+		copy(b[offset+18*j:], detailedTiming(t))
+	}
+	return b, nil
+}
+
+// hdmiVSDB is the HDMI 1.x vendor-specific data block at physical address
+// 1.0.0.0, with a TMDS clock of at most 340 MHz.
+func hdmiVSDB(bpc int, i video.Interface) []byte {
+	data := []byte{0x03, 0x0c, 0x00, 0x10, 0x00, deepColor(bpc, 0x10)}
+	if data[5] != 0 {
+		// DC_Y444.
+		data[5] |= 0x08
+	}
+	return append(data, byte(min(maxTMDSClock(i), 340e6)/5e6))
+}
+
+// forumVSDB is the HDMI Forum data block of HDMI 2.0 and newer, nil for
+// older versions.
+func forumVSDB(bpc int, ycbcr420 bool, i video.Interface) []byte {
+	if maxTMDSClock(i) <= 340e6 && maxFRLRate(i) == 0 {
+		return nil
+	}
+	frl := maxFRLRate(i)
+	data := []byte{0xd8, 0x5d, 0xc4, 0x01, byte(maxTMDSClock(i) / 5e6), 0x80, byte(frl) << 4}
+	if ycbcr420 {
+		data[6] |= deepColor(bpc, 0x01)
+	}
+	if i.DSC && frl > 0 {
+		// DSC 1.2a at 10 and 12 bpc with any bits per pixel, up to 8
+		// slices and 16 KB of chunks.
+		data = append(data, 0, 0, 0, 0x80|0x08|0x02|0x01, byte(frl)<<4|0x04, 15)
+	}
+	return data
+}
+
+// deepColor sets the bits from low, for 10, 12 and 16 bpc, up to bpc.
+func deepColor(bpc int, low byte) byte {
+	var flags byte
+	for j, depth := range []int{10, 12, 16} {
+		if bpc >= depth {
+			flags |= low << j
+		}
+	}
+	return flags
+}
+
+// maxTMDSClock is the fastest TMDS character rate of i, in Hz. HDMI 2.1
+// lists FRL rates only and keeps TMDS at 600 MHz.
+func maxTMDSClock(i video.Interface) int {
+	clock := 0
+	for _, m := range i.Modes {
+		if strings.HasPrefix(m.GetName(), "TMDS") {
+			// Three channels of 10 bits per character.
+			clock = max(clock, int(m.GetBandwidth().Bits/30))
+		}
+	}
+	if clock == 0 && maxFRLRate(i) > 0 {
+		return 600e6
+	}
+	return clock
+}
+
+// maxFRLRate is the fastest FRL rate index of i, capped at the 6 the HDMI
+// Forum block can declare.
+func maxFRLRate(i video.Interface) int {
+	rate := 0
+	for _, m := range i.Modes {
+		if n, ok := strings.CutPrefix(m.GetName(), "FRL "); ok {
+			if r, err := strconv.Atoi(n); err == nil {
+				rate = max(rate, min(r, 6))
+			}
+		}
+	}
+	return rate
+}
+
+// displayIDBlock builds a DisplayID 2.0 extension block with Type VII
+// timings, 1 kHz pixel clocks, flagged DSC capable when i has DSC. The
+// first timing is flagged preferred when preferred is set.
+func displayIDBlock(timings []video.ExactTiming, preferred bool, i video.Interface) []byte {
+	b := make([]byte, blockSize)
+	b[0] = 0x70
+	var revision byte
+	if i.DSC {
+		revision = 0x08
+	}
+	data := []byte{displayIDType7Timing, revision, byte(20 * len(timings))}
+	for j, t := range timings {
+		d := type7Timing(t)
+		if j == 0 && preferred {
+			d[3] |= 0x80
+		}
+		data = append(data, d...)
+	}
+	section := b[1 : blockSize-1]
+	section[0], section[1] = 0x20, byte(len(data))
+	copy(section[4:], data)
+	setChecksum(section[:5+len(data)])
+	return b
+}
+
+// type7Timing encodes t as parseDisplayIDTiming reads it back.
+func type7Timing(t video.ExactTiming) []byte {
+	d := make([]byte, 20)
+	clock := (t.PixelClock+500)/1000 - 1
+	d[0], d[1], d[2] = byte(clock), byte(clock>>8), byte(clock>>16)
+	d[3] = aspectRatio(t.Width, t.Height)
+	if t.Interlaced {
+		t = frameToField(t)
+		d[3] |= 0x10
+	}
+	word := func(j, v int, flag bool) {
+		v--
+		if flag {
+			v |= 0x8000
+		}
+		binary.LittleEndian.PutUint16(d[j:j+2], uint16(v))
+	}
+	word(4, t.Width, false)
+	word(6, t.HTotal()-t.Width, false)
+	word(8, t.HFrontPorch, t.HSyncPositive)
+	word(10, t.HSync, false)
+	word(12, t.Height, false)
+	word(14, t.VTotal()-t.Height, false)
+	word(16, t.VFrontPorch, t.VSyncPositive)
+	word(18, t.VSync, false)
+	return d
+}
+
+// aspectRatio is the DisplayID aspect ratio code of a mode, 8 when it has
+// none.
+func aspectRatio(width, height int) byte {
+	for code, r := range [][2]int{{1, 1}, {5, 4}, {4, 3}, {15, 9}, {16, 9}, {16, 10}, {64, 27}, {256, 135}} {
+		if width*r[1] == height*r[0] {
+			return byte(code)
+		}
+	}
+	return 8
+}
+
+// setChecksum sets the last byte of block so that its bytes sum to zero.
+func setChecksum(block []byte) {
+	var sum byte
+	for _, v := range block[:len(block)-1] {
+		sum += v
+	}
+	block[len(block)-1] = -sum
+}
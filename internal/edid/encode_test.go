@@ -0,0 +1,116 @@
+package edid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func testDisplay(t *testing.T, width, height, refresh int) video.Display {
+	t.Helper()
+	timing, ok := video.LookupTiming("CVT-RBv2")
+	if !ok {
+		t.Fatal("no CVT-RBv2 timing")
+	}
+	return video.Display{Width: width, Height: height, RefreshRate: refresh, ColorDepth: video.ColorDepth10bit(), Timing: timing}
+}
+
+func TestBuildRoundTrip(t *testing.T) {
+	displays := []video.Display{
+		testDisplay(t, 2560, 1440, 60),
+		testDisplay(t, 3840, 2160, 144),
+		vics[5].Display(video.ColorDepth8bit(), video.EncodingRGB),
+	}
+	data, err := Build(displays, Options{Name: "Round trip", ProductCode: 42, Year: 2024})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	e, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(e.Warnings) > 0 {
+		t.Errorf("warnings: %q", e.Warnings)
+	}
+	if e.Name != "Round trip" || e.ProductCode != 42 || e.Year != 2024 || e.BitDepth != 10 || e.Extensions != 2 {
+		t.Errorf("EDID = %s %d %d, %d bpc, %d extensions", e.Name, e.ProductCode, e.Year, e.BitDepth, e.Extensions)
+	}
+	if len(e.Timings) != len(displays) {
+		t.Fatalf("%d timings, want %d", len(e.Timings), len(displays))
+	}
+	// The detailed timings come first, then the DisplayID ones.
+	for j, i := range []int{0, 2, 1} {
+		want := shortenPorches(displays[i].ExactTiming())
+		got := e.Timings[j].Timing
+		if got.Width != want.Width || got.Height != want.Height || got.Interlaced != want.Interlaced ||
+			got.HTotal() != want.HTotal() || got.VTotal() != want.VTotal() || math.Abs(float64(got.PixelClock-want.PixelClock)) > 5000 {
+			t.Errorf("timing %d = %+v, want %+v", j, got, want)
+		}
+	}
+	if !e.Timings[0].Preferred || e.Timings[1].Preferred || e.Timings[2].Preferred {
+		t.Errorf("only the first detailed timing should be preferred: %+v", e.Timings)
+	}
+}
+
+func TestBuildWithoutDetailedTiming(t *testing.T) {
+	tests := []struct {
+		name    string
+		display video.Display
+		// width and refresh are those of the detailed timing.
+		width, refresh int
+	}{
+		{"slower", testDisplay(t, 3840, 2160, 144), 3840, 74},
+		{"too wide", testDisplay(t, 7680, 4320, 60), 1920, 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Build([]video.Display{tt.display}, Options{})
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			e, err := Parse(data)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(e.Timings) != 2 {
+				t.Fatalf("%d timings, want 2", len(e.Timings))
+			}
+			dtd, fast := e.Timings[0], e.Timings[1]
+			if dtd.Timing.Width != tt.width || int(math.Round(dtd.Timing.RefreshRate())) != tt.refresh {
+				t.Errorf("detailed timing = %s, want %d wide at %d Hz", dtd, tt.width, tt.refresh)
+			}
+			if fast.Source != "DisplayID 2" || !fast.Preferred || fast.Timing.Width != tt.display.Width {
+				t.Errorf("DisplayID timing = %+v, want the preferred mode", fast)
+			}
+		})
+	}
+}
+
+func TestRangeLimitsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		displays   []video.Display
+		minV, maxV int
+	}{
+		{"60 Hz", []video.Display{testDisplay(t, 1920, 1080, 60)}, 60, 60},
+		{"360 Hz", []video.Display{testDisplay(t, 1920, 1080, 60), testDisplay(t, 1920, 1080, 360)}, 60, 360},
+		// Rates above 510 Hz are clamped to what the offset reaches.
+		{"600 Hz", []video.Display{testDisplay(t, 1280, 720, 600)}, 510, 510},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var timings []video.ExactTiming
+			for _, d := range tt.displays {
+				timings = append(timings, d.ExactTiming())
+			}
+			r := parseRangeLimits(rangeLimits(timings, video.Interface{}))
+			if r.MinVRate != tt.minV || r.MaxVRate != tt.maxV {
+				t.Errorf("vertical rates = %d-%d Hz, want %d-%d Hz", r.MinVRate, r.MaxVRate, tt.minV, tt.maxV)
+			}
+			if r.MaxHRate < r.MinHRate || r.MaxHRate > 510 {
+				t.Errorf("horizontal rates = %d-%d kHz", r.MinHRate, r.MaxHRate)
+			}
+		})
+	}
+}
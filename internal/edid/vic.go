@@ -0,0 +1,59 @@
+package edid
+
+import "github.com/aloababa/gvbc/internal/video"
+
+// vics are the CTA-861 formats gvbc knows, by VIC. Pixel clocks are the
+// 60 Hz ones; 59.94 Hz sinks divide them by 1.001. Interlaced formats have
+// the vertical timings of a frame, and 480i and 576i are given without
+// the pixel repetition HDMI sends them with, as DRM lists them.
+var vics = map[int]video.ExactTiming{
+	1:   vic(640, 480, 16, 96, 48, 10, 2, 33, false, 25_175),
+	2:   vic(720, 480, 16, 62, 60, 9, 6, 30, false, 27_000),
+	3:   vic(720, 480, 16, 62, 60, 9, 6, 30, false, 27_000),
+	4:   vic(1280, 720, 110, 40, 220, 5, 5, 20, true, 74_250),
+	5:   interlacedVIC(1920, 1080, 88, 44, 148, 4, 10, 31, true, 74_250),
+	6:   interlacedVIC(720, 480, 19, 62, 57, 8, 6, 31, false, 13_500),
+	7:   interlacedVIC(720, 480, 19, 62, 57, 8, 6, 31, false, 13_500),
+	16:  vic(1920, 1080, 88, 44, 148, 4, 5, 36, true, 148_500),
+	17:  vic(720, 576, 12, 64, 68, 5, 5, 39, false, 27_000),
+	18:  vic(720, 576, 12, 64, 68, 5, 5, 39, false, 27_000),
+	19:  vic(1280, 720, 440, 40, 220, 5, 5, 20, true, 74_250),
+	20:  interlacedVIC(1920, 1080, 528, 44, 148, 4, 10, 31, true, 74_250),
+	21:  interlacedVIC(720, 576, 12, 63, 69, 4, 6, 39, false, 13_500),
+	22:  interlacedVIC(720, 576, 12, 63, 69, 4, 6, 39, false, 13_500),
+	31:  vic(1920, 1080, 528, 44, 148, 4, 5, 36, true, 148_500),
+	32:  vic(1920, 1080, 638, 44, 148, 4, 5, 36, true, 74_250),
+	33:  vic(1920, 1080, 528, 44, 148, 4, 5, 36, true, 74_250),
+	34:  vic(1920, 1080, 88, 44, 148, 4, 5, 36, true, 74_250),
+	47:  vic(1280, 720, 110, 40, 220, 5, 5, 20, true, 148_500),
+	63:  vic(1920, 1080, 88, 44, 148, 4, 5, 36, true, 297_000),
+	64:  vic(1920, 1080, 528, 44, 148, 4, 5, 36, true, 297_000),
+	93:  vic(3840, 2160, 1276, 88, 296, 8, 10, 72, true, 297_000),
+	94:  vic(3840, 2160, 1056, 88, 296, 8, 10, 72, true, 297_000),
+	95:  vic(3840, 2160, 176, 88, 296, 8, 10, 72, true, 297_000),
+	96:  vic(3840, 2160, 1056, 88, 296, 8, 10, 72, true, 594_000),
+	97:  vic(3840, 2160, 176, 88, 296, 8, 10, 72, true, 594_000),
+	98:  vic(4096, 2160, 1020, 88, 296, 8, 10, 72, true, 297_000),
+	99:  vic(4096, 2160, 968, 88, 128, 8, 10, 72, true, 297_000),
+	100: vic(4096, 2160, 88, 88, 128, 8, 10, 72, true, 297_000),
+	101: vic(4096, 2160, 968, 88, 128, 8, 10, 72, true, 594_000),
+	102: vic(4096, 2160, 88, 88, 128, 8, 10, 72, true, 594_000),
+	117: vic(3840, 2160, 1056, 88, 296, 8, 10, 72, true, 1_188_000),
+	118: vic(3840, 2160, 176, 88, 296, 8, 10, 72, true, 1_188_000),
+	193: vic(5120, 2160, 164, 88, 128, 8, 10, 72, true, 1_485_000),
+	194: vic(7680, 4320, 2552, 176, 592, 16, 20, 144, true, 1_188_000),
+	196: vic(7680, 4320, 352, 176, 592, 16, 20, 144, true, 1_188_000),
+	199: vic(7680, 4320, 352, 176, 592, 16, 20, 144, true, 2_376_000),
+	218: vic(4096, 2160, 800, 88, 296, 8, 10, 72, true, 1_188_000),
+	219: vic(4096, 2160, 88, 88, 128, 8, 10, 72, true, 1_188_000),
+}
+
+func vic(width, height, hFront, hSync, hBack, vFront, vSync, vBack int, positive bool, clockKHz int) video.ExactTiming {
+	return exactTiming("CTA-861", width, height, hFront, hSync, hBack, vFront, vSync, vBack, positive, positive, clockKHz*1000)
+}
+
+func interlacedVIC(width, height, hFront, hSync, hBack, vFront, vSync, vBack int, positive bool, clockKHz int) video.ExactTiming {
+	t := vic(width, height, hFront, hSync, hBack, vFront, vSync, vBack, positive, clockKHz)
+	t.Interlaced = true
+	return t
+}
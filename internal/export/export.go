@@ -0,0 +1,144 @@
+// Package export renders a display as the configuration lines of Linux
+// compositors, X11 and the kernel command line.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+// Output is a mode to set on a connector.
+type Output struct {
+	// Connector is the DRM connector name, e.g. DP-1 or HDMI-A-1.
+	Connector string
+	Display   video.Display
+	// Custom is set when the monitor does not advertise the mode, which is
+	// then added from its exact timing where the syntax allows it.
+	Custom bool
+}
+
+// Exporter renders an output in one configuration syntax. Export fails
+// when the syntax cannot set the mode of the output.
+type Exporter struct {
+	Name    string
+	Summary string
+	Export  func(o Output) (string, error)
+}
+
+var exporters = []Exporter{
+	{Name: "sway", Summary: "sway output command", Export: sway},
+	{Name: "hyprland", Summary: "Hyprland monitor rule", Export: hyprland},
+	{Name: "kanshi", Summary: "kanshi profile", Export: kanshi},
+	{Name: "xorg", Summary: "xorg.conf Monitor section", Export: xorg},
+	{Name: "kernel", Summary: "Linux kernel video= parameter", Export: kernel},
+}
+
+func Exporters() []Exporter {
+	return exporters
+}
+
+func Lookup(name string) (Exporter, bool) {
+	for _, e := range exporters {
+		if strings.EqualFold(e.Name, name) {
+			return e, true
+		}
+	}
+	return Exporter{}, false
+}
+
+// refresh is the refresh rate to ask for: the exact one of an exact
+// timing or a fractional rate, which compositors match against the
+// advertised modes.
+func refresh(d video.Display) string {
+	if t, ok := d.Timing.(video.ExactTiming); ok {
+		return fmt.Sprintf("%.3f", t.RefreshRate())
+	}
+	if d.Fractional {
+		return fmt.Sprintf("%.3f", float64(d.RefreshRate)*1000/1001)
+	}
+	return fmt.Sprint(d.RefreshRate)
+}
+
+// modelineTimings are the fields of the modeline of d after its name.
+func modelineTimings(d video.Display) string {
+	fields := strings.Fields(d.Modeline())
+	return strings.Join(fields[2:], " ")
+}
+
+func sway(o Output) (string, error) {
+	d := o.Display
+	var b strings.Builder
+	// Mode strings cannot ask for an interlaced mode, modelines can.
+	if o.Custom || d.Interlaced {
+		fmt.Fprintf(&b, "output %s modeline %s\n", o.Connector, modelineTimings(d))
+	} else {
+		fmt.Fprintf(&b, "output %s mode %dx%d@%sHz\n", o.Connector, d.Width, d.Height, refresh(d))
+	}
+	if d.ColorDepth.BPC() >= 10 {
+		fmt.Fprintf(&b, "output %s render_bit_depth 10\n", o.Connector)
+	}
+	return b.String(), nil
+}
+
+func hyprland(o Output) (string, error) {
+	d := o.Display
+	mode := fmt.Sprintf("%dx%d@%s", d.Width, d.Height, refresh(d))
+	if o.Custom || d.Interlaced {
+		mode = "modeline " + modelineTimings(d)
+	}
+	line := fmt.Sprintf("monitor=%s,%s,auto,1", o.Connector, mode)
+	if d.ColorDepth.BPC() >= 10 {
+		line += ",bitdepth,10"
+	}
+	return line + "\n", nil
+}
+
+func kanshi(o Output) (string, error) {
+	d := o.Display
+	// kanshi has no modelines; a custom mode is generated by the
+	// compositor, with CVT timings, and is always progressive.
+	if d.Interlaced {
+		return "", errors.New("kanshi cannot set an interlaced mode")
+	}
+	custom := ""
+	if o.Custom {
+		custom = "--custom "
+	}
+	return fmt.Sprintf("profile gvbc {\n\toutput %s mode %s%dx%d@%sHz\n}\n", o.Connector, custom, d.Width, d.Height, refresh(d)), nil
+}
+
+func xorg(o Output) (string, error) {
+	d := o.Display
+	var b strings.Builder
+	b.WriteString("Section \"Monitor\"\n")
+	fmt.Fprintf(&b, "\tIdentifier \"%s\"\n", o.Connector)
+	// X names interlaced modes with an i, e.g. 1920x1080i.
+	name := fmt.Sprintf("%dx%d%s", d.Width, d.Height, video.ScanSuffix(d.Interlaced))
+	if o.Custom {
+		name = d.ModelineName()
+		fmt.Fprintf(&b, "\t%s\n", d.Modeline())
+	}
+	fmt.Fprintf(&b, "\tOption \"PreferredMode\" \"%s\"\n", name)
+	b.WriteString("EndSection\n")
+	return b.String(), nil
+}
+
+// kernel sets the mode at boot. An advertised mode is picked by size and
+// refresh rate. A custom mode takes M, for the kernel to compute it with
+// CVT instead of GTF, and R for reduced blanking unless the timing has
+// more blanking than that. Interlaced modes take an i after the refresh
+// rate.
+func kernel(o Output) (string, error) {
+	d := o.Display
+	cvt := ""
+	if o.Custom {
+		cvt = "MR"
+		if t, ok := d.Timing.(video.ExactTiming); ok && t.HTotal()-t.Width > 160 {
+			cvt = "M"
+		}
+	}
+	return fmt.Sprintf("video=%s:%dx%d%s@%d%s\n", o.Connector, d.Width, d.Height, cvt, d.RefreshRate, video.ScanSuffix(d.Interlaced)), nil
+}
@@ -0,0 +1,51 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func testDisplay(t *testing.T, timing string, refresh int, interlaced bool) video.Display {
+	t.Helper()
+	tm, ok := video.LookupTiming(timing)
+	if !ok {
+		t.Fatalf("no %s timing", timing)
+	}
+	return video.Display{Width: 1920, Height: 1080, RefreshRate: refresh, ColorDepth: video.ColorDepth8bit(), Timing: tm, Interlaced: interlaced}
+}
+
+func TestKernel(t *testing.T) {
+	gtf := video.ExactTiming{Width: 1920, Height: 1080, HFrontPorch: 128, HSync: 200, HBackPorch: 328,
+		VFrontPorch: 3, VSync: 5, VBackPorch: 32, PixelClock: 173_000_000}
+	tests := []struct {
+		name string
+		o    Output
+		want string
+	}{
+		{"advertised", Output{Connector: "DP-1", Display: testDisplay(t, "CVT-RBv2", 60, false)}, "video=DP-1:1920x1080@60\n"},
+		{"custom", Output{Connector: "DP-1", Display: testDisplay(t, "CVT-RBv2", 75, false), Custom: true}, "video=DP-1:1920x1080MR@75\n"},
+		{"custom blanking", Output{Connector: "DP-1", Display: gtf.Display(video.ColorDepth8bit(), video.EncodingRGB), Custom: true}, "video=DP-1:1920x1080M@60\n"},
+		{"interlaced", Output{Connector: "HDMI-A-1", Display: testDisplay(t, "CVT-RB", 50, true)}, "video=HDMI-A-1:1920x1080@50i\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := kernel(tt.o)
+			if err != nil || got != tt.want {
+				t.Errorf("kernel = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestKanshi(t *testing.T) {
+	o := Output{Connector: "DP-1", Display: testDisplay(t, "CVT-RBv2", 75, false), Custom: true}
+	want := "profile gvbc {\n\toutput DP-1 mode --custom 1920x1080@75Hz\n}\n"
+	if got, err := kanshi(o); err != nil || got != want {
+		t.Errorf("kanshi = %q, %v, want %q", got, err, want)
+	}
+	o.Display = testDisplay(t, "CVT-RB", 50, true)
+	if got, err := kanshi(o); err == nil {
+		t.Errorf("kanshi of an interlaced mode = %q, want an error", got)
+	}
+}
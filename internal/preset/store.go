@@ -0,0 +1,213 @@
+package preset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/aloababa/gvbc/internal/video"
+	"gopkg.in/yaml.v3"
+)
+
+const DefaultCategory = "User"
+
+type file struct {
+	Presets []video.PresetSpec `yaml:"presets"`
+}
+
+// Store holds the user presets persisted under the XDG config directory.
+// Every mutation is written back to disk before it returns, and only takes
+// effect when the write succeeds.
+type Store struct {
+	path    string
+	presets []video.Preset
+	// stale are the stored presets that no longer resolve, such as one
+	// whose timing a spec removed. They are kept in the file as they are.
+	stale    []video.PresetSpec
+	warnings []string
+}
+
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gvbc", "presets.yaml"), nil
+}
+
+// Open loads the presets stored at path. A missing file yields an empty
+// store that is created on the first save. Presets that do not resolve are
+// skipped with a warning.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var f file
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, ps := range f.Presets {
+		p, err := ps.Preset()
+		if err != nil {
+			s.stale = append(s.stale, ps)
+			s.warnings = append(s.warnings, fmt.Sprintf("%s: preset %q skipped: %v", path, ps.Name, err))
+			continue
+		}
+		if p.Category == "" {
+			p.Category = DefaultCategory
+		}
+		s.presets = append(s.presets, p)
+	}
+	return s, nil
+}
+
+func (s *Store) Path() string {
+	return s.path
+}
+
+func (s *Store) Presets() []video.Preset {
+	return slices.Clone(s.presets)
+}
+
+// Warnings lists the presets Open skipped.
+func (s *Store) Warnings() []string {
+	return slices.Clone(s.warnings)
+}
+
+func (s *Store) Add(p video.Preset) error {
+	if p.Category == "" {
+		p.Category = DefaultCategory
+	}
+	if err := s.checkName(p.Name, -1); err != nil {
+		return err
+	}
+	return s.save(append(slices.Clone(s.presets), p))
+}
+
+func (s *Store) Rename(i int, name string) error {
+	if err := s.checkIndex(i); err != nil {
+		return err
+	}
+	if err := s.checkName(name, i); err != nil {
+		return err
+	}
+	presets := slices.Clone(s.presets)
+	presets[i].Name = name
+	return s.save(presets)
+}
+
+func (s *Store) SetCategory(i int, category string) error {
+	if err := s.checkIndex(i); err != nil {
+		return err
+	}
+	if category == "" {
+		category = DefaultCategory
+	}
+	presets := slices.Clone(s.presets)
+	presets[i].Category = category
+	return s.save(presets)
+}
+
+// Relabel renames preset i and moves it to category in a single save, so
+// that neither change is kept without the other.
+func (s *Store) Relabel(i int, name, category string) error {
+	if err := s.checkIndex(i); err != nil {
+		return err
+	}
+	if err := s.checkName(name, i); err != nil {
+		return err
+	}
+	if category == "" {
+		category = DefaultCategory
+	}
+	presets := slices.Clone(s.presets)
+	presets[i].Name, presets[i].Category = name, category
+	return s.save(presets)
+}
+
+func (s *Store) Delete(i int) error {
+	if err := s.checkIndex(i); err != nil {
+		return err
+	}
+	return s.save(slices.Delete(slices.Clone(s.presets), i, i+1))
+}
+
+// Move swaps preset i with preset j.
+func (s *Store) Move(i, j int) error {
+	if err := s.checkIndex(i); err != nil {
+		return err
+	}
+	if err := s.checkIndex(j); err != nil {
+		return err
+	}
+	presets := slices.Clone(s.presets)
+	presets[i], presets[j] = presets[j], presets[i]
+	return s.save(presets)
+}
+
+func (s *Store) checkIndex(i int) error {
+	if i < 0 || i >= len(s.presets) {
+		return fmt.Errorf("preset index %d out of range", i)
+	}
+	return nil
+}
+
+func (s *Store) checkName(name string, self int) error {
+	if name == "" {
+		return fmt.Errorf("preset name is required")
+	}
+	for i, p := range s.presets {
+		if i != self && p.Name == name {
+			return fmt.Errorf("preset %q already exists", name)
+		}
+	}
+	if slices.ContainsFunc(s.stale, func(ps video.PresetSpec) bool { return ps.Name == name }) {
+		return fmt.Errorf("preset %q already exists", name)
+	}
+	return nil
+}
+
+// save writes presets, followed by the stale ones, and makes them the
+// presets of s once the file is written.
+func (s *Store) save(presets []video.Preset) error {
+	f := file{Presets: make([]video.PresetSpec, 0, len(presets)+len(s.stale))}
+	for _, p := range presets {
+		f.Presets = append(f.Presets, video.NewPresetSpec(p))
+	}
+	f.Presets = append(f.Presets, s.stale...)
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".presets-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	s.presets = presets
+	return nil
+}
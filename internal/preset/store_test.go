@@ -0,0 +1,133 @@
+package preset
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+func testPreset(t *testing.T, name string) video.Preset {
+	t.Helper()
+	timing, ok := video.LookupTiming("CVT-RBv2")
+	if !ok {
+		t.Fatal("no CVT-RBv2 timing")
+	}
+	return video.Preset{Name: name, Display: video.Display{
+		Width:       3840,
+		Height:      2160,
+		RefreshRate: 144,
+		ColorDepth:  video.ColorDepth10bit(),
+		Timing:      timing,
+	}}
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gvbc", "presets.yaml")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if err := s.Add(testPreset(t, name)); err != nil {
+			t.Fatalf("Add(%s): %v", name, err)
+		}
+	}
+	if err := s.Add(testPreset(t, "a")); err == nil {
+		t.Fatal("Add of a duplicate name succeeded")
+	}
+	if err := s.Rename(0, "c"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if err := s.SetCategory(0, "Desk"); err != nil {
+		t.Fatalf("SetCategory: %v", err)
+	}
+	if err := s.Relabel(1, "c", "Couch"); err == nil {
+		t.Fatal("Relabel to a duplicate name succeeded")
+	}
+	if err := s.Relabel(1, "d", "Couch"); err != nil {
+		t.Fatalf("Relabel: %v", err)
+	}
+	if p := s.Presets()[1]; p.Name != "d" || p.Category != "Couch" {
+		t.Fatalf("relabeled preset = %s in %s, want d in Couch", p.Name, p.Category)
+	}
+	if err := s.Move(0, 1); err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	if err := s.Delete(0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	got := reopened.Presets()
+	if len(got) != 1 || got[0].Name != "c" || got[0].Category != "Desk" || got[0].Display.RefreshRate != 144 {
+		t.Fatalf("reopened presets = %+v, want c in Desk", got)
+	}
+}
+
+func TestStoreFailedSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "presets.yaml")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	// A directory in place of the presets file makes every save fail.
+	if err := os.Mkdir(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(testPreset(t, "a")); err == nil {
+		t.Fatal("Add succeeded without a writable file")
+	}
+	if n := len(s.Presets()); n != 0 {
+		t.Fatalf("%d presets after a failed save, want 0", n)
+	}
+}
+
+func TestStoreSkipsStalePresets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "presets.yaml")
+	data := `presets:
+  - name: good
+    width: 1920
+    height: 1080
+    refresh: 60
+    bpc: 8
+    timing: CVT-RB
+  - name: stale
+    width: 1920
+    height: 1080
+    refresh: 60
+    bpc: 8
+    timing: Removed
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if got := s.Presets(); len(got) != 1 || got[0].Name != "good" {
+		t.Fatalf("presets = %+v, want good", got)
+	}
+	if w := s.Warnings(); len(w) != 1 || !strings.Contains(w[0], `"stale"`) {
+		t.Fatalf("warnings = %q, want one for stale", w)
+	}
+	if err := s.Add(testPreset(t, "stale")); err == nil {
+		t.Fatal("Add reused the name of a stale preset")
+	}
+	if err := s.Add(testPreset(t, "new")); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(written), "timing: Removed") {
+		t.Fatalf("the stale preset was dropped from the file:\n%s", written)
+	}
+}
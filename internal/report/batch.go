@@ -0,0 +1,161 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Entry is one evaluated record of a batch. Exactly one of Error and Result
+// is set.
+type Entry struct {
+	Line   int     `json:"line" yaml:"line"`
+	Name   string  `json:"name,omitempty" yaml:"name,omitempty"`
+	Error  string  `json:"error,omitempty" yaml:"error,omitempty"`
+	Result *Result `json:"result,omitempty" yaml:"result,omitempty"`
+}
+
+type column struct {
+	family  string
+	version string
+}
+
+func (c column) String() string {
+	return c.family + " " + c.version
+}
+
+// WriteBatch renders entries as a list in JSON and YAML, and as a
+// compatibility matrix with one column per interface version otherwise.
+func WriteBatch(w io.Writer, f Format, entries []Entry) error {
+	switch f {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(entries); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatText, FormatCSV, FormatMarkdown:
+		return writeMatrix(w, f, entries)
+	}
+	return fmt.Errorf("unknown format %q", f)
+}
+
+func matrixColumns(entries []Entry) []column {
+	var columns []column
+	seen := make(map[column]bool)
+	for _, e := range entries {
+		if e.Result == nil {
+			continue
+		}
+		for _, row := range e.Result.Compatibility {
+			c := column{row.Family, row.Version}
+			if !seen[c] {
+				seen[c] = true
+				columns = append(columns, c)
+			}
+		}
+	}
+	return columns
+}
+
+func matrixCells(r *Result, columns []column, cell func(Row) string) []string {
+	cells := make([]string, len(columns))
+	for i, c := range columns {
+		for _, row := range r.Compatibility {
+			if row.Family == c.family && row.Version == c.version {
+				cells[i] = cell(row)
+				break
+			}
+		}
+	}
+	return cells
+}
+
+func writeMatrix(w io.Writer, f Format, entries []Entry) error {
+	columns := matrixColumns(entries)
+	header := []string{"line", "name", "display", "bandwidth", "fits"}
+	for _, c := range columns {
+		header = append(header, c.String())
+	}
+	header = append(header, "error")
+	if f == FormatCSV {
+		// The scan has a column of its own, as in a single result.
+		header = slices.Insert(header, 3, "interlaced")
+	}
+
+	records := make([][]string, len(entries))
+	for i, e := range entries {
+		record := []string{strconv.Itoa(e.Line), e.Name}
+		if e.Result == nil {
+			record = append(record, "", "", "")
+			if f == FormatCSV {
+				record = append(record, "")
+			}
+			record = append(record, make([]string, len(columns))...)
+			records[i] = append(record, e.Error)
+			continue
+		}
+		var cells []string
+		if f == FormatCSV {
+			record = append(record, e.Result.Display.String(), strconv.FormatBool(e.Result.Display.Interlaced),
+				strconv.FormatUint(e.Result.Bandwidth, 10), strconv.FormatBool(e.Result.Fits))
+			cells = matrixCells(e.Result, columns, func(row Row) string {
+				status, _ := row.Status.MarshalText()
+				return string(status)
+			})
+		} else {
+			record = append(record, e.Result.Display.String(), speed(e.Result.Bandwidth), yesNo(e.Result.Fits))
+			cells = matrixCells(e.Result, columns, func(row Row) string {
+				return row.Status.String() + " " + row.Mode
+			})
+		}
+		record = append(record, cells...)
+		records[i] = append(record, "")
+	}
+
+	switch f {
+	case FormatCSV:
+		header[4] = "bandwidth_bps"
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(records); err != nil {
+			return err
+		}
+		return cw.Error()
+	case FormatMarkdown:
+		fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | "))
+		fmt.Fprintf(w, "|%s\n", strings.Repeat("---|", len(header)))
+		for _, record := range records {
+			for i := range record {
+				record[i] = markdownEscape(record[i])
+			}
+			fmt.Fprintf(w, "| %s |\n", strings.Join(record, " | "))
+		}
+		return nil
+	}
+	for i := range header {
+		if i < 5 || i == len(header)-1 {
+			header[i] = strings.ToUpper(header[i])
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, record := range records {
+		fmt.Fprintln(tw, strings.Join(record, "\t"))
+	}
+	return tw.Flush()
+}
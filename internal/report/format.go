@@ -0,0 +1,189 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aloababa/gvbc/internal/video"
+	"github.com/hekmon/cunits/v3"
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatCSV      Format = "csv"
+	FormatMarkdown Format = "markdown"
+)
+
+func Formats() []Format {
+	return []Format{FormatText, FormatJSON, FormatYAML, FormatCSV, FormatMarkdown}
+}
+
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats() {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+	if strings.EqualFold(s, "md") {
+		return FormatMarkdown, nil
+	}
+	return "", fmt.Errorf("unknown format %q", s)
+}
+
+func Write(w io.Writer, f Format, r Result) error {
+	switch f {
+	case FormatText:
+		return writeText(w, r)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(csvRecords(r)); err != nil {
+			return err
+		}
+		return cw.Error()
+	case FormatMarkdown:
+		return writeMarkdown(w, r)
+	}
+	return fmt.Errorf("unknown format %q", f)
+}
+
+func speed(bits uint64) string {
+	return cunits.Speed{Bits: cunits.Bits(bits)}.String()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
+func (d Display) String() string {
+	scan, refresh := video.ScanSuffix(d.Interlaced), video.FormatRefresh(d.Refresh, d.Fractional)
+	if e, err := video.ParseEncoding(d.Encoding); err == nil && e != video.EncodingRGB {
+		return fmt.Sprintf("%dx%d%s@%sHz, %d bpc %s (%g bit/px), %s", d.Width, d.Height, scan, refresh, d.BPC, e, d.BitsPerPixel, d.Timing)
+	}
+	return fmt.Sprintf("%dx%d%s@%sHz, %d bpc (%g bit/px), %s", d.Width, d.Height, scan, refresh, d.BPC, d.BitsPerPixel, d.Timing)
+}
+
+func writeText(w io.Writer, r Result) error {
+	fmt.Fprintf(w, "Display:   %s\n", r.Display)
+	fmt.Fprintf(w, "Timing:    %dx%d total, %.2f MHz pixel clock\n", r.Timing.HTotal, r.Timing.VTotal, float64(r.Timing.PixelRate)/1e6)
+	fmt.Fprintf(w, "Bandwidth: %s\n", speed(r.Bandwidth))
+	fmt.Fprintf(w, "DSC:       %s\n", speed(r.DSC))
+	names, rows := r.families()
+	for _, name := range names {
+		fmt.Fprintf(w, "\n%s\n", name)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tMODE\tMAX\tEFFECTIVE\tUSAGE\tHDR\tSTATUS")
+		for _, row := range rows[name] {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.1f%%\t%s\t%s\n", row.Version, row.Mode,
+				speed(row.MaxBandwidth), speed(row.EffectiveBandwidth), row.Usage, yesNo(row.HDR), row.Status)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return writeSuggestions(w, r.Suggestions, "\n%s\n", "  %d. %s\n")
+}
+
+var csvHeader = []string{
+	"width", "height", "refresh_hz", "bpc", "encoding", "timing", "interlaced", "h_total", "v_total", "pixel_rate_hz",
+	"bandwidth_bps", "dsc_bps", "family", "version", "mode", "max_bandwidth_bps",
+	"effective_bandwidth_bps", "usage_percent", "hdr", "status",
+}
+
+func csvRecords(r Result) [][]string {
+	records := make([][]string, len(r.Compatibility))
+	for i, row := range r.Compatibility {
+		status, _ := row.Status.MarshalText()
+		records[i] = []string{
+			strconv.Itoa(r.Display.Width),
+			strconv.Itoa(r.Display.Height),
+			video.FormatRefresh(r.Display.Refresh, r.Display.Fractional),
+			strconv.Itoa(r.Display.BPC),
+			r.Display.Encoding,
+			r.Display.Timing,
+			strconv.FormatBool(r.Display.Interlaced),
+			strconv.Itoa(r.Timing.HTotal),
+			strconv.Itoa(r.Timing.VTotal),
+			strconv.Itoa(r.Timing.PixelRate),
+			strconv.FormatUint(r.Bandwidth, 10),
+			strconv.FormatUint(r.DSC, 10),
+			row.Family,
+			row.Version,
+			row.Mode,
+			strconv.FormatUint(row.MaxBandwidth, 10),
+			strconv.FormatUint(row.EffectiveBandwidth, 10),
+			strconv.FormatFloat(row.Usage, 'f', 2, 64),
+			strconv.FormatBool(row.HDR),
+			string(status),
+		}
+	}
+	return records
+}
+
+func writeMarkdown(w io.Writer, r Result) error {
+	fmt.Fprintf(w, "## %s\n\n", r.Display)
+	fmt.Fprintf(w, "- **Timing:** %dx%d total, %.2f MHz pixel clock\n", r.Timing.HTotal, r.Timing.VTotal, float64(r.Timing.PixelRate)/1e6)
+	fmt.Fprintf(w, "- **Bandwidth:** %s\n", speed(r.Bandwidth))
+	fmt.Fprintf(w, "- **DSC:** %s\n", speed(r.DSC))
+	names, rows := r.families()
+	for _, name := range names {
+		fmt.Fprintf(w, "\n### %s\n\n", name)
+		fmt.Fprintln(w, "| Version | Mode | Max | Effective | Usage | HDR | Status |")
+		fmt.Fprintln(w, "|---|---|---:|---:|---:|---|---|")
+		for _, row := range rows[name] {
+			fmt.Fprintf(w, "| %s | %s | %s | %s | %.1f%% | %s | %s |\n", markdownEscape(row.Version), markdownEscape(row.Mode),
+				speed(row.MaxBandwidth), speed(row.EffectiveBandwidth), row.Usage, yesNo(row.HDR), row.Status)
+		}
+	}
+	return writeSuggestions(w, r.Suggestions, "\n### %s\n\n", "%d. %s\n")
+}
+
+// writeSuggestions lists suggestions grouped by the interface they are for.
+func writeSuggestions(w io.Writer, suggestions []Suggestion, titleFormat, itemFormat string) error {
+	var target string
+	n := 0
+	for _, s := range suggestions {
+		if s.For != target {
+			target = s.For
+			n = 0
+			if _, err := fmt.Fprintf(w, titleFormat, "Suggestions for "+target); err != nil {
+				return err
+			}
+		}
+		n++
+		if _, err := fmt.Fprintf(w, itemFormat, n, strings.Join(s.Changes, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
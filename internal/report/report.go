@@ -0,0 +1,144 @@
+package report
+
+import (
+	_ "embed"
+
+	"github.com/aloababa/gvbc/internal/video"
+)
+
+// Result is the structured form of a display evaluation. Every output format
+// is rendered from it.
+type Result struct {
+	Display       Display      `json:"display" yaml:"display"`
+	Timing        Timing       `json:"timing" yaml:"timing"`
+	Bandwidth     uint64       `json:"bandwidth_bps" yaml:"bandwidth_bps"`
+	DSC           uint64       `json:"dsc_bps" yaml:"dsc_bps"`
+	Fits          bool         `json:"fits" yaml:"fits"`
+	Compatibility []Row        `json:"compatibility" yaml:"compatibility"`
+	Suggestions   []Suggestion `json:"suggestions,omitempty" yaml:"suggestions,omitempty"`
+}
+
+type Display struct {
+	Width        int     `json:"width" yaml:"width"`
+	Height       int     `json:"height" yaml:"height"`
+	Refresh      int     `json:"refresh_hz" yaml:"refresh_hz"`
+	BPC          int     `json:"bpc" yaml:"bpc"`
+	BitsPerPixel float64 `json:"bits_per_pixel" yaml:"bits_per_pixel"`
+	Encoding     string  `json:"encoding" yaml:"encoding"`
+	Timing       string  `json:"timing" yaml:"timing"`
+	// Interlaced displays refresh at their field rate.
+	Interlaced bool `json:"interlaced,omitempty" yaml:"interlaced,omitempty"`
+	// Fractional displays refresh at 1000/1001 of Refresh.
+	Fractional bool `json:"fractional,omitempty" yaml:"fractional,omitempty"`
+}
+
+type Timing struct {
+	HTotal    int `json:"h_total" yaml:"h_total"`
+	VTotal    int `json:"v_total" yaml:"v_total"`
+	FrameSize int `json:"frame_size" yaml:"frame_size"`
+	PixelRate int `json:"pixel_rate_hz" yaml:"pixel_rate_hz"`
+}
+
+type Row struct {
+	Family             string       `json:"family" yaml:"family"`
+	Version            string       `json:"version" yaml:"version"`
+	Mode               string       `json:"mode" yaml:"mode"`
+	MaxBandwidth       uint64       `json:"max_bandwidth_bps" yaml:"max_bandwidth_bps"`
+	EffectiveBandwidth uint64       `json:"effective_bandwidth_bps" yaml:"effective_bandwidth_bps"`
+	Usage              float64      `json:"usage_percent" yaml:"usage_percent"`
+	HDR                bool         `json:"hdr" yaml:"hdr"`
+	Status             video.Status `json:"status" yaml:"status"`
+}
+
+// Suggestion is a remediation for an interface the display does not fit.
+type Suggestion struct {
+	Family          string   `json:"family" yaml:"family"`
+	Version         string   `json:"version" yaml:"version"`
+	Mode            string   `json:"mode" yaml:"mode"`
+	Changes         []string `json:"changes" yaml:"changes"`
+	Cost            float64  `json:"cost" yaml:"cost"`
+	DSCBitsPerPixel float64  `json:"dsc_bits_per_pixel,omitempty" yaml:"dsc_bits_per_pixel,omitempty"`
+	// For is the interface the display did not fit, which differs from
+	// Family and Version when the suggestion is to move to another version.
+	For     string  `json:"for" yaml:"for"`
+	Display Display `json:"display" yaml:"display"`
+}
+
+func NewSuggestion(target video.Interface, s video.Suggestion) Suggestion {
+	return Suggestion{
+		Family:          s.Interface.Family,
+		Version:         s.Interface.Version,
+		Mode:            s.Mode.GetName(),
+		Changes:         s.Changes,
+		Cost:            s.Cost,
+		DSCBitsPerPixel: s.DSCBitsPerPixel,
+		For:             target.String(),
+		Display:         NewDisplay(s.Display),
+	}
+}
+
+func NewDisplay(d video.Display) Display {
+	return Display{
+		Width:        d.Width,
+		Height:       d.Height,
+		Refresh:      d.RefreshRate,
+		BPC:          d.ColorDepth.BPC(),
+		BitsPerPixel: d.BitsPerPixel(),
+		Encoding:     d.Encoding.ShortName(),
+		Timing:       d.Timing.String(),
+		Interlaced:   d.Interlaced,
+		Fractional:   d.Fractional,
+	}
+}
+
+func New(d video.Display, compatibility []video.Compatibility, fits bool) Result {
+	hTotal, vTotal := d.Totals()
+	r := Result{
+		Display: NewDisplay(d),
+		Timing: Timing{
+			HTotal:    hTotal,
+			VTotal:    vTotal,
+			FrameSize: d.EffectiveFrameSize(),
+			PixelRate: d.EffectivePixelRate(),
+		},
+		Bandwidth:     uint64(d.Bandwidth().Bits),
+		DSC:           uint64(d.DSC().Bits),
+		Fits:          fits,
+		Compatibility: make([]Row, len(compatibility)),
+	}
+	for i, c := range compatibility {
+		r.Compatibility[i] = Row{
+			Family:             c.Interface.Family,
+			Version:            c.Interface.Version,
+			Mode:               c.Mode.GetName(),
+			MaxBandwidth:       uint64(c.Mode.GetBandwidth().Bits),
+			EffectiveBandwidth: uint64(c.Mode.EffectiveBandwidth().Bits),
+			Usage:              c.Usage,
+			HDR:                c.HDR,
+			Status:             c.Status,
+		}
+	}
+	return r
+}
+
+// families returns the rows grouped by interface family, in order of first
+// appearance.
+func (r Result) families() ([]string, map[string][]Row) {
+	var names []string
+	rows := make(map[string][]Row)
+	for _, row := range r.Compatibility {
+		if _, ok := rows[row.Family]; !ok {
+			names = append(names, row.Family)
+		}
+		rows[row.Family] = append(rows[row.Family], row)
+	}
+	return names, rows
+}
+
+//go:embed schema.json
+var schema []byte
+
+// Schema returns the JSON schema of Result.
+func Schema() []byte {
+	return schema
+}
@@ -0,0 +1,113 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Table is a titled grid of preformatted cells, used by commands whose
+// output does not fit the compatibility Result.
+type Table struct {
+	Title  string
+	Header []string
+	Rows   [][]string
+}
+
+// WriteTables renders v in JSON and YAML, and tables in the other formats.
+// CSV output is a single grid: when several tables share a header, a leading
+// section column holds their titles.
+func WriteTables(w io.Writer, f Format, v any, tables ...Table) error {
+	switch f {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatCSV:
+		return writeCSVTables(w, tables)
+	case FormatText:
+		for i, t := range tables {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if t.Title != "" {
+				fmt.Fprintln(w, t.Title)
+			}
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, strings.ToUpper(strings.Join(t.Header, "\t")))
+			for _, row := range t.Rows {
+				fmt.Fprintln(tw, strings.Join(row, "\t"))
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatMarkdown:
+		for i, t := range tables {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if t.Title != "" {
+				fmt.Fprintf(w, "### %s\n\n", t.Title)
+			}
+			fmt.Fprintf(w, "| %s |\n", strings.Join(t.Header, " | "))
+			fmt.Fprintf(w, "|%s\n", strings.Repeat("---|", len(t.Header)))
+			for _, row := range t.Rows {
+				cells := make([]string, len(row))
+				for j, c := range row {
+					cells[j] = markdownEscape(c)
+				}
+				fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q", f)
+}
+
+func writeCSVTables(w io.Writer, tables []Table) error {
+	if len(tables) == 0 {
+		return nil
+	}
+	cw := csv.NewWriter(w)
+	sections := len(tables) > 1
+	for _, t := range tables[1:] {
+		if !slices.Equal(t.Header, tables[0].Header) {
+			sections = false
+		}
+	}
+	for i, t := range tables {
+		switch {
+		case sections && i == 0:
+			cw.Write(append([]string{"section"}, t.Header...))
+		case !sections && i > 0:
+			cw.Flush()
+			fmt.Fprintln(w)
+			cw.Write(t.Header)
+		case !sections:
+			cw.Write(t.Header)
+		}
+		for _, row := range t.Rows {
+			if sections {
+				row = append([]string{t.Title}, row...)
+			}
+			cw.Write(row)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
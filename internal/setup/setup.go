@@ -0,0 +1,177 @@
+package setup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/batch"
+	"github.com/aloababa/gvbc/internal/video"
+	"gopkg.in/yaml.v3"
+)
+
+// File is a multi-display setup: the displays connected to the ports of a
+// single source.
+type File struct {
+	Source   SourceRef     `yaml:"source"`
+	Displays []DisplaySpec `yaml:"displays"`
+}
+
+// SourceRef is either the name of a catalog source or a full source spec.
+type SourceRef struct {
+	Name string
+	Spec video.SourceSpec
+}
+
+func (r *SourceRef) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&r.Name)
+	}
+	// Node.Decode does not reject unknown fields: decode a copy strictly.
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	return dec.Decode(&r.Spec)
+}
+
+func (r SourceRef) Source() (video.Source, error) {
+	if r.Name == "" {
+		return r.Spec.Source()
+	}
+	s, ok := video.LookupSource(r.Name)
+	if !ok {
+		return video.Source{}, fmt.Errorf("unknown source %q", r.Name)
+	}
+	return s, nil
+}
+
+// DisplaySpec is a display on a port. Color depth and timing default to
+// the batch defaults. A tiled display, e.g. tiles: 2x1, is driven as one
+// stream per tile, spread over its ports, or over MST on a single port.
+type DisplaySpec struct {
+	Port             string   `yaml:"port"`
+	Ports            []string `yaml:"ports,omitempty"`
+	Tiles            string   `yaml:"tiles,omitempty"`
+	video.PresetSpec `yaml:",inline"`
+}
+
+// Setup is a resolved setup file.
+type Setup struct {
+	Source  video.Source
+	Outputs []video.Output
+}
+
+func Load(path string) (Setup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Setup{}, err
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return Setup{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Parse decodes and resolves a YAML or JSON setup file against the active
+// spec.
+func Parse(data []byte) (Setup, error) {
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		return Setup{}, fmt.Errorf("setup: %w", err)
+	}
+	var errs []error
+	src, err := f.Source.Source()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("setup: source: %w", err))
+	}
+	if len(f.Displays) == 0 {
+		errs = append(errs, fmt.Errorf("setup: at least one display is required"))
+	}
+	var outputs []video.Output
+	for i, ds := range f.Displays {
+		ports := ds.Ports
+		if ds.Port != "" {
+			ports = append([]string{ds.Port}, ports...)
+		}
+		if len(ports) == 0 {
+			errs = append(errs, fmt.Errorf("setup: displays[%d]: port is required", i))
+			continue
+		}
+		if ds.BPC == 0 {
+			ds.BPC = batch.DefaultBPC
+		}
+		if ds.Timing == "" {
+			ds.Timing = batch.DefaultTiming
+		}
+		p, err := ds.Preset()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("setup: displays[%d]: %w", i, err))
+			continue
+		}
+		name := ds.Name
+		if name == "" {
+			name = ports[0]
+		}
+		if ds.Tiles == "" {
+			if len(ports) > 1 {
+				errs = append(errs, fmt.Errorf("setup: displays[%d]: several ports need tiles", i))
+				continue
+			}
+			outputs = append(outputs, video.Output{Name: name, Port: ports[0], Display: p.Display})
+			continue
+		}
+		tiled, err := tiledOutputs(name, ports, ds.Tiles, p.Display)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("setup: displays[%d]: %w", i, err))
+			continue
+		}
+		outputs = append(outputs, tiled...)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return Setup{}, err
+	}
+	return Setup{Source: src, Outputs: outputs}, nil
+}
+
+// tiledOutputs splits d into tiles per layout and spreads them over ports,
+// one output per port named after the tiles it carries.
+func tiledOutputs(name string, ports []string, layout string, d video.Display) ([]video.Output, error) {
+	h, v, err := video.ParseTileLayout(layout)
+	if err != nil {
+		return nil, err
+	}
+	t, err := video.NewTiledDisplay(d, h, v)
+	if err != nil {
+		return nil, err
+	}
+	if len(ports) > t.Tiles() {
+		return nil, fmt.Errorf("%d ports for %d tiles", len(ports), t.Tiles())
+	}
+	var out []video.Output
+	for n, tiles := range t.Spread(len(ports)) {
+		numbers := make([]string, len(tiles))
+		for j, tile := range tiles {
+			numbers[j] = strconv.Itoa(tile + 1)
+		}
+		noun := "tile"
+		if len(tiles) > 1 {
+			noun = "tiles"
+		}
+		out = append(out, video.Output{
+			Name:    fmt.Sprintf("%s (%s %s)", name, noun, strings.Join(numbers, ", ")),
+			Port:    ports[n],
+			Display: t.Tile,
+			Streams: len(tiles),
+		})
+	}
+	return out, nil
+}
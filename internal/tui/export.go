@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/export"
+)
+
+func (m *Model) toogleExport() {
+	m.showExport = !m.showExport
+}
+
+// cycleExport selects the next or previous configuration format.
+func (m *Model) cycleExport(step int) {
+	n := len(export.Exporters())
+	m.exportIndex = (m.exportIndex + step + n) % n
+}
+
+// exportOutput is the output of the current display, on the first input of
+// the sink, with a custom mode when the sink does not advertise it.
+func (m Model) exportOutput() export.Output {
+	o := export.Output{Connector: "DP-1", Display: m.d}
+	if m.sink != nil {
+		if len(m.sink.Inputs) > 0 && m.sink.Inputs[0].Interface.Family == "HDMI" {
+			o.Connector = "HDMI-A-1"
+		}
+		o.Custom = !m.sink.Advertises(m.d)
+	}
+	return o
+}
+
+func (m Model) renderExportContent() string {
+	e := export.Exporters()[m.exportIndex]
+	o := m.exportOutput()
+	var b strings.Builder
+	b.WriteString(line.Render(e.Summary))
+	b.WriteString("\n")
+	mode := "advertised mode"
+	if m.sink == nil {
+		mode = "select a sink to detect custom modes"
+	} else if o.Custom {
+		mode = "custom mode"
+	}
+	b.WriteString(subtle.Render(fmt.Sprintf("%d/%d · %s · %s", m.exportIndex+1, len(export.Exporters()), o.Connector, mode)))
+	b.WriteString("\n\n")
+	config, err := e.Export(o)
+	if err != nil {
+		b.WriteString(normal.Render(err.Error()))
+		return b.String()
+	}
+	b.WriteString(highlight.Render(strings.TrimSuffix(config, "\n")))
+	return b.String()
+}
@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/preset"
+	"github.com/aloababa/gvbc/internal/video"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type presetEdit int
+
+const (
+	presetEditNone presetEdit = iota
+	presetEditSave
+	presetEditRename
+)
+
+// buildPresetItems lists built-in and user presets grouped by category, in
+// order of first appearance.
+func (m Model) buildPresetItems() []list.Item {
+	var all []presetListItem
+	for _, p := range video.Presets() {
+		all = append(all, presetListItem{preset: p, user: -1})
+	}
+	if m.presetStore != nil {
+		for i, p := range m.presetStore.Presets() {
+			all = append(all, presetListItem{preset: p, user: i})
+		}
+	}
+	var categories []string
+	for _, item := range all {
+		if !slices.Contains(categories, item.preset.Category) {
+			categories = append(categories, item.preset.Category)
+		}
+	}
+	items := make([]list.Item, 0, len(all))
+	for _, c := range categories {
+		for _, item := range all {
+			if item.preset.Category == c {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+func (m *Model) refreshPresetItems(selectUser int) {
+	m.presetItems = m.buildPresetItems()
+	m.presetList.SetItems(m.presetItems)
+	for i, item := range m.presetItems {
+		if selectUser >= 0 && item.(presetListItem).user == selectUser {
+			m.presetList.Select(i)
+			return
+		}
+	}
+	m.presetList.Select(min(m.presetList.GlobalIndex(), len(m.presetItems)-1))
+}
+
+func (m Model) selectedPreset() (presetListItem, bool) {
+	if len(m.presetItems) == 0 {
+		return presetListItem{}, false
+	}
+	return m.presetItems[m.presetList.GlobalIndex()].(presetListItem), true
+}
+
+func (m *Model) handlePresetKey(key string) tea.Cmd {
+	m.presetStatus = ""
+	if m.presetStore == nil {
+		m.presetStatus = "user presets are unavailable"
+		return nil
+	}
+	item, ok := m.selectedPreset()
+	switch key {
+	case "s":
+		m.presetEdit = presetEditSave
+		m.presetInput.SetValue("")
+		return m.presetInput.Focus()
+	case "r":
+		if !ok || item.user < 0 {
+			m.presetStatus = "built-in presets cannot be renamed"
+			return nil
+		}
+		m.presetEdit = presetEditRename
+		m.presetInput.SetValue(item.preset.Category + "/" + item.preset.Name)
+		m.presetInput.CursorEnd()
+		return m.presetInput.Focus()
+	case "d":
+		if !ok || item.user < 0 {
+			m.presetStatus = "built-in presets cannot be deleted"
+			return nil
+		}
+		if err := m.presetStore.Delete(item.user); err != nil {
+			m.presetStatus = err.Error()
+			return nil
+		}
+		m.refreshPresetItems(-1)
+	case "shift+up", "shift+down":
+		if !ok || item.user < 0 {
+			m.presetStatus = "built-in presets cannot be moved"
+			return nil
+		}
+		i := m.presetList.GlobalIndex() - 1
+		if key == "shift+down" {
+			i = m.presetList.GlobalIndex() + 1
+		}
+		if i < 0 || i >= len(m.presetItems) {
+			return nil
+		}
+		other := m.presetItems[i].(presetListItem)
+		if other.user < 0 || other.preset.Category != item.preset.Category {
+			return nil
+		}
+		if err := m.presetStore.Move(item.user, other.user); err != nil {
+			m.presetStatus = err.Error()
+			return nil
+		}
+		m.refreshPresetItems(other.user)
+	}
+	return nil
+}
+
+func (m *Model) updatePresetEdit(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.stopPresetEdit()
+		return nil
+	case "enter":
+		category, name := splitPresetName(m.presetInput.Value())
+		var err error
+		var selectUser int
+		switch m.presetEdit {
+		case presetEditSave:
+			err = m.presetStore.Add(video.Preset{
+				Name:     name,
+				Category: category,
+				Display:  m.d,
+			})
+			selectUser = len(m.presetStore.Presets()) - 1
+		case presetEditRename:
+			item, _ := m.selectedPreset()
+			selectUser = item.user
+			err = m.presetStore.Relabel(item.user, name, category)
+		}
+		if err != nil {
+			m.presetStatus = err.Error()
+			return nil
+		}
+		m.stopPresetEdit()
+		m.refreshPresetItems(selectUser)
+		return nil
+	}
+	var cmd tea.Cmd
+	m.presetInput, cmd = m.presetInput.Update(msg)
+	return cmd
+}
+
+func (m *Model) stopPresetEdit() {
+	m.presetEdit = presetEditNone
+	m.presetInput.Blur()
+	m.presetInput.SetValue("")
+}
+
+// splitPresetName splits "category/name" input; a bare name falls back to the
+// default user category.
+func splitPresetName(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if category, name, ok := strings.Cut(s, "/"); ok {
+		return strings.TrimSpace(category), strings.TrimSpace(name)
+	}
+	return preset.DefaultCategory, s
+}
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/video"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// buildSinkItems lists "Any sink" followed by the catalog grouped by
+// category, in order of first appearance.
+func buildSinkItems() []list.Item {
+	sinks := video.Sinks()
+	var categories []string
+	for _, s := range sinks {
+		if !slices.Contains(categories, s.Category) {
+			categories = append(categories, s.Category)
+		}
+	}
+	items := make([]list.Item, 0, len(sinks)+1)
+	items = append(items, sinkListItem{})
+	for _, c := range categories {
+		for _, s := range sinks {
+			if s.Category == c {
+				items = append(items, sinkListItem{sink: &s})
+			}
+		}
+	}
+	return items
+}
+
+func (m *Model) toogleSinkList() {
+	m.showSinkList = !m.showSinkList
+	m.sinkList.ResetFilter()
+}
+
+// selectSink sets the display to the native mode of the selected sink, at
+// a color depth it accepts.
+func (m *Model) selectSink() {
+	item, ok := m.sinkList.SelectedItem().(sinkListItem)
+	if !ok {
+		return
+	}
+	m.sink = item.sink
+	if m.sink == nil {
+		return
+	}
+	native := m.sink.Native()
+	m.d.Width, m.d.Height, m.d.RefreshRate, m.d.Interlaced, m.d.Fractional = native.Width, native.Height, native.RefreshRate, native.Interlaced, false
+	if m.sink.MaxBPC > 0 && m.d.ColorDepth.BPC() > m.sink.MaxBPC {
+		if c, err := video.ColorDepthFromBPC(m.sink.MaxBPC); err == nil {
+			m.d.ColorDepth = c
+		}
+	}
+	m.inputs[0].SetValue(strconv.Itoa(m.d.Width))
+	m.inputs[1].SetValue(strconv.Itoa(m.d.Height))
+	m.inputs[2].SetValue(refreshValue(m.d))
+	m.colorDepthList.Select(m.getColorDepthIndex(m.d.ColorDepth))
+}
+
+func (m Model) sinkName() string {
+	if m.sink == nil {
+		return "Any"
+	}
+	return m.sink.Name
+}
+
+type sinkListItem struct {
+	sink *video.Sink
+}
+
+func (i sinkListItem) Title() string {
+	if i.sink == nil {
+		return "Any sink"
+	}
+	return i.sink.Name
+}
+
+func (i sinkListItem) Description() string {
+	if i.sink == nil {
+		return "Every interface version"
+	}
+	return i.sink.Category + " · " + i.sink.Native().String() + "Hz · " + i.sink.InputSummary()
+}
+
+func (i sinkListItem) FilterValue() string {
+	if i.sink == nil {
+		return i.Title()
+	}
+	return i.sink.Name + " " + i.sink.Category + " " + i.sink.InputSummary()
+}
+
+// filterSinks keeps the items containing every word of term, ignoring case,
+// as the sinks command does.
+func filterSinks(term string, targets []string) []list.Rank {
+	words := strings.Fields(strings.ToLower(term))
+	var ranks []list.Rank
+	for i, t := range targets {
+		t = strings.ToLower(t)
+		if !slices.ContainsFunc(words, func(w string) bool { return !strings.Contains(t, w) }) {
+			ranks = append(ranks, list.Rank{Index: i})
+		}
+	}
+	return ranks
+}
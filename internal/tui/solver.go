@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aloababa/gvbc/internal/video"
+
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+// renderSolverContent lists, for the selected version of every interface
+// family (or all versions), the highest refresh rate at the current
+// resolution and the largest resolution at the current refresh rate and
+// aspect ratio.
+func (m Model) renderSolverContent() string {
+	aspectW, aspectH := aspectRatio(m.d.Width, m.d.Height)
+	t := table.New().
+		Headers("INTERFACE", "MAX HZ", "DSC HZ", "MAX RES", "DSC RES").
+		BorderStyle(focus)
+	for _, p := range m.panels {
+		versions := p.family.Versions
+		if index := p.list.GlobalIndex(); index > 0 {
+			versions = []video.Interface{p.items[index].(interfaceListItem).iface}
+		}
+		for _, i := range versions {
+			row := []string{i.String()}
+			for _, dsc := range []bool{false, true} {
+				if dsc && !i.DSC {
+					row = append(row, "-")
+					continue
+				}
+				if n, _, ok := i.BestMaxRefreshRate(m.d, dsc); ok {
+					row = append(row, fmt.Sprintf("%d Hz", n))
+				} else {
+					row = append(row, "-")
+				}
+			}
+			for _, dsc := range []bool{false, true} {
+				if dsc && !i.DSC {
+					row = append(row, "-")
+					continue
+				}
+				if r, _, ok := i.BestMaxResolution(m.d, aspectW, aspectH, dsc); ok {
+					row = append(row, fmt.Sprintf("%dx%d", r.Width, r.Height))
+				} else {
+					row = append(row, "-")
+				}
+			}
+			t.Row(row...)
+		}
+	}
+	var b strings.Builder
+	b.WriteString(line.Render("Limits"))
+	b.WriteString("\n")
+	b.WriteString(normal.Render(fmt.Sprintf("Max refresh at %dx%d, max %d:%d resolution at %s Hz", m.d.Width, m.d.Height, aspectW, aspectH, video.FormatRefresh(m.d.RefreshRate, m.d.Fractional))))
+	b.WriteString("\n\n")
+	b.WriteString(t.Render())
+	return b.String()
+}
+
+func aspectRatio(w, h int) (int, int) {
+	a, b := w, h
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a == 0 {
+		return 16, 9
+	}
+	return w / a, h / a
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"slices"
+
+	"github.com/aloababa/gvbc/internal/video"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// buildSourceItems lists "Any source" followed by the catalog grouped by
+// category, in order of first appearance.
+func buildSourceItems() []list.Item {
+	sources := video.Sources()
+	var categories []string
+	for _, s := range sources {
+		if !slices.Contains(categories, s.Category) {
+			categories = append(categories, s.Category)
+		}
+	}
+	items := make([]list.Item, 0, len(sources)+1)
+	items = append(items, sourceListItem{})
+	for _, c := range categories {
+		for _, s := range sources {
+			if s.Category == c {
+				items = append(items, sourceListItem{source: &s})
+			}
+		}
+	}
+	return items
+}
+
+func (m *Model) toogleSourceList() {
+	m.showSourceList = !m.showSourceList
+}
+
+func (m *Model) selectSource() {
+	m.source = m.sourceItems[m.sourceList.GlobalIndex()].(sourceListItem).source
+}
+
+func (m Model) sourceName() string {
+	if m.source == nil {
+		return "Any"
+	}
+	return m.source.Name
+}
+
+type sourceListItem struct {
+	source *video.Source
+}
+
+func (i sourceListItem) Title() string {
+	if i.source == nil {
+		return "Any source"
+	}
+	return i.source.Name
+}
+
+func (i sourceListItem) Description() string {
+	if i.source == nil {
+		return "Every interface version"
+	}
+	return i.source.Category + " · " + i.source.PortSummary()
+}
+
+func (i sourceListItem) FilterValue() string { return i.Title() }
@@ -3,10 +3,11 @@ package tui
 import (
 	"fmt"
 	"log/slog"
-	"sort"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/aloababa/gvbc/internal/preset"
 	"github.com/aloababa/gvbc/internal/video"
 
 	"github.com/76creates/stickers/flexbox"
@@ -24,13 +25,10 @@ type Model struct {
 	screenHeight  int
 	screenRefresh bool
 
-	flexbox         *flexbox.HorizontalFlexBox
-	displayCell     *flexbox.Cell
-	displayPortCell *flexbox.Cell
-	hdmiCell        *flexbox.Cell
+	flexbox     *flexbox.HorizontalFlexBox
+	displayCell *flexbox.Cell
 
-	displayPortTable *table.Table
-	hdmiTable        *table.Table
+	panels []*interfacePanel
 
 	inputs []textinput.Model
 
@@ -38,60 +36,96 @@ type Model struct {
 	colorDepthList     list.Model
 	showColorDepthList bool
 
+	encodingItems    []list.Item
+	encodingList     list.Model
+	showEncodingList bool
+
 	timingItems    []list.Item
 	timingList     list.Model
 	showTimingList bool
 
-	displayPortItems    []list.Item
-	displayPortList     list.Model
-	showDisplayPortList bool
-
-	hdmiItems    []list.Item
-	hdmiList     list.Model
-	showHdmiList bool
-
+	presetStore    *preset.Store
 	presetItems    []list.Item
 	presetList     list.Model
 	showPresetList bool
+	presetInput    textinput.Model
+	presetEdit     presetEdit
+	presetStatus   string
+
+	source         *video.Source
+	sourceItems    []list.Item
+	sourceList     list.Model
+	showSourceList bool
+
+	sink         *video.Sink
+	sinkItems    []list.Item
+	sinkList     list.Model
+	showSinkList bool
+
+	showSolver bool
+
+	showExport  bool
+	exportIndex int
 
 	focusIndex int
 }
 
-func NewModel() *Model {
+type interfacePanel struct {
+	family video.InterfaceFamily
+
+	cell  *flexbox.Cell
+	table *table.Table
+
+	items    []list.Item
+	list     list.Model
+	showList bool
+
+	suggestions []video.Suggestion
+	// rejects lists why the sink does not accept the display.
+	rejects []string
+	// notice replaces the table when the source cannot output the family
+	// or the selected version, or the sink cannot accept them.
+	notice string
+}
+
+func NewModel(presetStore *preset.Store) *Model {
 	fb := flexbox.NewHorizontal(0, 0)
 	displayCell := flexbox.NewCell(1, 2).SetStyle(flexCell)
-	displayPortCell := flexbox.NewCell(1, 1).SetStyle(flexCell)
-	hdmiCell := flexbox.NewCell(1, 1).SetStyle(flexCell)
+
+	colorDepths := video.ColorDepths()
+	encodings := video.Encodings()
+	timings := video.Timings()
+	families := video.InterfaceFamilies()
+	m := &Model{
+		screenRefresh:   true,
+		flexbox:         fb,
+		displayCell:     displayCell,
+		panels:          make([]*interfacePanel, len(families)),
+		inputs:          make([]textinput.Model, 3),
+		colorDepthItems: make([]list.Item, len(colorDepths)),
+		encodingItems:   make([]list.Item, len(encodings)),
+		timingItems:     make([]list.Item, len(timings)),
+		presetStore:     presetStore,
+	}
+	if presetStore != nil {
+		// Shown in the preset panel until the next preset action.
+		m.presetStatus = strings.Join(presetStore.Warnings(), "; ")
+	}
+
+	interfaceCells := make([]*flexbox.Cell, len(families))
+	for i, f := range families {
+		m.panels[i] = newInterfacePanel(f)
+		interfaceCells[i] = m.panels[i].cell
+	}
 	fb.AddColumns([]*flexbox.Column{
 		fb.NewColumn().AddCells(
 			displayCell,
 		),
 		fb.NewColumn().AddCells(
-			displayPortCell,
-			hdmiCell,
+			interfaceCells...,
 		),
 	})
 
-	colorDepths := video.ColorDepths()
-	timings := video.Timings()
-	displayPorts := video.DisplayPortVersions()
-	hdmis := video.HDMIVersions()
-	presets := video.Presets()
-
-	m := &Model{
-		screenRefresh:    true,
-		flexbox:          fb,
-		displayCell:      displayCell,
-		displayPortCell:  displayPortCell,
-		hdmiCell:         hdmiCell,
-		inputs:           make([]textinput.Model, 3),
-		colorDepthItems:  make([]list.Item, len(colorDepths)),
-		timingItems:      make([]list.Item, len(timings)),
-		displayPortItems: make([]list.Item, len(displayPorts)+1),
-		hdmiItems:        make([]list.Item, len(hdmis)+1),
-		presetItems:      make([]list.Item, len(presets)),
-	}
-
 	for i, c := range colorDepths {
 		switch c {
 		case video.ColorDepth8bit():
@@ -132,6 +166,23 @@ func NewModel() *Model {
 	m.colorDepthList.SetShowTitle(false)
 	m.colorDepthList.Select(1)
 
+	for i, e := range encodings {
+		m.encodingItems[i] = encodingListItem{
+			encoding: e,
+		}
+	}
+	delegate = list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = focus
+	delegate.Styles.SelectedDesc = focus
+	m.encodingList = list.New(m.encodingItems, delegate, 0, 0)
+	m.encodingList.Styles.FilterCursor = focus
+	m.encodingList.SetShowPagination(false)
+	m.encodingList.SetShowFilter(false)
+	m.encodingList.SetShowHelp(false)
+	m.encodingList.SetShowStatusBar(false)
+	m.encodingList.SetShowTitle(false)
+	m.encodingList.Select(0)
+
 	for i, t := range timings {
 		m.timingItems[i] = timingListItem{
 			timing: t,
@@ -149,54 +200,6 @@ func NewModel() *Model {
 	m.timingList.SetShowTitle(false)
 	m.timingList.Select(1)
 
-	m.displayPortItems[0] = displayPortListItem{
-		dp: video.DisplayPort{
-			Version: "All",
-		},
-	}
-	i := 1
-	for _, dp := range displayPorts {
-		m.displayPortItems[i] = displayPortListItem{
-			dp: dp,
-		}
-		i++
-	}
-	delegate = list.NewDefaultDelegate()
-	delegate.Styles.SelectedTitle = focus
-	delegate.Styles.SelectedDesc = focus
-	m.displayPortList = list.New(m.displayPortItems, delegate, 0, 0)
-	m.displayPortList.Styles.FilterCursor = focus
-	m.displayPortList.SetShowPagination(false)
-	m.displayPortList.SetShowFilter(false)
-	m.displayPortList.SetShowHelp(false)
-	m.displayPortList.SetShowStatusBar(false)
-	m.displayPortList.SetShowTitle(false)
-	m.displayPortList.Select(0)
-
-	m.hdmiItems[0] = hdmiListItem{
-		hdmi: video.HDMI{
-			Version: "All",
-		},
-	}
-	i = 1
-	for _, hdmi := range hdmis {
-		m.hdmiItems[i] = hdmiListItem{
-			hdmi: hdmi,
-		}
-		i++
-	}
-	delegate = list.NewDefaultDelegate()
-	delegate.Styles.SelectedTitle = focus
-	delegate.Styles.SelectedDesc = focus
-	m.hdmiList = list.New(m.hdmiItems, delegate, 0, 0)
-	m.hdmiList.Styles.FilterCursor = focus
-	m.hdmiList.SetShowPagination(false)
-	m.hdmiList.SetShowFilter(false)
-	m.hdmiList.SetShowHelp(false)
-	m.hdmiList.SetShowStatusBar(false)
-	m.hdmiList.SetShowTitle(false)
-	m.hdmiList.Select(0)
-
 	var t textinput.Model
 	for i := range m.inputs {
 		t = textinput.New()
@@ -217,18 +220,15 @@ func NewModel() *Model {
 			t.SetValue("2160")
 		case 2:
 			t.Blur()
-			t.CharLimit = 3
-			t.Width = 3
+			// Room for a fractional rate and an i suffix, e.g. 59.94i.
+			t.CharLimit = 7
+			t.Width = 7
 			t.SetValue("144")
 		}
 		m.inputs[i] = t
 	}
 
-	for i, p := range presets {
-		m.presetItems[i] = presetListItem{
-			preset: p,
-		}
-	}
+	m.presetItems = m.buildPresetItems()
 	delegate = list.NewDefaultDelegate()
 	delegate.Styles.SelectedTitle = focus
 	delegate.Styles.SelectedDesc = focus
@@ -241,19 +241,46 @@ func NewModel() *Model {
 	m.presetList.SetShowTitle(false)
 	m.presetList.Select(0)
 
+	m.sourceItems = buildSourceItems()
+	delegate = list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = focus
+	delegate.Styles.SelectedDesc = focus
+	m.sourceList = list.New(m.sourceItems, delegate, 0, 0)
+	m.sourceList.Styles.FilterCursor = focus
+	m.sourceList.SetShowPagination(false)
+	m.sourceList.SetShowFilter(false)
+	m.sourceList.SetShowHelp(false)
+	m.sourceList.SetShowStatusBar(false)
+	m.sourceList.SetShowTitle(false)
+	m.sourceList.Select(0)
+
+	m.sinkItems = buildSinkItems()
+	delegate = list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = focus
+	delegate.Styles.SelectedDesc = focus
+	m.sinkList = list.New(m.sinkItems, delegate, 0, 0)
+	m.sinkList.Styles.FilterCursor = focus
+	m.sinkList.Styles.FilterPrompt = focus
+	m.sinkList.Filter = filterSinks
+	m.sinkList.SetShowPagination(false)
+	m.sinkList.SetShowHelp(false)
+	m.sinkList.SetShowStatusBar(false)
+	m.sinkList.SetShowTitle(false)
+	m.sinkList.Select(0)
+
+	m.presetInput = textinput.New()
+	m.presetInput.Prompt = "Name: "
+	m.presetInput.Placeholder = "name or category/name"
+	m.presetInput.Cursor.Style = focus
+	m.presetInput.CharLimit = 64
+
 	m.updateDisplay()
 
 	slog.Debug("updated display", slog.Any("display", m.d))
 
-	m.displayPortTable = table.New().
-		Headers([]string{"VERSION", "MODE", "MAX", "EFFECTIVE", "USAGE", "HDR", "STATUS"}...).
-		Rows(m.displayPortTableData()...).
-		BorderStyle(focus)
-
-	m.hdmiTable = table.New().
-		Headers([]string{"VERSION", "MODE", "MAX", "EFFECTIVE", "USAGE", "HDR", "STATUS"}...).
-		Rows(m.hdmiTableData()...).
-		BorderStyle(focus)
+	for _, p := range m.panels {
+		p.updateTable(m.d, m.source, m.sink)
+	}
 
 	return m
 }
@@ -288,19 +315,25 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	case tea.KeyMsg:
+		if m.presetEdit != presetEditNone && msg.String() != "ctrl+c" {
+			return m, m.updatePresetEdit(msg)
+		}
+		if m.showSinkList && m.sinkList.FilterState() == list.Filtering && msg.String() != "ctrl+c" {
+			var cmd tea.Cmd
+			m.sinkList, cmd = m.sinkList.Update(msg)
+			return m, cmd
+		}
 		switch s := msg.String(); s {
 		case "ctrl+c":
 			return m, tea.Quit
 		case "tab", "up", "down":
-			if !m.showColorDepthList && !m.showTimingList &&
-				!m.showDisplayPortList && !m.showHdmiList &&
-				!m.showPresetList {
+			if !m.listShown() {
 				if s == "up" {
 					m.focusIndex--
 				} else {
 					m.focusIndex++
 				}
-				index := len(m.inputs) + 3
+				index := len(m.inputs) + 2 + len(m.panels)
 				if m.focusIndex > index {
 					m.focusIndex = 0
 				} else if m.focusIndex < 0 {
@@ -321,7 +354,13 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Batch(cmds...)
 			}
 		case "enter":
-			if m.showPresetList {
+			if m.showSourceList {
+				m.selectSource()
+				m.toogleSourceList()
+			} else if m.showSinkList {
+				m.selectSink()
+				m.toogleSinkList()
+			} else if m.showPresetList {
 				m.applyPreset(m.presetItems[m.presetList.GlobalIndex()].(presetListItem).preset)
 				m.tooglePresetList()
 				m.presetList.Select(0)
@@ -330,30 +369,78 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				case 3:
 					m.toogleColorDepthList()
 				case 4:
-					m.toogleTimingList()
+					m.toogleEncodingList()
 				case 5:
-					m.toogleDisplayPortList()
-				case 6:
-					m.toogleHdmiList()
+					m.toogleTimingList()
+				default:
+					if p := m.focusedPanel(); p != nil {
+						p.toogleList()
+					}
 				}
 				return m, nil
 			}
 		case "p":
 			m.tooglePresetList()
 			m.presetList.Select(0)
+			m.presetStatus = ""
 			return m, nil
+		case "g":
+			if !m.listShown() || m.showSourceList {
+				m.toogleSourceList()
+				return m, nil
+			}
+		case "n":
+			if !m.listShown() || m.showSinkList {
+				m.toogleSinkList()
+				return m, nil
+			}
+		case "m":
+			if !m.listShown() {
+				m.showSolver = !m.showSolver
+				m.showExport = false
+				return m, nil
+			}
+		case "x":
+			if !m.listShown() {
+				m.toogleExport()
+				m.showSolver = false
+				return m, nil
+			}
+		case "left", "right":
+			if m.showExport {
+				if s == "left" {
+					m.cycleExport(-1)
+				} else {
+					m.cycleExport(1)
+				}
+				return m, nil
+			}
+		case "s", "r", "d", "shift+up", "shift+down":
+			if m.showPresetList {
+				return m, m.handlePresetKey(s)
+			}
 		case "esc":
 			if m.showColorDepthList {
 				m.toogleColorDepthList()
+			} else if m.showEncodingList {
+				m.toogleEncodingList()
 			} else if m.showTimingList {
 				m.toogleTimingList()
-			} else if m.showDisplayPortList {
-				m.toogleDisplayPortList()
-			} else if m.showHdmiList {
-				m.toogleHdmiList()
+			} else if p := m.shownPanel(); p != nil {
+				p.toogleList()
 			} else if m.showPresetList {
 				m.tooglePresetList()
 				m.presetList.Select(0)
+			} else if m.showSourceList {
+				m.toogleSourceList()
+			} else if m.showSinkList && m.sinkList.FilterState() == list.FilterApplied {
+				m.sinkList.ResetFilter()
+			} else if m.showSinkList {
+				m.toogleSinkList()
+			} else if m.showSolver {
+				m.showSolver = false
+			} else if m.showExport {
+				m.toogleExport()
 			}
 			return m, nil
 		}
@@ -361,7 +448,13 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	var cmd tea.Cmd
 	cmds := make([]tea.Cmd, 0, len(m.inputs)+5)
-	if !m.showPresetList {
+	if m.showSourceList {
+		m.sourceList, cmd = m.sourceList.Update(msg)
+		cmds = append(cmds, cmd)
+	} else if m.showSinkList {
+		m.sinkList, cmd = m.sinkList.Update(msg)
+		cmds = append(cmds, cmd)
+	} else if !m.showPresetList {
 		switch m.focusIndex {
 		case 0, 1, 2:
 			for i := range m.inputs {
@@ -372,14 +465,16 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.colorDepthList, cmd = m.colorDepthList.Update(msg)
 			cmds = append(cmds, cmd)
 		case 4:
-			m.timingList, cmd = m.timingList.Update(msg)
+			m.encodingList, cmd = m.encodingList.Update(msg)
 			cmds = append(cmds, cmd)
 		case 5:
-			m.displayPortList, cmd = m.displayPortList.Update(msg)
-			cmds = append(cmds, cmd)
-		case 6:
-			m.hdmiList, cmd = m.hdmiList.Update(msg)
+			m.timingList, cmd = m.timingList.Update(msg)
 			cmds = append(cmds, cmd)
+		default:
+			if p := m.focusedPanel(); p != nil {
+				p.list, cmd = p.list.Update(msg)
+				cmds = append(cmds, cmd)
+			}
 		}
 	} else {
 		m.presetList, cmd = m.presetList.Update(msg)
@@ -388,10 +483,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	m.updateDisplay()
 
-	m.displayPortTable = m.displayPortTable.ClearRows()
-	m.displayPortTable = m.displayPortTable.Rows(m.displayPortTableData()...)
-	m.hdmiTable = m.hdmiTable.ClearRows()
-	m.hdmiTable = m.hdmiTable.Rows(m.hdmiTableData()...)
+	for _, p := range m.panels {
+		p.updateTable(m.d, m.source, m.sink)
+	}
 
 	return m, tea.Batch(cmds...)
 }
@@ -402,17 +496,22 @@ func (m *Model) updateScreen(w, h int) {
 	m.flexbox.SetWidth(w)
 	m.flexbox.SetHeight(h)
 	m.colorDepthList.SetSize(m.displayCell.GetWidth(), m.displayCell.GetHeight()-10)
+	m.encodingList.SetSize(m.displayCell.GetWidth(), m.displayCell.GetHeight()-10)
 	m.timingList.SetSize(m.displayCell.GetWidth(), m.displayCell.GetHeight()-10)
-	m.displayPortList.SetSize(m.displayCell.GetWidth(), m.displayCell.GetHeight()-10)
-	m.hdmiList.SetSize(m.displayCell.GetWidth(), m.displayCell.GetHeight()-10)
+	for _, p := range m.panels {
+		p.list.SetSize(m.displayCell.GetWidth(), m.displayCell.GetHeight()-10)
+	}
 	m.presetList.SetSize(m.displayCell.GetWidth(), m.displayCell.GetHeight()-10)
+	m.sourceList.SetSize(m.displayCell.GetWidth(), m.displayCell.GetHeight()-10)
+	m.sinkList.SetSize(m.displayCell.GetWidth(), m.displayCell.GetHeight()-10)
 	slog.Debug("screen updated", slog.Int("width", w), slog.Int("height", h))
 }
 
 func (m Model) View() string {
 	m.displayCell.SetContent(renderCellContent("Display", m.displayCell, m.renderDisplayContent()))
-	m.displayPortCell.SetContent(renderCellContent("Display Port", m.displayPortCell, m.displayPortTable.Render()))
-	m.hdmiCell.SetContent(renderCellContent("HDMI", m.hdmiCell, m.hdmiTable.Render()))
+	for _, p := range m.panels {
+		p.cell.SetContent(renderCellContent(p.family.Name, p.cell, p.renderContent()))
+	}
 	return m.flexbox.Render()
 }
 
@@ -422,18 +521,43 @@ func (m Model) renderDisplayContent() string {
 	if m.showColorDepthList {
 		displayContent.WriteString(m.colorDepthList.View())
 		keyBinds = listKeyBind
+	} else if m.showEncodingList {
+		displayContent.WriteString(m.encodingList.View())
+		keyBinds = listKeyBind
 	} else if m.showTimingList {
 		displayContent.WriteString(m.timingList.View())
 		keyBinds = listKeyBind
-	} else if m.showDisplayPortList {
-		displayContent.WriteString(m.displayPortList.View())
-		keyBinds = listKeyBind
-	} else if m.showHdmiList {
-		displayContent.WriteString(m.hdmiList.View())
+	} else if p := m.shownPanel(); p != nil {
+		displayContent.WriteString(p.list.View())
 		keyBinds = listKeyBind
 	} else if m.showPresetList {
 		displayContent.WriteString(m.presetList.View())
-		keyBinds = presetKeyBind
+		if m.presetEdit != presetEditNone {
+			displayContent.WriteString("\n")
+			displayContent.WriteString(m.presetInput.View())
+			keyBinds = presetEditKeyBind
+		} else {
+			keyBinds = presetKeyBind
+		}
+		if m.presetStatus != "" {
+			displayContent.WriteString("\n")
+			displayContent.WriteString(highlight.Render(m.presetStatus))
+		}
+	} else if m.showSourceList {
+		displayContent.WriteString(m.sourceList.View())
+		keyBinds = sourceKeyBind
+	} else if m.showSinkList {
+		displayContent.WriteString(m.sinkList.View())
+		keyBinds = sinkKeyBind
+		if m.sinkList.FilterState() == list.Filtering {
+			keyBinds = sinkFilterKeyBind
+		}
+	} else if m.showSolver {
+		displayContent.WriteString(m.renderSolverContent())
+		keyBinds = solverKeyBind
+	} else if m.showExport {
+		displayContent.WriteString(m.renderExportContent())
+		keyBinds = exportKeyBind
 	} else {
 		displayContent.WriteString(line.Render("Resolution @ Refresh Rate"))
 		displayContent.WriteString("\n")
@@ -452,28 +576,53 @@ func (m Model) renderDisplayContent() string {
 			displayContent.WriteString(normal.Render(m.colorDepthItems[m.colorDepthList.GlobalIndex()].(colorDepthListItem).desc))
 		}
 		displayContent.WriteString("\n\n\n")
-		displayContent.WriteString(line.Render("Timing"))
+		displayContent.WriteString(line.Render("Encoding"))
 		displayContent.WriteString("\n")
 		if m.focusIndex == 4 {
-			displayContent.WriteString(focus.Render(m.timingItems[m.timingList.GlobalIndex()].(timingListItem).timing.String()))
+			displayContent.WriteString(focus.Render(m.encodingItems[m.encodingList.GlobalIndex()].(encodingListItem).encoding.String()))
 		} else {
-			displayContent.WriteString(normal.Render(m.timingItems[m.timingList.GlobalIndex()].(timingListItem).timing.String()))
+			displayContent.WriteString(normal.Render(m.encodingItems[m.encodingList.GlobalIndex()].(encodingListItem).encoding.String()))
 		}
 		displayContent.WriteString("\n\n\n")
-		displayContent.WriteString(line.Render("DisplayPort"))
+		displayContent.WriteString(line.Render("Timing"))
 		displayContent.WriteString("\n")
 		if m.focusIndex == 5 {
-			displayContent.WriteString(focus.Render(m.displayPortItems[m.displayPortList.GlobalIndex()].(displayPortListItem).dp.Version))
+			displayContent.WriteString(focus.Render(m.timingItems[m.timingList.GlobalIndex()].(timingListItem).timing.String()))
 		} else {
-			displayContent.WriteString(normal.Render(m.displayPortItems[m.displayPortList.GlobalIndex()].(displayPortListItem).dp.Version))
+			displayContent.WriteString(normal.Render(m.timingItems[m.timingList.GlobalIndex()].(timingListItem).timing.String()))
 		}
 		displayContent.WriteString("\n\n\n")
-		displayContent.WriteString(line.Render("HDMI"))
+		for i, p := range m.panels {
+			if i > 0 {
+				displayContent.WriteString("\n\n\n")
+			}
+			displayContent.WriteString(line.Render(p.family.Name))
+			displayContent.WriteString("\n")
+			if m.focusIndex == len(m.inputs)+3+i {
+				displayContent.WriteString(focus.Render(p.selectedVersion()))
+			} else {
+				displayContent.WriteString(normal.Render(p.selectedVersion()))
+			}
+		}
+		displayContent.WriteString("\n\n\n")
+		displayContent.WriteString(line.Render("Source"))
 		displayContent.WriteString("\n")
-		if m.focusIndex == 6 {
-			displayContent.WriteString(focus.Render(m.hdmiItems[m.hdmiList.GlobalIndex()].(hdmiListItem).hdmi.Version))
-		} else {
-			displayContent.WriteString(normal.Render(m.hdmiItems[m.hdmiList.GlobalIndex()].(hdmiListItem).hdmi.Version))
+		displayContent.WriteString(normal.Render(m.sourceName()))
+		if m.source != nil {
+			for _, e := range m.source.Exceeds(m.d) {
+				displayContent.WriteString("\n")
+				displayContent.WriteString(highlight.Render(e))
+			}
+		}
+		displayContent.WriteString("\n\n\n")
+		displayContent.WriteString(line.Render("Sink"))
+		displayContent.WriteString("\n")
+		displayContent.WriteString(normal.Render(m.sinkName()))
+		if m.sink != nil {
+			for _, q := range m.sink.Quirks {
+				displayContent.WriteString("\n")
+				displayContent.WriteString(subtle.Render(q))
+			}
 		}
 		displayContent.WriteString("\n")
 		displayContent.WriteString(line.Render(strings.Repeat(" ", 32)))
@@ -510,129 +659,218 @@ func (m *Model) toogleColorDepthList() {
 	m.showColorDepthList = !m.showColorDepthList
 }
 
-func (m *Model) toogleTimingList() {
-	m.showTimingList = !m.showTimingList
+func (m *Model) toogleEncodingList() {
+	m.showEncodingList = !m.showEncodingList
 }
 
-func (m *Model) toogleDisplayPortList() {
-	m.showDisplayPortList = !m.showDisplayPortList
+func (m *Model) toogleTimingList() {
+	m.showTimingList = !m.showTimingList
 }
 
-func (m *Model) toogleHdmiList() {
-	m.showHdmiList = !m.showHdmiList
+func (p *interfacePanel) toogleList() {
+	p.showList = !p.showList
 }
 
 func (m *Model) tooglePresetList() {
 	m.showPresetList = !m.showPresetList
 }
 
-func (m Model) getLowestCompatibleMode(modes []video.TransmissionMode) video.TransmissionMode {
-	if len(modes) == 0 {
+func (m Model) listShown() bool {
+	return m.showColorDepthList || m.showEncodingList || m.showTimingList || m.showPresetList || m.showSourceList ||
+		m.showSinkList || m.shownPanel() != nil
+}
+
+func (m Model) focusedPanel() *interfacePanel {
+	i := m.focusIndex - len(m.inputs) - 3
+	if i < 0 || i >= len(m.panels) {
 		return nil
 	}
-	sort.Sort(byEffectiveBandwidth(modes))
-	lastMode := modes[0]
-	for _, mode := range modes {
-		if mode.EffectiveBandwidth().Bits >= m.d.Bandwidth().Bits {
-			lastMode = mode
-			continue
-		} else if lastMode.EffectiveBandwidth().Bits >= m.d.Bandwidth().Bits {
-			return lastMode
-		} else if mode.MaxCompressedBandwidth(m.d.ColorDepth).Bits >= m.d.Bandwidth().Bits {
-			return mode
+	return m.panels[i]
+}
+
+func (m Model) shownPanel() *interfacePanel {
+	for _, p := range m.panels {
+		if p.showList {
+			return p
 		}
 	}
-	return lastMode
+	return nil
 }
 
-func (m Model) displayPortTableData() [][]string {
-	var rows [][]string
-	if index := m.displayPortList.GlobalIndex(); index > 0 {
-		dp := m.displayPortItems[index].(displayPortListItem).dp
-		for _, mode := range dp.Modes {
-			rows = append(rows, m.displayPortRow(dp, mode))
+func newInterfacePanel(f video.InterfaceFamily) *interfacePanel {
+	p := &interfacePanel{
+		family: f,
+		cell:   flexbox.NewCell(1, 1).SetStyle(flexCell),
+		items:  make([]list.Item, len(f.Versions)+1),
+	}
+	p.items[0] = interfaceListItem{
+		iface: video.Interface{
+			Family:  f.Name,
+			Version: "All",
+		},
+	}
+	for i, v := range f.Versions {
+		p.items[i+1] = interfaceListItem{
+			iface: v,
 		}
-	} else {
-		for _, item := range m.displayPortItems[1:] {
-			dp := item.(displayPortListItem).dp
-			mode := m.getLowestCompatibleMode(dp.Modes)
-			rows = append(rows, m.displayPortRow(dp, mode))
+	}
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = focus
+	delegate.Styles.SelectedDesc = focus
+	p.list = list.New(p.items, delegate, 0, 0)
+	p.list.Styles.FilterCursor = focus
+	p.list.SetShowPagination(false)
+	p.list.SetShowFilter(false)
+	p.list.SetShowHelp(false)
+	p.list.SetShowStatusBar(false)
+	p.list.SetShowTitle(false)
+	p.list.Select(0)
+	p.table = table.New().
+		Headers([]string{"VERSION", "MODE", "MAX", "EFFECTIVE", "USAGE", "HDR", "STATUS"}...).
+		BorderStyle(focus)
+	return p
+}
+
+func (p *interfacePanel) selectedVersion() string {
+	return p.items[p.list.GlobalIndex()].(interfaceListItem).iface.Version
+}
+
+const maxPanelSuggestions = 3
+
+func (p *interfacePanel) updateTable(d video.Display, source *video.Source, sink *video.Sink) {
+	p.table = p.table.ClearRows()
+	p.notice = ""
+	p.rejects = nil
+	target, ok := p.target(source, sink)
+	if !ok {
+		p.suggestions = nil
+		return
+	}
+	if sink != nil {
+		p.rejects = sink.Rejects(d)
+	}
+	p.table = p.table.Rows(p.tableData(d, target, source, sink)...)
+	p.suggestions = p.suggest(d, target, source, sink)
+}
+
+// available is the family restricted to what the source outputs and the
+// sink accepts. Otherwise it returns why nothing is left.
+func (p *interfacePanel) available(source *video.Source, sink *video.Sink) (video.InterfaceFamily, string) {
+	f := p.family
+	if source != nil {
+		var ok bool
+		if f, ok = source.Family(f); !ok || len(f.Versions) == 0 {
+			return f, fmt.Sprintf("%s has no %s output", source.Name, p.family.Name)
 		}
 	}
-	return rows
+	if sink != nil {
+		var ok bool
+		if f, ok = sink.Family(f); !ok {
+			return f, fmt.Sprintf("%s has no %s input", sink.Name, p.family.Name)
+		}
+		if len(f.Versions) == 0 {
+			return f, fmt.Sprintf("%s accepts no %s link the source outputs", sink.Name, p.family.Name)
+		}
+	}
+	return f, ""
 }
 
-func (m Model) displayPortRow(dp video.DisplayPort, mode video.TransmissionMode) []string {
-	var hdr string
-	if dp.CanHDR(m.d.ColorDepth) {
-		hdr = "Yes"
-	} else {
-		hdr = "No"
+// target returns the selected version, or the newest one when all versions
+// are shown, as far as the source outputs it and the sink accepts it.
+// Otherwise it sets the notice.
+func (p *interfacePanel) target(source *video.Source, sink *video.Sink) (video.Interface, bool) {
+	f, notice := p.available(source, sink)
+	if notice != "" {
+		p.notice = notice
+		return video.Interface{}, false
 	}
-	var status string
-	if mode.EffectiveBandwidth().Bits >= m.d.Bandwidth().Bits {
-		status = "✅"
-	} else {
-		if dp.DSC {
-			if mode.MaxCompressedBandwidth(m.d.ColorDepth).Bits >= m.d.Bandwidth().Bits {
-				status = "❗ (DSC)"
-			} else {
-				status = "❌ (Bandwidth)"
-				hdr = "No"
+	index := p.list.GlobalIndex()
+	if index == 0 {
+		return f.Versions[0], true
+	}
+	selected := p.items[index].(interfaceListItem).iface
+	if v, ok := f.Version(selected.Version); ok {
+		return v, true
+	}
+	if source != nil {
+		if out, ok := source.Family(p.family); ok {
+			if _, ok := out.Version(selected.Version); !ok {
+				p.notice = fmt.Sprintf("%s outputs at most %s", source.Name, out.Versions[0])
+				return video.Interface{}, false
 			}
-		} else {
-			status = "❌ (No DSC)"
-			hdr = "No"
 		}
 	}
-	return []string{dp.Version, mode.GetName(),
-		mode.GetBandwidth().String(), mode.EffectiveBandwidth().String(),
-		fmt.Sprintf("%.1f%%", mode.Usage(m.d.Bandwidth())), hdr, status}
+	in, _ := sink.Family(p.family)
+	p.notice = fmt.Sprintf("%s accepts at most %s", sink.Name, in.Versions[0])
+	return video.Interface{}, false
 }
 
-func (m Model) hdmiTableData() [][]string {
-	var rows [][]string
-	if index := m.hdmiList.GlobalIndex(); index > 0 {
-		hdmi := m.hdmiItems[index].(hdmiListItem).hdmi
-		for _, mode := range hdmi.Modes {
-			rows = append(rows, m.hdmiRow(hdmi, mode))
+// suggest returns remediations for the target if the display does not fit
+// it. With a source or a sink, moving to a version they cannot carry is left
+// out.
+func (p *interfacePanel) suggest(d video.Display, target video.Interface, source *video.Source, sink *video.Sink) []video.Suggestion {
+	suggestions := video.Suggest(d, target, 0)
+	if source != nil || sink != nil {
+		suggestions = slices.DeleteFunc(suggestions, func(s video.Suggestion) bool {
+			return s.Interface.Version != target.Version
+		})
+	}
+	return suggestions[:min(len(suggestions), maxPanelSuggestions)]
+}
+
+func (p *interfacePanel) renderContent() string {
+	if p.notice != "" {
+		return highlight.Render(p.notice)
+	}
+	if len(p.suggestions) == 0 && len(p.rejects) == 0 {
+		return p.table.Render()
+	}
+	var lines []string
+	for _, r := range p.rejects {
+		lines = append(lines, highlight.Render(r))
+	}
+	if len(p.suggestions) > 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
 		}
+		lines = append(lines, highlight.Bold(true).Render("Suggestions"))
+	}
+	for i, s := range p.suggestions {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, strings.Join(s.Changes, ", ")))
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, p.table.Render(), "", lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+func (p *interfacePanel) tableData(d video.Display, target video.Interface, source *video.Source, sink *video.Sink) [][]string {
+	var results []video.Compatibility
+	if p.list.GlobalIndex() > 0 {
+		results = target.Evaluate(d)
 	} else {
-		for _, item := range m.hdmiItems[1:] {
-			hdmi := item.(hdmiListItem).hdmi
-			mode := m.getLowestCompatibleMode(hdmi.Modes)
-			rows = append(rows, m.hdmiRow(hdmi, mode))
+		f, _ := p.available(source, sink)
+		results = f.Evaluate(d)
+	}
+	rows := make([][]string, len(results))
+	for i, c := range results {
+		rows[i] = compatibilityRow(c)
+		if len(p.rejects) > 0 && (c.Status == video.StatusOK || c.Status == video.StatusDSC) {
+			rows[i][len(rows[i])-1] = sinkRejectedStatus
 		}
 	}
 	return rows
 }
 
-func (m Model) hdmiRow(hdmi video.HDMI, mode video.TransmissionMode) []string {
-	var hdr string
-	if hdmi.CanHDR(m.d.ColorDepth) {
+// sinkRejectedStatus replaces the status of a mode the link carries but the
+// sink does not accept.
+const sinkRejectedStatus = "⛔ (Sink)"
+
+func compatibilityRow(c video.Compatibility) []string {
+	hdr := "No"
+	if c.HDR {
 		hdr = "Yes"
-	} else {
-		hdr = "No"
-	}
-	var status string
-	if mode.EffectiveBandwidth().Bits >= m.d.Bandwidth().Bits {
-		status = "✅"
-	} else {
-		if hdmi.DSC {
-			if mode.MaxCompressedBandwidth(m.d.ColorDepth).Bits >= m.d.Bandwidth().Bits {
-				status = "❗ (DSC)"
-			} else {
-				status = "❌ (Bandwidth)"
-				hdr = "No"
-			}
-		} else {
-			status = "❌ (No DSC)"
-			hdr = "No"
-		}
 	}
-	return []string{hdmi.Version, mode.GetName(),
-		mode.GetBandwidth().String(), mode.EffectiveBandwidth().String(),
-		fmt.Sprintf("%.1f%%", mode.Usage(m.d.Bandwidth())), hdr, status}
+	return []string{c.Interface.Version, c.Mode.GetName(),
+		c.Mode.GetBandwidth().String(), c.Mode.EffectiveBandwidth().String(),
+		fmt.Sprintf("%.1f%%", c.Usage), hdr, c.Status.String()}
 }
 
 func (m *Model) updateDisplay() {
@@ -651,28 +889,51 @@ func (m Model) getDisplay() (video.Display, error) {
 	if err != nil {
 		return video.Display{}, err
 	}
-	refreshRate, err := strconv.Atoi(m.inputs[2].Value())
+	// An i suffix, as in 50i, makes the display interlaced.
+	refresh, interlaced := strings.CutSuffix(m.inputs[2].Value(), "i")
+	refreshRate, fractional, err := video.ParseRefresh(refresh)
 	if err != nil {
 		return video.Display{}, err
 	}
-	return video.Display{
+	if width <= 0 || height <= 0 {
+		return video.Display{}, fmt.Errorf("width and height must be positive")
+	}
+	if interlaced && height%2 != 0 {
+		return video.Display{}, fmt.Errorf("an interlaced height must be even, got %d", height)
+	}
+	d := video.Display{
 		Width:       width,
 		Height:      height,
 		RefreshRate: refreshRate,
+		Interlaced:  interlaced,
+		Fractional:  fractional,
 		ColorDepth:  m.colorDepthItems[m.colorDepthList.GlobalIndex()].(colorDepthListItem).colorDepth,
+		Encoding:    m.encodingItems[m.encodingList.GlobalIndex()].(encodingListItem).encoding,
 		Timing:      m.timingItems[m.timingList.GlobalIndex()].(timingListItem).timing,
-	}, nil
+	}
+	// Past the blanking limit of the timing, keep the last usable display.
+	if err := video.CheckTiming(d); err != nil {
+		return video.Display{}, err
+	}
+	return d, nil
 }
 
 func (m *Model) applyPreset(p video.Preset) {
 	m.d = p.Display
 	m.inputs[0].SetValue(strconv.Itoa(m.d.Width))
 	m.inputs[1].SetValue(strconv.Itoa(m.d.Height))
-	m.inputs[2].SetValue(strconv.Itoa(m.d.RefreshRate))
+	m.inputs[2].SetValue(refreshValue(m.d))
 	m.colorDepthList.Select(m.getColorDepthIndex(m.d.ColorDepth))
+	m.encodingList.Select(int(m.d.Encoding))
 	m.timingList.Select(m.getTimingIndex(m.d.Timing))
 }
 
+// refreshValue is the refresh rate input of d, with an i suffix when
+// interlaced.
+func refreshValue(d video.Display) string {
+	return video.FormatRefresh(d.RefreshRate, d.Fractional) + video.ScanSuffix(d.Interlaced)
+}
+
 func (m Model) getColorDepthIndex(colorDepth video.ColorDepth) int {
 	for i, item := range m.colorDepthItems {
 		if item.(colorDepthListItem).colorDepth == colorDepth {
@@ -706,6 +967,42 @@ var (
 			Key:   "p",
 			Value: "presets",
 		},
+		{
+			Key:   "g / n",
+			Value: "source / sink",
+		},
+		{
+			Key:   "m",
+			Value: "limits",
+		},
+		{
+			Key:   "x",
+			Value: "export",
+		},
+		{
+			Key:   "ctrl+c",
+			Value: "exit",
+		},
+	}
+	solverKeyBind = []keyBind{
+		{
+			Key:   "esc / m",
+			Value: "close",
+		},
+		{
+			Key:   "ctrl+c",
+			Value: "exit",
+		},
+	}
+	exportKeyBind = []keyBind{
+		{
+			Key:   "← / →",
+			Value: "format",
+		},
+		{
+			Key:   "esc / x",
+			Value: "close",
+		},
 		{
 			Key:   "ctrl+c",
 			Value: "exit",
@@ -734,6 +1031,18 @@ var (
 			Key:   "enter",
 			Value: "apply",
 		},
+		{
+			Key:   "s",
+			Value: "save",
+		},
+		{
+			Key:   "r / d",
+			Value: "rename / delete",
+		},
+		{
+			Key:   "shift+↑ / ↓",
+			Value: "move",
+		},
 		{
 			Key:   "esc / p",
 			Value: "close",
@@ -743,6 +1052,74 @@ var (
 			Value: "exit",
 		},
 	}
+	sourceKeyBind = []keyBind{
+		{
+			Key:   "↑ / ↓",
+			Value: "navigate",
+		},
+		{
+			Key:   "enter",
+			Value: "select",
+		},
+		{
+			Key:   "esc / g",
+			Value: "close",
+		},
+		{
+			Key:   "ctrl+c",
+			Value: "exit",
+		},
+	}
+	sinkKeyBind = []keyBind{
+		{
+			Key:   "↑ / ↓",
+			Value: "navigate",
+		},
+		{
+			Key:   "/",
+			Value: "search",
+		},
+		{
+			Key:   "enter",
+			Value: "select",
+		},
+		{
+			Key:   "esc / n",
+			Value: "close",
+		},
+		{
+			Key:   "ctrl+c",
+			Value: "exit",
+		},
+	}
+	sinkFilterKeyBind = []keyBind{
+		{
+			Key:   "enter",
+			Value: "apply",
+		},
+		{
+			Key:   "esc",
+			Value: "cancel",
+		},
+		{
+			Key:   "ctrl+c",
+			Value: "exit",
+		},
+	}
+	presetEditKeyBind = []keyBind{
+		{
+			Key:   "enter",
+			Value: "confirm",
+		},
+		{
+			Key:   "esc",
+			Value: "cancel",
+		},
+		{
+			Key:   "ctrl+c",
+			Value: "exit",
+		},
+	}
 )
 
 type colorDepthListItem struct {
@@ -755,6 +1132,14 @@ func (i colorDepthListItem) Title() string       { return i.title }
 func (i colorDepthListItem) Description() string { return i.desc }
 func (i colorDepthListItem) FilterValue() string { return i.title }
 
+type encodingListItem struct {
+	encoding video.Encoding
+}
+
+func (i encodingListItem) Title() string       { return i.encoding.String() }
+func (i encodingListItem) Description() string { return i.encoding.String() }
+func (i encodingListItem) FilterValue() string { return i.encoding.String() }
+
 type timingListItem struct {
 	timing video.Timing
 }
@@ -763,42 +1148,23 @@ func (i timingListItem) Title() string       { return i.timing.String() }
 func (i timingListItem) Description() string { return i.timing.String() }
 func (i timingListItem) FilterValue() string { return i.timing.String() }
 
-type displayPortListItem struct {
-	dp video.DisplayPort
+type interfaceListItem struct {
+	iface video.Interface
 }
 
-func (i displayPortListItem) Title() string       { return "DisplayPort" }
-func (i displayPortListItem) Description() string { return i.dp.Version }
-func (i displayPortListItem) FilterValue() string { return i.dp.Version }
-
-type hdmiListItem struct {
-	hdmi video.HDMI
-}
-
-func (i hdmiListItem) Title() string       { return "HDMI" }
-func (i hdmiListItem) Description() string { return i.hdmi.Version }
-func (i hdmiListItem) FilterValue() string { return i.hdmi.Version }
+func (i interfaceListItem) Title() string       { return i.iface.Family }
+func (i interfaceListItem) Description() string { return i.iface.Version }
+func (i interfaceListItem) FilterValue() string { return i.iface.Version }
 
 type presetListItem struct {
 	preset video.Preset
+	// user is the index of the preset in the user preset store, or -1 for
+	// built-in presets.
+	user int
 }
 
 func (i presetListItem) Title() string { return i.preset.Name }
 func (i presetListItem) Description() string {
-	return fmt.Sprintf("%dHz %s", i.preset.Display.RefreshRate, i.preset.Display.ColorDepth.String())
+	return fmt.Sprintf("%s · %sHz %s", i.preset.Category, refreshValue(i.preset.Display), i.preset.Display.ColorDepth.String())
 }
 func (i presetListItem) FilterValue() string { return i.preset.Name }
-
-type byEffectiveBandwidth []video.TransmissionMode
-
-func (b byEffectiveBandwidth) Len() int {
-	return len(b)
-}
-
-func (b byEffectiveBandwidth) Swap(i, j int) {
-	b[i], b[j] = b[j], b[i]
-}
-
-func (b byEffectiveBandwidth) Less(i, j int) bool {
-	return b[i].EffectiveBandwidth().Bits > b[j].EffectiveBandwidth().Bits
-}
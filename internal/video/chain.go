@@ -0,0 +1,325 @@
+package video
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hekmon/cunits/v3"
+)
+
+// Cable carries one interface family up to the link mode it is certified
+// for.
+type Cable struct {
+	Name    string
+	Family  string
+	MaxRate LinkMode
+}
+
+// Adapter sits between two connectors of a chain.
+type Adapter struct {
+	Name   string
+	Input  Port
+	Output Port
+	// Active adapters, such as DisplayPort to HDMI protocol converters
+	// (PCONs), train a link on each side. Passive ones, such as DP++
+	// dongles, have the source drive the output protocol directly.
+	Active bool
+	// MaxTMDSClock caps the TMDS character rate of the output, in Hz; zero
+	// when the output version alone limits it.
+	MaxTMDSClock int
+	// DSC is set when the adapter carries DSC compressed streams.
+	DSC bool
+}
+
+func Cables() []Cable {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return slices.Clone(resolved.cables)
+}
+
+func LookupCable(name string) (Cable, bool) {
+	for _, c := range Cables() {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return Cable{}, false
+}
+
+func Adapters() []Adapter {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return slices.Clone(resolved.adapters)
+}
+
+func LookupAdapter(name string) (Adapter, bool) {
+	for _, a := range Adapters() {
+		if strings.EqualFold(a.Name, name) {
+			return a, true
+		}
+	}
+	return Adapter{}, false
+}
+
+// Hop is a cable or an adapter of a chain; exactly one is set.
+type Hop struct {
+	Cable   *Cable
+	Adapter *Adapter
+}
+
+func (h Hop) Name() string {
+	if h.Cable != nil {
+		return h.Cable.Name
+	}
+	return h.Adapter.Name
+}
+
+// ChainEnd is the source port or the sink input at one end of a chain.
+type ChainEnd struct {
+	Name string
+	Port Port
+	// DSC is set when the device has a DSC encoder, for a source, or a DSC
+	// decoder, for a sink.
+	DSC bool
+}
+
+// Chain is the path a signal takes from a source port to a sink input.
+type Chain struct {
+	Source ChainEnd
+	Hops   []Hop
+	Sink   ChainEnd
+}
+
+// ChainSegment is a stretch of a chain carrying a single link, between the
+// source or an active adapter and the sink or the next active adapter.
+type ChainSegment struct {
+	// Interface is the negotiated version with the modes every part of the
+	// segment carries, without DSC unless all of them support it.
+	Interface Interface
+	// Parts are the names of the ends and hops of the segment, in order.
+	Parts []string
+	// Bottleneck is the part with the slowest fastest mode.
+	Bottleneck string
+	// Bandwidth is the effective bandwidth of the fastest mode, zero when
+	// the parts have no mode in common.
+	Bandwidth cunits.Speed
+	// Compatibility is how the display fits the segment.
+	Compatibility Compatibility
+}
+
+// ChainResult is the outcome of evaluating a display over a chain.
+type ChainResult struct {
+	Segments []ChainSegment
+	// Bottleneck is the part of the slowest segment that limits it.
+	Bottleneck string
+	// DSC is set when every part of the chain carries DSC streams.
+	DSC    bool
+	Status Status
+	// Best is the cheapest change that makes the display survive the whole
+	// chain. It is nil when the display already does or nothing fits.
+	Best *Suggestion
+	// Problems lists the hops that cannot be connected, in which case no
+	// segment is evaluated.
+	Problems []string
+}
+
+// chainPart is what one end or hop of a segment supports.
+type chainPart struct {
+	name string
+	// iface is the version and modes of a connector, nil for a cable.
+	iface *Interface
+	// maxRate is the fastest mode of a cable.
+	maxRate   LinkMode
+	tmdsClock int
+}
+
+// Evaluate splits the chain into segments at active adapters, negotiates
+// the link of each one and checks d against all of them. The slowest
+// segment is the bottleneck of the chain.
+func (c Chain) Evaluate(d Display) ChainResult {
+	var r ChainResult
+	segments, dsc, problems := c.segments()
+	if len(problems) > 0 {
+		r.Problems = problems
+		r.Status = StatusBandwidth
+		return r
+	}
+	r.DSC = dsc
+	slowest := -1
+	for _, parts := range segments {
+		s := resolveChainSegment(parts, dsc)
+		if len(s.Interface.Modes) == 0 {
+			r.Problems = append(r.Problems, fmt.Sprintf("%s have no %s link in common",
+				strings.Join(s.Parts, ", "), s.Interface.Family))
+		} else {
+			s.Compatibility = s.Interface.Check(d, s.Interface.LowestCompatibleMode(d))
+		}
+		r.Segments = append(r.Segments, s)
+		if slowest < 0 || s.Bandwidth.Bits < r.Segments[slowest].Bandwidth.Bits {
+			slowest = len(r.Segments) - 1
+		}
+	}
+	if len(r.Problems) > 0 {
+		r.Status = StatusBandwidth
+		return r
+	}
+	bottleneck := r.Segments[slowest]
+	r.Bottleneck = bottleneck.Bottleneck
+	for _, s := range r.Segments {
+		r.Status = max(r.Status, s.Compatibility.Status)
+	}
+	if r.Status.Fits() {
+		return r
+	}
+	link := bottleneck.Interface
+	link.Modes = sortedModes(link)[len(link.Modes)-1:]
+	for _, s := range Suggest(d, link, 0) {
+		if s.Interface.Version == link.Version {
+			r.Best = &s
+			break
+		}
+	}
+	return r
+}
+
+// segments groups the parts of the chain by link, and reports whether DSC
+// streams cross the whole chain.
+func (c Chain) segments() ([][]chainPart, bool, []string) {
+	var problems []string
+	dsc := c.Source.DSC && c.Sink.DSC
+	source := c.Source.Port.Interface
+	family := source.Family
+	current := []chainPart{{name: c.Source.Name, iface: &source}}
+	var segments [][]chainPart
+	for _, h := range c.Hops {
+		if h.Cable != nil {
+			if h.Cable.Family != family {
+				problems = append(problems, fmt.Sprintf("%s carries %s, the link is %s", h.Cable.Name, h.Cable.Family, family))
+				continue
+			}
+			current = append(current, chainPart{name: h.Cable.Name, maxRate: h.Cable.MaxRate})
+			continue
+		}
+		a := h.Adapter
+		in, out := a.Input.Interface, a.Output.Interface
+		if in.Family != family {
+			problems = append(problems, fmt.Sprintf("%s takes %s, the link is %s", a.Name, in.Family, family))
+			continue
+		}
+		dsc = dsc && a.DSC
+		switch {
+		case a.Active:
+			segments = append(segments, append(current, chainPart{name: a.Name, iface: &in}))
+			current = []chainPart{{name: a.Name, iface: &out, tmdsClock: a.MaxTMDSClock}}
+		case in.Family != out.Family:
+			// The source itself switches to the output protocol, so the
+			// adapter has to be plugged into it.
+			if len(current) > 1 {
+				problems = append(problems, fmt.Sprintf("%s must be plugged into the source", a.Name))
+			}
+			current = []chainPart{{name: c.Source.Name + " via " + a.Name, iface: &out, tmdsClock: a.MaxTMDSClock}}
+		default:
+			current = append(current, chainPart{name: a.Name, iface: &in},
+				chainPart{name: a.Name, iface: &out, tmdsClock: a.MaxTMDSClock})
+		}
+		family = out.Family
+	}
+	sink := c.Sink.Port.Interface
+	if sink.Family != family {
+		problems = append(problems, fmt.Sprintf("%s takes %s, the link is %s", c.Sink.Name, sink.Family, family))
+	}
+	segments = append(segments, append(current, chainPart{name: c.Sink.Name, iface: &sink}))
+	return segments, dsc, problems
+}
+
+// resolveChainSegment negotiates the oldest version of the parts, keeps the
+// modes every part carries and finds the part that limits the segment.
+func resolveChainSegment(parts []chainPart, dsc bool) ChainSegment {
+	var s ChainSegment
+	for _, p := range parts {
+		if len(s.Parts) == 0 || s.Parts[len(s.Parts)-1] != p.name {
+			s.Parts = append(s.Parts, p.name)
+		}
+	}
+	s.Interface = negotiateChainLink(parts, dsc)
+	s.Bandwidth = fastestBandwidth(s.Interface)
+	var slowest cunits.Speed
+	for _, p := range parts {
+		bandwidth := fastestBandwidth(negotiateChainLink([]chainPart{parts[0], p}, dsc))
+		if s.Bottleneck == "" || bandwidth.Bits < slowest.Bits {
+			s.Bottleneck, slowest = p.name, bandwidth
+		}
+	}
+	return s
+}
+
+func negotiateChainLink(parts []chainPart, dsc bool) Interface {
+	family := parts[0].iface.Family
+	full, _ := LookupInterfaceFamily(family)
+	order := func(version string) int {
+		return slices.IndexFunc(full.Versions, func(v Interface) bool { return v.Version == version })
+	}
+	link := Interface{Family: family}
+	oldest := -1
+	for _, p := range parts {
+		if p.iface == nil {
+			continue
+		}
+		if n := order(p.iface.Version); n > oldest {
+			oldest, link = n, full.Versions[n]
+		}
+	}
+	// Links train at the rates of older versions too.
+	link = withFallbackModes(link)
+	link.DSC = link.DSC && dsc
+	for _, p := range parts {
+		limit := p.maxRate.MaxBandwidth
+		if p.iface != nil {
+			link.DSC = link.DSC && p.iface.DSC
+			if len(p.iface.Modes) > 0 {
+				limit = sortedModes(*p.iface)[len(p.iface.Modes)-1].GetBandwidth()
+			}
+		}
+		if limit.Bits > 0 {
+			link.Modes = slices.DeleteFunc(slices.Clone(link.Modes), func(m TransmissionMode) bool {
+				return m.GetBandwidth().Bits > limit.Bits
+			})
+		}
+		if p.tmdsClock > 0 {
+			link.Modes = clampTMDS(link.Modes, p.tmdsClock)
+		}
+	}
+	return link
+}
+
+// clampTMDS replaces the modes faster than a TMDS character rate of clock
+// Hz on three channels of 10 bits by a single mode at that rate, unless a
+// mode already runs at it.
+func clampTMDS(modes []TransmissionMode, clock int) []TransmissionMode {
+	limit := cunits.Speed{Bits: cunits.Bits(clock) * 30}
+	var out []TransmissionMode
+	var clamped TransmissionMode
+	for _, m := range modes {
+		if m.GetBandwidth().Bits <= limit.Bits {
+			out = append(out, m)
+		} else if clamped == nil && !slices.ContainsFunc(out, func(o TransmissionMode) bool { return o.GetBandwidth().Bits == limit.Bits }) {
+			clamped = LinkMode{
+				Name:         fmt.Sprintf("TMDS (%d MHz)", clock/1e6),
+				MaxBandwidth: limit,
+				Efficiency:   float64(m.EffectiveBandwidth().Bits) / float64(m.GetBandwidth().Bits),
+			}
+		}
+	}
+	if clamped != nil {
+		out = append(out, clamped)
+	}
+	return out
+}
+
+func fastestBandwidth(i Interface) cunits.Speed {
+	if len(i.Modes) == 0 {
+		return cunits.Speed{}
+	}
+	return sortedModes(i)[len(i.Modes)-1].EffectiveBandwidth()
+}
@@ -0,0 +1,94 @@
+package video
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testChain(t *testing.T, sink Interface, hops ...string) Chain {
+	t.Helper()
+	c := Chain{
+		Source: ChainEnd{Name: "GPU", Port: Port{Name: "DP-1", Interface: testInterface(t, "DisplayPort", "1.4")}, DSC: true},
+		Sink:   ChainEnd{Name: "TV", Port: Port{Name: "HDMI-1", Interface: sink}, DSC: true},
+	}
+	for _, name := range hops {
+		if cable, ok := LookupCable(name); ok {
+			c.Hops = append(c.Hops, Hop{Cable: &cable})
+		} else if adapter, ok := LookupAdapter(name); ok {
+			c.Hops = append(c.Hops, Hop{Adapter: &adapter})
+		} else {
+			t.Fatalf("no cable or adapter %q", name)
+		}
+	}
+	return c
+}
+
+func TestChainEvaluate(t *testing.T) {
+	timing, _ := LookupTiming("CVT-RB")
+	fhd := Display{Width: 1920, Height: 1080, RefreshRate: 60, ColorDepth: ColorDepth8bit(), Timing: timing}
+	qhd := Display{Width: 2560, Height: 1440, RefreshRate: 60, ColorDepth: ColorDepth8bit(), Timing: timing}
+	hdmi20 := testInterface(t, "HDMI", "2.0")
+
+	tests := []struct {
+		name       string
+		d          Display
+		hops       []string
+		parts      [][]string
+		link       string
+		modes      []string
+		bottleneck string
+		status     Status
+	}{
+		// A DP++ dongle has the source drive HDMI 1.4 itself, capped at the
+		// TMDS clock of the dongle whatever the cable carries.
+		{"type 1 dongle", fhd, []string{"DP++ to HDMI dongle (type 1)", "Premium High Speed HDMI cable"},
+			[][]string{{"GPU via DP++ to HDMI dongle (type 1)", "Premium High Speed HDMI cable", "TV"}},
+			"HDMI 1.4", []string{"TMDS (165 MHz)"}, "GPU via DP++ to HDMI dongle (type 1)", StatusOK},
+		{"type 1 dongle too slow", qhd, []string{"DP++ to HDMI dongle (type 1)", "Premium High Speed HDMI cable"},
+			[][]string{{"GPU via DP++ to HDMI dongle (type 1)", "Premium High Speed HDMI cable", "TV"}},
+			"HDMI 1.4", []string{"TMDS (165 MHz)"}, "GPU via DP++ to HDMI dongle (type 1)", StatusNoDSC},
+		{"type 2 dongle", qhd, []string{"DP++ to HDMI dongle (type 2)", "Premium High Speed HDMI cable"},
+			[][]string{{"GPU via DP++ to HDMI dongle (type 2)", "Premium High Speed HDMI cable", "TV"}},
+			"HDMI 1.4", []string{"TMDS (165 MHz)", "TMDS (300 MHz)"}, "GPU via DP++ to HDMI dongle (type 2)", StatusOK},
+		// An active adapter ends the DisplayPort link and starts an HDMI
+		// one, here limited by the cable.
+		{"active adapter", qhd, []string{"VESA DP8K cable", "DP 1.4 to HDMI 2.0 active adapter", "Standard HDMI cable"},
+			[][]string{{"GPU", "VESA DP8K cable", "DP 1.4 to HDMI 2.0 active adapter"}, {"DP 1.4 to HDMI 2.0 active adapter", "Standard HDMI cable", "TV"}},
+			"HDMI 2.0", []string{"TMDS (165 MHz)"}, "Standard HDMI cable", StatusNoDSC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := testChain(t, hdmi20, tt.hops...).Evaluate(tt.d)
+			if len(r.Problems) > 0 {
+				t.Fatalf("problems = %q", r.Problems)
+			}
+			var parts [][]string
+			for _, s := range r.Segments {
+				parts = append(parts, s.Parts)
+			}
+			if !slices.EqualFunc(parts, tt.parts, slices.Equal) {
+				t.Errorf("segments = %q, want %q", parts, tt.parts)
+			}
+			last := r.Segments[len(r.Segments)-1].Interface
+			var modes []string
+			for _, m := range sortedModes(last) {
+				modes = append(modes, m.GetName())
+			}
+			if last.String() != tt.link || !slices.Equal(modes, tt.modes) {
+				t.Errorf("HDMI link = %s %q, want %s %q", last, modes, tt.link, tt.modes)
+			}
+			if r.Bottleneck != tt.bottleneck || r.Status != tt.status {
+				t.Errorf("bottleneck %q, %s, want %q, %s", r.Bottleneck, r.Status, tt.bottleneck, tt.status)
+			}
+			if !r.Status.Fits() && (r.Best == nil || r.Best.Interface.Version != last.Version) {
+				t.Errorf("best = %+v, want a change on %s", r.Best, last)
+			}
+		})
+	}
+
+	r := testChain(t, hdmi20, "VESA DP8K cable", "DP++ to HDMI dongle (type 2)").Evaluate(fhd)
+	if len(r.Problems) == 0 || !strings.Contains(strings.Join(r.Problems, "\n"), "must be plugged into the source") {
+		t.Errorf("problems of a dongle behind a cable = %q", r.Problems)
+	}
+}
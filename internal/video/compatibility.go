@@ -0,0 +1,83 @@
+package video
+
+import "fmt"
+
+type Status int
+
+const (
+	StatusOK Status = iota
+	StatusDSC
+	StatusBandwidth
+	StatusNoDSC
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusOK:
+		return "✅"
+	case StatusDSC:
+		return "❗ (DSC)"
+	case StatusBandwidth:
+		return "❌ (Bandwidth)"
+	case StatusNoDSC:
+		return "❌ (No DSC)"
+	}
+	return ""
+}
+
+var statusNames = map[Status]string{
+	StatusOK:        "ok",
+	StatusDSC:       "dsc",
+	StatusBandwidth: "bandwidth",
+	StatusNoDSC:     "no_dsc",
+}
+
+func (s Status) MarshalText() ([]byte, error) {
+	name, ok := statusNames[s]
+	if !ok {
+		return nil, fmt.Errorf("invalid status %d", int(s))
+	}
+	return []byte(name), nil
+}
+
+func (s *Status) UnmarshalText(text []byte) error {
+	for status, name := range statusNames {
+		if name == string(text) {
+			*s = status
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid status %q", text)
+}
+
+func (s Status) Fits() bool {
+	return s == StatusOK || s == StatusDSC
+}
+
+type Compatibility struct {
+	Interface Interface
+	Mode      TransmissionMode
+	Usage     float64
+	HDR       bool
+	Status    Status
+}
+
+// Evaluate checks d against every mode of i.
+func (i Interface) Evaluate(d Display) []Compatibility {
+	out := make([]Compatibility, len(i.Modes))
+	for j, mode := range i.Modes {
+		out[j] = i.Check(d, mode)
+	}
+	return out
+}
+
+// Evaluate checks d against the lowest compatible mode of every version of f.
+func (f InterfaceFamily) Evaluate(d Display) []Compatibility {
+	out := make([]Compatibility, 0, len(f.Versions))
+	for _, i := range f.Versions {
+		if mode := i.LowestCompatibleMode(d); mode != nil {
+			out = append(out, i.Check(d, mode))
+		}
+	}
+	return out
+}
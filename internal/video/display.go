@@ -3,37 +3,119 @@ package video
 import (
 	"fmt"
 	"math"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/hekmon/cunits/v3"
 )
 
 type Display struct {
-	Width       int
-	Height      int
+	Width  int
+	Height int
+	// RefreshRate is the field rate of an interlaced display, twice its
+	// frame rate: 50 for 1080i50.
 	RefreshRate int
 	ColorDepth  ColorDepth
+	Encoding    Encoding
 	Timing      Timing
+	// Interlaced displays send every other line of a frame in each field.
+	Interlaced bool
+	// Fractional displays refresh at 1000/1001 of RefreshRate, the NTSC
+	// rates: 59.94 Hz for 60.
+	Fractional bool
 }
 
 func (d Display) String() string {
-	return fmt.Sprintf("%dx%d@%dHz, color depth: %s, timing: %s",
-		d.Width, d.Height, d.RefreshRate, d.ColorDepth, d.Timing.String())
+	if d.Encoding != EncodingRGB {
+		return fmt.Sprintf("%s, color depth: %s, encoding: %s, timing: %s",
+			d.Mode(), d.ColorDepth, d.Encoding, d.Timing.String())
+	}
+	return fmt.Sprintf("%s, color depth: %s, timing: %s",
+		d.Mode(), d.ColorDepth, d.Timing.String())
+}
+
+// Mode is the resolution and refresh rate of d, e.g. 1920x1080i@50Hz or
+// 3840x2160@59.94Hz.
+func (d Display) Mode() string {
+	return fmt.Sprintf("%dx%d%s@%sHz", d.Width, d.Height, ScanSuffix(d.Interlaced), FormatRefresh(d.RefreshRate, d.Fractional))
+}
+
+// ScanSuffix is what follows the resolution in the name of a mode: i for
+// an interlaced one, as in 1920x1080i, and nothing for a progressive one.
+func ScanSuffix(interlaced bool) string {
+	if interlaced {
+		return "i"
+	}
+	return ""
+}
+
+// ParseRefresh parses a positive refresh rate in Hz: a whole one, or
+// 1000/1001 of one to within 0.01 Hz, as 59.94 or 23.976, which is
+// fractional.
+func ParseRefresh(s string) (int, bool, error) {
+	if rate, err := strconv.Atoi(s); err == nil {
+		if rate <= 0 {
+			return 0, false, fmt.Errorf("invalid refresh rate %q: must be positive", s)
+		}
+		return rate, false, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || !(f > 0 && f < math.MaxInt32) {
+		return 0, false, fmt.Errorf("invalid refresh rate %q", s)
+	}
+	if f == math.Trunc(f) {
+		return int(f), false, nil
+	}
+	rate := int(math.Round(f * 1001 / 1000))
+	if math.Abs(float64(rate)*1000/1001-f) > 0.01 {
+		return 0, false, fmt.Errorf("invalid refresh rate %q: neither whole nor 1000/1001 of a whole rate", s)
+	}
+	return rate, true, nil
+}
+
+// FormatRefresh is the refresh rate of a mode as ParseRefresh takes it.
+func FormatRefresh(rate int, fractional bool) string {
+	if !fractional {
+		return strconv.Itoa(rate)
+	}
+	return strconv.FormatFloat(math.Round(float64(rate)*1e6/1001)/1e3, 'f', -1, 64)
 }
 
 func (d Display) FrameSize() int {
 	return d.Width * d.Height
 }
 
+func (d Display) Totals() (int, int) {
+	return d.Timing.Totals(d)
+}
+
 func (d Display) EffectiveFrameSize() int {
 	return d.Timing.EffectiveFrameSize(d)
 }
 
+// EffectivePixelRate is the pixel clock: an interlaced display sends one
+// frame every two fields, and a fractional one keeps the timing of its
+// whole rate with a clock 1000/1001 as fast.
 func (d Display) EffectivePixelRate() int {
-	return d.EffectiveFrameSize() * d.RefreshRate
+	rate := d.EffectiveFrameSize() * d.RefreshRate
+	if d.Interlaced {
+		rate /= 2
+	}
+	if d.Fractional {
+		rate = rate * 1000 / 1001
+	}
+	return rate
+}
+
+// BitsPerPixel is the number of bits sent per pixel once chroma subsampling
+// is applied.
+func (d Display) BitsPerPixel() float64 {
+	return float64(int(d.ColorDepth)*d.Encoding.samples()) / 6
 }
 
 func (d Display) Bandwidth() cunits.Speed {
-	return cunits.Speed{Bits: cunits.Bits(d.EffectivePixelRate() * int(d.ColorDepth))}
+	return cunits.Speed{Bits: cunits.Bits(d.EffectivePixelRate() * int(d.ColorDepth) * d.Encoding.samples() / 6)}
 }
 
 func (d Display) DSC() cunits.Speed {
@@ -56,6 +138,19 @@ func (c ColorDepth) String() string {
 	return ""
 }
 
+func (c ColorDepth) BPC() int {
+	return int(c) / 3
+}
+
+func ColorDepthFromBPC(bpc int) (ColorDepth, error) {
+	for _, c := range ColorDepths() {
+		if c.BPC() == bpc {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported color depth %d bpc", bpc)
+}
+
 func ColorDepths() []ColorDepth {
 	return []ColorDepth{
 		colorDepth8bit,
@@ -90,11 +185,23 @@ const (
 
 type Timing interface {
 	EffectiveFrameSize(d Display) int
+	Totals(d Display) (hTotal, vTotal int)
 	String() string
 }
 
 func Timings() []Timing {
-	return []Timing{cvtrb, cvtrbv2}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return slices.Clone(resolved.timings)
+}
+
+func LookupTiming(name string) (Timing, bool) {
+	for _, t := range Timings() {
+		if strings.EqualFold(t.String(), name) {
+			return t, true
+		}
+	}
+	return nil, false
 }
 
 type CVTRBTiming struct {
@@ -104,34 +211,201 @@ type CVTRBTiming struct {
 }
 
 func (t CVTRBTiming) EffectiveFrameSize(d Display) int {
-	return (d.Height + int(math.Ceil((float64(d.Height)*t.VMin)/(1/float64(d.RefreshRate)-t.VMin)))) * (d.Width + t.HBlank)
+	hTotal, vTotal := t.Totals(d)
+	return hTotal * vTotal
+}
+
+// Totals of an interlaced display are those of a frame: the blanking is
+// computed for each field at the field rate, and the fields are half a
+// line apart, which makes the total odd.
+func (t CVTRBTiming) Totals(d Display) (int, int) {
+	if d.Interlaced {
+		field := d.Height / 2
+		return d.Width + t.HBlank, 2*(field+t.vBlank(field, d.RefreshRate)) + 1
+	}
+	return d.Width + t.HBlank, d.Height + t.vBlank(d.Height, d.RefreshRate)
+}
+
+func (t CVTRBTiming) vBlank(height, refreshRate int) int {
+	return int(math.Ceil((float64(height) * t.VMin) / (1/float64(refreshRate) - t.VMin)))
 }
 
 func (t CVTRBTiming) String() string {
 	return t.Name
 }
 
-func CVTRB() Timing {
-	return cvtrb
+// ExactTiming is the timing of a single mode with known blanking and pixel
+// clock, such as an EDID detailed timing. Other resolutions keep its
+// blanking. The vertical timings of an interlaced mode are those of a
+// frame, as in modelines: the porches and syncs of both fields and the
+// half line between them.
+type ExactTiming struct {
+	Name          string
+	Width         int
+	Height        int
+	HFrontPorch   int
+	HSync         int
+	HBackPorch    int
+	VFrontPorch   int
+	VSync         int
+	VBackPorch    int
+	HSyncPositive bool
+	VSyncPositive bool
+	Interlaced    bool
+	// PixelClock is in Hz.
+	PixelClock int
 }
 
-func CVTRBv2() Timing {
-	return cvtrbv2
+func (t ExactTiming) HTotal() int {
+	return t.Width + t.HFrontPorch + t.HSync + t.HBackPorch
 }
 
-var (
-	cvtrb = CVTRBTiming{
-		Name:   "CVT-RB",
-		VMin:   0.00046,
-		HBlank: 160,
+func (t ExactTiming) VTotal() int {
+	return t.Height + t.VFrontPorch + t.VSync + t.VBackPorch
+}
+
+// RefreshRate is the exact refresh rate in Hz, e.g. 59.94, the field rate
+// when interlaced.
+func (t ExactTiming) RefreshRate() float64 {
+	if t.HTotal() == 0 || t.VTotal() == 0 {
+		return 0
+	}
+	rate := float64(t.PixelClock) / float64(t.HTotal()*t.VTotal())
+	if t.Interlaced {
+		return 2 * rate
 	}
+	return rate
+}
 
-	cvtrbv2 = CVTRBTiming{
-		Name:   "CVT-RBv2",
-		VMin:   0.00046,
-		HBlank: 80,
+// Display returns the mode of t with the given pixel format.
+func (t ExactTiming) Display(colorDepth ColorDepth, encoding Encoding) Display {
+	rate, fractional := t.refresh()
+	return Display{
+		Width:       t.Width,
+		Height:      t.Height,
+		RefreshRate: rate,
+		ColorDepth:  colorDepth,
+		Encoding:    encoding,
+		Timing:      t,
+		Interlaced:  t.Interlaced,
+		Fractional:  fractional,
 	}
-)
+}
+
+// refresh is the refresh rate of t as a Display has it: the whole rate of
+// which it is 1000/1001 and fractional, or else rounded.
+func (t ExactTiming) refresh() (int, bool) {
+	r := t.RefreshRate()
+	if n := math.Round(r * 1001 / 1000); n > 0 && math.Abs(n*1000/1001-r) < 0.005 {
+		return int(n), true
+	}
+	return int(math.Round(r)), false
+}
+
+// matches reports whether d is the mode of t, whose refresh rate is
+// rounded in d.
+func (t ExactTiming) matches(d Display) bool {
+	return d.Width == t.Width && d.Height == t.Height && d.Interlaced == t.Interlaced &&
+		d.RefreshRate > 0 && d.RefreshRate == int(math.Round(t.RefreshRate()))
+}
+
+// EffectiveFrameSize keeps the pixel clock of t for its own mode, whose
+// refresh rate is rounded in d.
+func (t ExactTiming) EffectiveFrameSize(d Display) int {
+	if t.matches(d) {
+		clock, rate := t.PixelClock, d.RefreshRate
+		if d.Interlaced {
+			clock *= 2
+		}
+		if d.Fractional {
+			clock, rate = clock*1001, rate*1000
+		}
+		return (clock + rate/2) / rate
+	}
+	hTotal, vTotal := t.Totals(d)
+	return hTotal * vTotal
+}
+
+// Totals keeps the blanking of t for each field, so that a progressive
+// timing doubles it for an interlaced display and the other way around.
+func (t ExactTiming) Totals(d Display) (int, int) {
+	vBlank := t.VTotal() - t.Height
+	switch {
+	case t.Interlaced && !d.Interlaced:
+		vBlank = (vBlank - 1) / 2
+	case !t.Interlaced && d.Interlaced:
+		vBlank = 2*vBlank + 1
+	}
+	return d.Width + t.HTotal() - t.Width, d.Height + vBlank
+}
+
+func (t ExactTiming) String() string {
+	return t.Name
+}
+
+// ExactTiming returns the exact timing of d: its own when it has one for
+// this mode, or else its totals split into porches and syncs the CVT
+// reduced blanking way, with the pixel clock rounded to the kHz.
+func (d Display) ExactTiming() ExactTiming {
+	if t, ok := d.Timing.(ExactTiming); ok && t.matches(d) {
+		return t
+	}
+	hTotal, vTotal := d.Totals()
+	hBlank, vBlank := hTotal-d.Width, vTotal-d.Height
+	t := ExactTiming{
+		Name:          d.Timing.String(),
+		Width:         d.Width,
+		Height:        d.Height,
+		HSync:         min(32, hBlank),
+		HSyncPositive: true,
+		Interlaced:    d.Interlaced,
+		PixelClock:    (d.EffectivePixelRate() + 500) / 1000 * 1000,
+	}
+	if d.Interlaced {
+		// Split the blanking of one field, the half line aside.
+		vBlank = (vBlank - 1) / 2
+	}
+	// Half the blanking is back porch: 48/32/80 for CVT-RB, 8/32/40 for
+	// CVT-RBv2.
+	t.HBackPorch = min(hBlank/2, hBlank-t.HSync)
+	t.HFrontPorch = hBlank - t.HSync - t.HBackPorch
+	if hBlank <= 80 {
+		// CVT-RBv2: fixed sync and back porch, the front porch takes the
+		// rest.
+		t.VSync, t.VBackPorch = min(8, vBlank), min(6, max(vBlank-8, 0))
+		t.VFrontPorch = vBlank - t.VSync - t.VBackPorch
+	} else {
+		t.VFrontPorch, t.VSync = min(3, vBlank), min(cvtVSync(d.Width, d.Height), max(vBlank-3, 0))
+		t.VBackPorch = vBlank - t.VFrontPorch - t.VSync
+	}
+	if t.VFrontPorch == 0 && t.VBackPorch > 0 {
+		// Drivers reject a sync on the first blank line: take a line of
+		// front porch from the back porch, which the short fields of
+		// interlaced CVT-RBv2 leave empty.
+		t.VFrontPorch, t.VBackPorch = 1, t.VBackPorch-1
+	}
+	if d.Interlaced {
+		// Both fields, with the half line in the back porch.
+		t.VFrontPorch, t.VSync = 2*t.VFrontPorch, 2*t.VSync
+		t.VBackPorch = vTotal - d.Height - t.VFrontPorch - t.VSync
+	}
+	return t
+}
+
+// cvtVSync is the CVT vertical sync width, which encodes the aspect ratio.
+func cvtVSync(width, height int) int {
+	switch {
+	case width*3 == height*4:
+		return 4
+	case width*9 == height*16:
+		return 5
+	case width*10 == height*16:
+		return 6
+	case width*4 == height*5, width*9 == height*15:
+		return 7
+	}
+	return 10
+}
 
 type TransmissionMode interface {
 	GetName() string
@@ -0,0 +1,68 @@
+package video
+
+import "testing"
+
+func TestParseRefresh(t *testing.T) {
+	tests := []struct {
+		in         string
+		rate       int
+		fractional bool
+		out        string
+	}{
+		{"60", 60, false, "60"},
+		{"59.94", 60, true, "59.94"},
+		{"29.97", 30, true, "29.97"},
+		{"23.976", 24, true, "23.976"},
+		{"119.88", 120, true, "119.88"},
+		{"60.0", 60, false, "60"},
+	}
+	for _, tt := range tests {
+		rate, fractional, err := ParseRefresh(tt.in)
+		if err != nil || rate != tt.rate || fractional != tt.fractional {
+			t.Errorf("ParseRefresh(%q) = %d, %t, %v, want %d, %t", tt.in, rate, fractional, err, tt.rate, tt.fractional)
+		}
+		if got := FormatRefresh(rate, fractional); got != tt.out {
+			t.Errorf("FormatRefresh(%d, %t) = %q, want %q", rate, fractional, got, tt.out)
+		}
+	}
+	for _, in := range []string{"0", "-1", "0.0", "59.9", "-59.94", "x", "NaN", "Inf"} {
+		if _, _, err := ParseRefresh(in); err == nil {
+			t.Errorf("ParseRefresh(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestFractionalExactTiming(t *testing.T) {
+	// CTA-861 VIC 16 at 59.94 Hz.
+	timing := ExactTiming{Width: 1920, Height: 1080, HFrontPorch: 88, HSync: 44, HBackPorch: 148,
+		VFrontPorch: 4, VSync: 5, VBackPorch: 36, PixelClock: 148_351_648}
+	d := timing.Display(ColorDepth8bit(), EncodingRGB)
+	if d.RefreshRate != 60 || !d.Fractional || d.Mode() != "1920x1080@59.94Hz" {
+		t.Fatalf("Display = %s, %t, want 1920x1080@59.94Hz", d.Mode(), d.Fractional)
+	}
+	if got := d.EffectivePixelRate(); got != timing.PixelClock {
+		t.Errorf("EffectivePixelRate = %d, want %d", got, timing.PixelClock)
+	}
+
+	timing.PixelClock = 148_500_000
+	if d := timing.Display(ColorDepth8bit(), EncodingRGB); d.RefreshRate != 60 || d.Fractional {
+		t.Errorf("Display at 148.5 MHz = %s, want 1920x1080@60Hz", d.Mode())
+	}
+}
+
+func TestInterlacedExactTimingFrontPorch(t *testing.T) {
+	for _, name := range []string{"CVT-RB", "CVT-RBv2"} {
+		timing, ok := LookupTiming(name)
+		if !ok {
+			t.Fatalf("no %s timing", name)
+		}
+		d := Display{Width: 1920, Height: 1080, RefreshRate: 50, ColorDepth: ColorDepth8bit(), Timing: timing, Interlaced: true}
+		et := d.ExactTiming()
+		if vSyncStart := et.Height + et.VFrontPorch; vSyncStart <= et.Height {
+			t.Errorf("%s: vertical sync starts at %d, want past %d", name, vSyncStart, et.Height)
+		}
+		if hTotal, vTotal := d.Totals(); et.HTotal() != hTotal || et.VTotal() != vTotal {
+			t.Errorf("%s: totals %dx%d, want %dx%d", name, et.HTotal(), et.VTotal(), hTotal, vTotal)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package video
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hekmon/cunits/v3"
+)
+
+// UpstreamLink is how a dock connects to its host.
+type UpstreamLink int
+
+const (
+	// UpstreamDPAltMode carries DisplayPort on 2 or 4 lanes of a USB-C
+	// cable. With 4 lanes, only USB 2 is left for data.
+	UpstreamDPAltMode UpstreamLink = iota
+	// UpstreamThunderbolt4 and UpstreamUSB4 tunnel DisplayPort streams and
+	// USB data through a shared link.
+	UpstreamThunderbolt4
+	UpstreamUSB4
+)
+
+func (l UpstreamLink) String() string {
+	switch l {
+	case UpstreamDPAltMode:
+		return "dp-alt"
+	case UpstreamThunderbolt4:
+		return "thunderbolt4"
+	case UpstreamUSB4:
+		return "usb4"
+	}
+	return ""
+}
+
+func ParseUpstreamLink(s string) (UpstreamLink, error) {
+	for _, l := range []UpstreamLink{UpstreamDPAltMode, UpstreamThunderbolt4, UpstreamUSB4} {
+		if l.String() == s {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown upstream link %q", s)
+}
+
+// Dock is a docking station or hub driving displays from one upstream
+// port.
+type Dock struct {
+	Name     string
+	Upstream UpstreamLink
+	// Interface is the DisplayPort version of the alt mode link or of each
+	// tunnel.
+	Interface Interface
+	// Lanes are the alt mode lane counts the dock can use, ascending.
+	Lanes []int
+	// Bandwidth is the raw bandwidth of a Thunderbolt or USB4 link, shared
+	// by the DisplayPort tunnels and USB data.
+	Bandwidth cunits.Speed
+	// Tunnels is the number of DisplayPort streams the upstream link
+	// carries: one for alt mode.
+	Tunnels int
+	// MSTStreams is the number of displays the MST hub drives from one
+	// stream; 1 without a hub.
+	MSTStreams int
+	Ports      []DockPort
+}
+
+// DockPort is a downstream output, fed by one of the upstream streams.
+type DockPort struct {
+	Port
+	// Tunnel is the index of the upstream stream, from 0.
+	Tunnel int
+}
+
+func Docks() []Dock {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return slices.Clone(resolved.docks)
+}
+
+func LookupDock(name string) (Dock, bool) {
+	for _, d := range Docks() {
+		if strings.EqualFold(d.Name, name) {
+			return d, true
+		}
+	}
+	return Dock{}, false
+}
+
+func (k Dock) Port(name string) (DockPort, bool) {
+	for _, p := range k.Ports {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return DockPort{}, false
+}
+
+// PortSummary counts the downstream ports of each interface.
+func (k Dock) PortSummary() string {
+	ports := make([]Port, len(k.Ports))
+	for i, p := range k.Ports {
+		ports[i] = p.Port
+	}
+	return portSummary(ports)
+}
+
+// DockDisplay is a monitor plugged into a dock port. Modes are the modes
+// it accepts, preferred first.
+type DockDisplay struct {
+	Name  string
+	Port  string
+	Modes []Display
+}
+
+// DockAssignment is the mode a display gets.
+type DockAssignment struct {
+	Display DockDisplay
+	Port    DockPort
+	// Mode is nil when no mode fits.
+	Mode      *Display
+	Bandwidth cunits.Speed
+	Problems  []string
+
+	modeIndex int
+}
+
+// Preferred reports whether the display gets its preferred mode.
+func (a DockAssignment) Preferred() bool {
+	return a.Mode != nil && a.modeIndex == 0
+}
+
+// DockStream is an upstream DisplayPort stream and what it carries.
+type DockStream struct {
+	Link      string
+	Bandwidth cunits.Speed
+	Used      cunits.Speed
+	Displays  int
+}
+
+// DockPlan splits the upstream bandwidth of a dock between displays.
+type DockPlan struct {
+	Dock Dock
+	// Lanes is the alt mode lane count, zero for tunnelled links.
+	Lanes   int
+	Streams []DockStream
+	// USB is the USB data the upstream link has room for: "USB 3" or
+	// "USB 2" in alt mode, the bandwidth left otherwise.
+	USB      string
+	Displays []DockAssignment
+}
+
+// OK reports whether every display gets its preferred mode.
+func (p DockPlan) OK() bool {
+	for _, a := range p.Displays {
+		if !a.Preferred() {
+			return false
+		}
+	}
+	return true
+}
+
+// Plan assigns modes to displays in order, like an MST hub allocating
+// bandwidth on a first come, first served basis: each display gets the
+// first of its modes that its port carries uncompressed and that fits the
+// bandwidth left on its stream. In alt mode, the dock keeps 2 lanes and
+// USB 3 unless 4 lanes give more displays their preferred mode. host, when
+// set, is the host port the dock is plugged into.
+func (k Dock) Plan(displays []DockDisplay, host *Port) DockPlan {
+	link := k.Interface
+	if host != nil {
+		link = olderInterface(link, host.Interface)
+	}
+	if k.Upstream != UpstreamDPAltMode {
+		return k.plan(displays, link, 0)
+	}
+	var best DockPlan
+	for _, lanes := range k.Lanes {
+		if lanes < 2 {
+			continue
+		}
+		p := k.plan(displays, link, lanes)
+		if best.Dock.Name == "" || preferredCount(p) > preferredCount(best) {
+			best = p
+		}
+	}
+	return best
+}
+
+func (k Dock) plan(displays []DockDisplay, link Interface, lanes int) DockPlan {
+	p := DockPlan{Dock: k, Lanes: lanes}
+	if len(link.Modes) == 0 {
+		p.USB = k.usb(lanes, 0)
+		for _, d := range displays {
+			p.Displays = append(p.Displays, DockAssignment{
+				Display:  d,
+				Problems: []string{fmt.Sprintf("the upstream link of %s has no link mode to carry %s", k.Name, d.Name)},
+			})
+		}
+		return p
+	}
+	fastest := sortedModes(link)[len(link.Modes)-1]
+	n := lanes
+	if n == 0 && len(link.Lanes) > 0 {
+		n = link.Lanes[len(link.Lanes)-1]
+	}
+	r := newLinkRate(link, fastest, n)
+	name := link.String() + " " + fastest.GetName()
+	if r.lanes > 0 {
+		name += fmt.Sprintf(" x%d", r.lanes)
+	}
+	for range max(k.Tunnels, 1) {
+		p.Streams = append(p.Streams, DockStream{Link: name, Bandwidth: r.bandwidth})
+	}
+	var used cunits.Bits
+	taken := make(map[string]string)
+	for _, d := range displays {
+		a := DockAssignment{Display: d}
+		port, ok := k.Port(d.Port)
+		if !ok {
+			a.Problems = append(a.Problems, fmt.Sprintf("no port %q on %s", d.Port, k.Name))
+			p.Displays = append(p.Displays, a)
+			continue
+		}
+		a.Port = port
+		if other, ok := taken[strings.ToLower(port.Name)]; ok {
+			a.Problems = append(a.Problems, fmt.Sprintf("port %s is already used by %s", port.Name, other))
+			p.Displays = append(p.Displays, a)
+			continue
+		}
+		taken[strings.ToLower(port.Name)] = d.Name
+		s := &p.Streams[min(port.Tunnel, len(p.Streams)-1)]
+		if s.Displays >= max(k.MSTStreams, 1) {
+			a.Problems = append(a.Problems, fmt.Sprintf("the stream of %s already drives %d displays", port.Name, s.Displays))
+			p.Displays = append(p.Displays, a)
+			continue
+		}
+		for i, m := range d.Modes {
+			bandwidth := m.Bandwidth()
+			if port.Interface.Check(m, port.Interface.LowestCompatibleMode(m)).Status != StatusOK ||
+				s.Used.Bits+bandwidth.Bits > s.Bandwidth.Bits ||
+				(k.Bandwidth.Bits > 0 && used+bandwidth.Bits > k.Bandwidth.Bits) {
+				continue
+			}
+			a.Mode, a.modeIndex = &m, i
+			a.Bandwidth = bandwidth
+			s.Used.Bits += bandwidth.Bits
+			s.Displays++
+			used += bandwidth.Bits
+			break
+		}
+		if a.Mode == nil {
+			a.Problems = append(a.Problems, fmt.Sprintf("no mode of %s fits %s with the bandwidth left", d.Name, port.Name))
+		}
+		p.Displays = append(p.Displays, a)
+	}
+	p.USB = k.usb(lanes, used)
+	return p
+}
+
+// usb describes the USB data left next to displays using used bits of the
+// upstream link.
+func (k Dock) usb(lanes int, used cunits.Bits) string {
+	switch {
+	case k.Upstream != UpstreamDPAltMode:
+		return fmt.Sprintf("%s left", cunits.Speed{Bits: k.Bandwidth.Bits - min(used, k.Bandwidth.Bits)})
+	case lanes >= 4:
+		return "USB 2"
+	default:
+		return "USB 3"
+	}
+}
+
+func preferredCount(p DockPlan) int {
+	n := 0
+	for _, a := range p.Displays {
+		if a.Preferred() {
+			n++
+		}
+	}
+	return n
+}
+
+// olderInterface returns the older of two versions of the same family,
+// without the modes faster than the other one carries.
+func olderInterface(a, b Interface) Interface {
+	if a.Family != b.Family {
+		return a
+	}
+	f, ok := LookupInterfaceFamily(a.Family)
+	if !ok {
+		return a
+	}
+	order := func(i Interface) int {
+		return slices.IndexFunc(f.Versions, func(v Interface) bool { return v.Version == i.Version })
+	}
+	if order(b) > order(a) {
+		a, b = b, a
+	}
+	if len(b.Modes) == 0 {
+		a.Modes = nil
+		return a
+	}
+	limit := sortedModes(b)[len(b.Modes)-1].GetBandwidth()
+	if modes := slices.DeleteFunc(slices.Clone(a.Modes), func(m TransmissionMode) bool {
+		return m.GetBandwidth().Bits > limit.Bits
+	}); len(modes) > 0 {
+		a.Modes = modes
+	}
+	return a
+}
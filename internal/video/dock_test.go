@@ -0,0 +1,43 @@
+package video
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDockPlanWithoutLinkModes(t *testing.T) {
+	dp, ok := LookupInterfaceFamily("DisplayPort")
+	if !ok {
+		t.Fatal("no DisplayPort family")
+	}
+	port := DockPort{Port: Port{Name: "DP1", Interface: dp.Versions[0]}}
+	d := DockDisplay{Name: "desk", Port: "DP1", Modes: []Display{{Width: 1920, Height: 1080, RefreshRate: 60}}}
+
+	tests := []struct {
+		name string
+		dock Dock
+		host *Port
+	}{
+		{
+			name: "dock",
+			dock: Dock{Name: "Empty", Interface: Interface{Family: "DisplayPort", Version: "1.4"}, Lanes: []int{2, 4}, Ports: []DockPort{port}},
+		},
+		{
+			name: "host",
+			dock: Dock{Name: "Hub", Interface: dp.Versions[0], Lanes: []int{2, 4}, Ports: []DockPort{port}},
+			host: &Port{Name: "USB-C", Interface: Interface{Family: "DisplayPort", Version: dp.Versions[0].Version}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.dock.Plan([]DockDisplay{d}, tt.host)
+			if len(p.Displays) != 1 {
+				t.Fatalf("%d displays, want 1", len(p.Displays))
+			}
+			a := p.Displays[0]
+			if a.Mode != nil || len(a.Problems) != 1 || !strings.Contains(a.Problems[0], "no link mode") {
+				t.Fatalf("assignment = %+v, want a link mode problem", a)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package video
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Encoding is the pixel encoding and chroma subsampling of a stream. The
+// zero value is full-resolution RGB.
+type Encoding int
+
+const (
+	EncodingRGB Encoding = iota
+	EncodingYCbCr444
+	EncodingYCbCr422
+	EncodingYCbCr420
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingRGB:
+		return "RGB"
+	case EncodingYCbCr444:
+		return "YCbCr 4:4:4"
+	case EncodingYCbCr422:
+		return "YCbCr 4:2:2"
+	case EncodingYCbCr420:
+		return "YCbCr 4:2:0"
+	}
+	return ""
+}
+
+func Encodings() []Encoding {
+	return []Encoding{EncodingRGB, EncodingYCbCr444, EncodingYCbCr422, EncodingYCbCr420}
+}
+
+// ParseEncoding accepts the String form as well as the short names rgb,
+// 444, 422 and 420.
+func ParseEncoding(s string) (Encoding, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "rgb":
+		return EncodingRGB, nil
+	case "444", "4:4:4", "ycbcr444", "ycbcr 4:4:4":
+		return EncodingYCbCr444, nil
+	case "422", "4:2:2", "ycbcr422", "ycbcr 4:2:2":
+		return EncodingYCbCr422, nil
+	case "420", "4:2:0", "ycbcr420", "ycbcr 4:2:0":
+		return EncodingYCbCr420, nil
+	}
+	return 0, fmt.Errorf("unknown encoding %q", s)
+}
+
+// ShortName is the compact form accepted by ParseEncoding.
+func (e Encoding) ShortName() string {
+	switch e {
+	case EncodingYCbCr444:
+		return "444"
+	case EncodingYCbCr422:
+		return "422"
+	case EncodingYCbCr420:
+		return "420"
+	}
+	return "rgb"
+}
+
+// samples returns the average number of color components sent per pixel,
+// times two to stay integral: 4:2:2 sends two and 4:2:0 one and a half.
+func (e Encoding) samples() int {
+	switch e {
+	case EncodingYCbCr422:
+		return 4
+	case EncodingYCbCr420:
+		return 3
+	}
+	return 6
+}
@@ -0,0 +1,187 @@
+package video
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/hekmon/cunits/v3"
+)
+
+type Interface struct {
+	Family  string
+	Version string
+	DSC     bool
+	HDR     bool
+	// Lanes lists the lane counts a link can be trained at, ascending. Mode
+	// bandwidths are those of the widest one. Empty when the lane count is
+	// fixed by the mode.
+	Lanes    []int
+	Training LinkTraining
+	Modes    []TransmissionMode
+}
+
+func (i Interface) String() string {
+	return fmt.Sprintf("%s %s", i.Family, i.Version)
+}
+
+func (i Interface) CanHDR(colorDepth ColorDepth) bool {
+	if i.HDR {
+		if colorDepth >= colorDepth10bit {
+			return true
+		}
+	}
+	return false
+}
+
+func (i Interface) Check(d Display, mode TransmissionMode) Compatibility {
+	return i.checkBandwidth(d.Bandwidth(), d.ColorDepth, mode)
+}
+
+// checkBandwidth checks a stream of the given bandwidth and color depth
+// against mode.
+func (i Interface) checkBandwidth(bandwidth cunits.Speed, colorDepth ColorDepth, mode TransmissionMode) Compatibility {
+	c := Compatibility{
+		Interface: i,
+		Mode:      mode,
+		Usage:     mode.Usage(bandwidth),
+		HDR:       i.CanHDR(colorDepth),
+	}
+	if mode.EffectiveBandwidth().Bits >= bandwidth.Bits {
+		c.Status = StatusOK
+	} else if !i.DSC {
+		c.Status = StatusNoDSC
+		c.HDR = false
+	} else if mode.MaxCompressedBandwidth(colorDepth).Bits >= bandwidth.Bits {
+		c.Status = StatusDSC
+	} else {
+		c.Status = StatusBandwidth
+		c.HDR = false
+	}
+	return c
+}
+
+func (i Interface) LowestCompatibleMode(d Display) TransmissionMode {
+	return i.lowestCompatibleMode(d.Bandwidth(), d.ColorDepth)
+}
+
+func (i Interface) lowestCompatibleMode(bandwidth cunits.Speed, colorDepth ColorDepth) TransmissionMode {
+	if len(i.Modes) == 0 {
+		return nil
+	}
+	modes := slices.Clone(i.Modes)
+	slices.SortFunc(modes, func(a, b TransmissionMode) int {
+		return cmp.Compare(b.EffectiveBandwidth().Bits, a.EffectiveBandwidth().Bits)
+	})
+	lastMode := modes[0]
+	for _, mode := range modes {
+		if mode.EffectiveBandwidth().Bits >= bandwidth.Bits {
+			lastMode = mode
+			continue
+		} else if lastMode.EffectiveBandwidth().Bits >= bandwidth.Bits {
+			return lastMode
+		} else if mode.MaxCompressedBandwidth(colorDepth).Bits >= bandwidth.Bits {
+			return mode
+		}
+	}
+	return lastMode
+}
+
+type InterfaceFamily struct {
+	Name     string
+	Versions []Interface
+}
+
+var (
+	registryMu sync.RWMutex
+	registered []InterfaceFamily
+)
+
+// RegisterInterfaceFamily adds a connector family defined in Go. Families
+// loaded from spec files are listed first, followed by registered ones.
+func RegisterInterfaceFamily(f InterfaceFamily) error {
+	if f.Name == "" {
+		return fmt.Errorf("interface family name is required")
+	}
+	if len(f.Versions) == 0 {
+		return fmt.Errorf("interface family %q has no versions", f.Name)
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, r := range slices.Concat(resolved.families, registered) {
+		if r.Name == f.Name {
+			return fmt.Errorf("interface family %q already registered", f.Name)
+		}
+	}
+	registered = append(registered, withFamilyName(f))
+	return nil
+}
+
+func InterfaceFamilies() []InterfaceFamily {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return slices.Concat(resolved.families, registered)
+}
+
+func LookupInterfaceFamily(name string) (InterfaceFamily, bool) {
+	for _, f := range InterfaceFamilies() {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return InterfaceFamily{}, false
+}
+
+func LinkModes() []LinkMode {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return slices.Clone(resolved.modes)
+}
+
+func (f InterfaceFamily) Version(version string) (Interface, bool) {
+	for _, i := range f.Versions {
+		if i.Version == version {
+			return i, true
+		}
+	}
+	return Interface{}, false
+}
+
+func withFamilyName(f InterfaceFamily) InterfaceFamily {
+	versions := make([]Interface, len(f.Versions))
+	for i, v := range f.Versions {
+		v.Family = f.Name
+		versions[i] = v
+	}
+	f.Versions = versions
+	return f
+}
+
+var _ TransmissionMode = LinkMode{}
+
+type LinkMode struct {
+	Name         string
+	MaxBandwidth cunits.Speed
+	Efficiency   float64
+}
+
+func (m LinkMode) GetName() string {
+	return m.Name
+}
+
+func (m LinkMode) GetBandwidth() cunits.Speed {
+	return m.MaxBandwidth
+}
+
+func (m LinkMode) EffectiveBandwidth() cunits.Speed {
+	return cunits.Speed{Bits: cunits.Bits(float64(m.MaxBandwidth.Bits) * m.Efficiency)}
+}
+
+func (m LinkMode) MaxCompressedBandwidth(colorDepth ColorDepth) cunits.Speed {
+	return cunits.Speed{Bits: m.EffectiveBandwidth().Bits * cunits.Bits(float64(colorDepth)/8)}
+}
+
+func (m LinkMode) Usage(bandwidth cunits.Speed) float64 {
+	return float64(bandwidth.Bits*100) / float64(m.EffectiveBandwidth().Bits)
+}
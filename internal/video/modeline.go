@@ -0,0 +1,104 @@
+package video
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ModelineName is the name gvbc gives the modeline of d, e.g.
+// "3840x2160_144", "1920x1080i_50" when interlaced or "1920x1080_59.94"
+// when fractional.
+func (d Display) ModelineName() string {
+	return fmt.Sprintf("%dx%d%s_%s", d.Width, d.Height, ScanSuffix(d.Interlaced), FormatRefresh(d.RefreshRate, d.Fractional))
+}
+
+// Modeline is the X11 modeline of d, with its exact timing.
+func (d Display) Modeline() string {
+	return d.ExactTiming().Modeline(d.ModelineName())
+}
+
+// Modeline is the X11 modeline of t, as xorg.conf and xrandr --newmode
+// take it.
+func (t ExactTiming) Modeline(name string) string {
+	hSync, vSync := "-hsync", "-vsync"
+	if t.HSyncPositive {
+		hSync = "+hsync"
+	}
+	if t.VSyncPositive {
+		vSync = "+vsync"
+	}
+	hSyncStart := t.Width + t.HFrontPorch
+	vSyncStart := t.Height + t.VFrontPorch
+	line := fmt.Sprintf("Modeline %q %s %d %d %d %d %d %d %d %d %s %s", name,
+		strconv.FormatFloat(float64(t.PixelClock)/1e6, 'f', -1, 64),
+		t.Width, hSyncStart, hSyncStart+t.HSync, t.HTotal(),
+		t.Height, vSyncStart, vSyncStart+t.VSync, t.VTotal(), hSync, vSync)
+	if t.Interlaced {
+		line += " Interlace"
+	}
+	return line
+}
+
+// ParseModeline parses an X11 modeline, with or without the Modeline
+// keyword and the name, as pasted from xorg.conf, cvt or xrandr --newmode.
+// It returns the timing, named "Modeline", and the name of the modeline.
+func ParseModeline(s string) (ExactTiming, string, error) {
+	fields := strings.Fields(s)
+	if len(fields) > 0 && strings.EqualFold(fields[0], "modeline") {
+		fields = fields[1:]
+	}
+	var name string
+	if len(fields) > 0 {
+		if _, err := strconv.ParseFloat(fields[0], 64); err != nil {
+			name, fields = strings.Trim(fields[0], `"`), fields[1:]
+		}
+	}
+	if len(fields) < 9 {
+		return ExactTiming{}, "", fmt.Errorf("modeline %q: want a pixel clock and 8 timings", s)
+	}
+	clock, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || clock <= 0 {
+		return ExactTiming{}, "", fmt.Errorf("modeline %q: invalid pixel clock %q", s, fields[0])
+	}
+	var v [8]int
+	for i := range v {
+		if v[i], err = strconv.Atoi(fields[1+i]); err != nil {
+			return ExactTiming{}, "", fmt.Errorf("modeline %q: invalid timing %q", s, fields[1+i])
+		}
+	}
+	// Each of active, sync start, sync end and total is at least the one
+	// before.
+	if v[0] <= 0 || v[4] <= 0 || v[1] < v[0] || v[2] < v[1] || v[3] < v[2] || v[5] < v[4] || v[6] < v[5] || v[7] < v[6] {
+		return ExactTiming{}, "", fmt.Errorf("modeline %q: timings out of order", s)
+	}
+	t := ExactTiming{
+		Name:        "Modeline",
+		Width:       v[0],
+		Height:      v[4],
+		HFrontPorch: v[1] - v[0],
+		HSync:       v[2] - v[1],
+		HBackPorch:  v[3] - v[2],
+		VFrontPorch: v[5] - v[4],
+		VSync:       v[6] - v[5],
+		VBackPorch:  v[7] - v[6],
+		PixelClock:  int(math.Round(clock * 1e6)),
+	}
+	for _, flag := range fields[9:] {
+		switch strings.ToLower(flag) {
+		case "+hsync":
+			t.HSyncPositive = true
+		case "+vsync":
+			t.VSyncPositive = true
+		case "-hsync", "-vsync":
+		case "interlace":
+			t.Interlaced = true
+		case "doublescan":
+			return ExactTiming{}, "", fmt.Errorf("modeline %q: %s modes are not supported", s, strings.ToLower(flag))
+		default:
+			return ExactTiming{}, "", fmt.Errorf("modeline %q: unknown flag %q", s, flag)
+		}
+	}
+	return t, name, nil
+}
@@ -0,0 +1,60 @@
+package video
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestModelineRoundTrip(t *testing.T) {
+	timing, ok := LookupTiming("CVT-RBv2")
+	if !ok {
+		t.Fatal("no CVT-RBv2 timing")
+	}
+	displays := []Display{
+		{Width: 3840, Height: 2160, RefreshRate: 144, ColorDepth: ColorDepth10bit(), Timing: timing},
+		{Width: 1920, Height: 1080, RefreshRate: 50, ColorDepth: ColorDepth8bit(), Timing: timing, Interlaced: true},
+		{Width: 1920, Height: 1080, RefreshRate: 60, ColorDepth: ColorDepth8bit(), Timing: timing, Fractional: true},
+	}
+	for _, d := range displays {
+		line := d.Modeline()
+		got, name, err := ParseModeline(line)
+		if err != nil {
+			t.Fatalf("ParseModeline(%q): %v", line, err)
+		}
+		want := d.ExactTiming()
+		want.Name = "Modeline"
+		if got != want || name != d.ModelineName() {
+			t.Errorf("ParseModeline(%q) = %+v, %q, want %+v, %q", line, got, name, want, d.ModelineName())
+		}
+	}
+}
+
+func TestParseModeline(t *testing.T) {
+	const cvt = `Modeline "1920x1080_60.00"  173.00  1920 2048 2248 2576  1080 1083 1088 1120 -hsync +vsync`
+	got, name, err := ParseModeline(cvt)
+	if err != nil {
+		t.Fatalf("ParseModeline: %v", err)
+	}
+	want := ExactTiming{Name: "Modeline", Width: 1920, Height: 1080, HFrontPorch: 128, HSync: 200, HBackPorch: 328,
+		VFrontPorch: 3, VSync: 5, VBackPorch: 32, VSyncPositive: true, PixelClock: 173_000_000}
+	if got != want || name != "1920x1080_60.00" {
+		t.Errorf("ParseModeline = %+v, %q, want %+v", got, name, want)
+	}
+	// xrandr --newmode takes the modeline without its keyword nor name.
+	if got, name, err := ParseModeline("173.00 1920 2048 2248 2576 1080 1083 1088 1120 -hsync +vsync"); err != nil || got != want || name != "" {
+		t.Errorf("ParseModeline without a name = %+v, %q, %v", got, name, err)
+	}
+
+	for line, msg := range map[string]string{
+		"Modeline \"short\" 173.00 1920 2048":                       "want a pixel clock and 8 timings",
+		"0 1920 2048 2248 2576 1080 1083 1088 1120":                 "invalid pixel clock",
+		"173.00 1920 2048 2248 2576 1080 1083 1088 x":               "invalid timing",
+		"173.00 1920 1900 2248 2576 1080 1083 1088 1120":            "out of order",
+		"173.00 1920 2048 2248 2576 1080 1083 1088 1120 DoubleScan": "not supported",
+		"173.00 1920 2048 2248 2576 1080 1083 1088 1120 +csync":     "unknown flag",
+	} {
+		if _, _, err := ParseModeline(line); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("ParseModeline(%q) error = %v, want %q", line, err, msg)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package video
+
+// ModeCheck is how an advertised mode fits one interface.
+type ModeCheck struct {
+	Interface     Interface
+	Compatibility Compatibility
+	// ColorDepth is the deepest lower color depth that fits uncompressed
+	// when d does not; zero when d fits uncompressed or none does.
+	ColorDepth ColorDepth
+}
+
+// NeedsDSC reports whether the mode only fits compressed.
+func (c ModeCheck) NeedsDSC() bool {
+	return c.Compatibility.Status == StatusDSC
+}
+
+// CheckMode checks d against the lowest compatible mode of i, and looks
+// for a lower color depth that avoids DSC or makes d fit at all.
+func CheckMode(d Display, i Interface) ModeCheck {
+	c := ModeCheck{Interface: i}
+	mode := i.LowestCompatibleMode(d)
+	if mode == nil {
+		c.Compatibility = Compatibility{Interface: i, Status: StatusBandwidth}
+		return c
+	}
+	c.Compatibility = i.Check(d, mode)
+	if c.Compatibility.Status == StatusOK {
+		return c
+	}
+	depths := ColorDepths()
+	for j := len(depths) - 1; j >= 0; j-- {
+		lower := d
+		lower.ColorDepth = depths[j]
+		if lower.ColorDepth >= d.ColorDepth {
+			continue
+		}
+		if m := i.LowestCompatibleMode(lower); m != nil && i.Check(lower, m).Status == StatusOK {
+			c.ColorDepth = lower.ColorDepth
+			break
+		}
+	}
+	return c
+}
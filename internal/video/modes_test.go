@@ -0,0 +1,38 @@
+package video
+
+import "testing"
+
+func TestCheckMode(t *testing.T) {
+	tests := []struct {
+		name            string
+		family, version string
+		d               Display
+		mode            string
+		status          Status
+		// bpc is the lower color depth that fits uncompressed, zero for
+		// none.
+		bpc int
+	}{
+		{"lowest mode", "DisplayPort", "1.0", testDisplay(t, 1920, 1080, 60, ColorDepth8bit()), "RBR", StatusOK, 0},
+		{"fits", "DisplayPort", "1.4", testDisplay(t, 3840, 2160, 60, ColorDepth10bit()), "HBR3", StatusOK, 0},
+		{"dsc or 8 bpc", "DisplayPort", "1.4", testDisplay(t, 3840, 2160, 120, ColorDepth10bit()), "HBR3", StatusDSC, 8},
+		{"dsc only", "DisplayPort", "1.4", testDisplay(t, 3840, 2160, 144, ColorDepth10bit()), "HBR3", StatusDSC, 0},
+		{"8 bpc without dsc", "HDMI", "2.0", testDisplay(t, 3840, 2160, 60, ColorDepth10bit()), "TMDS (600 MHz)", StatusNoDSC, 8},
+		{"nothing", "DisplayPort", "1.2", testDisplay(t, 3840, 2160, 144, ColorDepth10bit()), "HBR2", StatusNoDSC, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := CheckMode(tt.d, testInterface(t, tt.family, tt.version))
+			bpc := 0
+			if c.ColorDepth != 0 {
+				bpc = c.ColorDepth.BPC()
+			}
+			if c.Compatibility.Mode == nil || c.Compatibility.Mode.GetName() != tt.mode || c.Compatibility.Status != tt.status || bpc != tt.bpc {
+				t.Errorf("check = %v, %s, %d bpc, want %s, %s, %d bpc", c.Compatibility.Mode, c.Compatibility.Status, bpc, tt.mode, tt.status, tt.bpc)
+			}
+			if c.NeedsDSC() != (tt.status == StatusDSC) {
+				t.Errorf("NeedsDSC = %t", c.NeedsDSC())
+			}
+		})
+	}
+}
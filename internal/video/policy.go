@@ -0,0 +1,401 @@
+package video
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hekmon/cunits/v3"
+)
+
+// minDSCBitsPerPixel is the lowest compressed bits per pixel a DSC encoder
+// is asked for.
+const minDSCBitsPerPixel = 8
+
+// LinkOrder is the order in which a driver tries link configurations.
+type LinkOrder int
+
+const (
+	// LinkOrderSlowWide tries the lowest link rate first, with as many
+	// lanes as possible.
+	LinkOrderSlowWide LinkOrder = iota
+	// LinkOrderFastNarrow is the reverse: the highest link rate first,
+	// with as few lanes as possible.
+	LinkOrderFastNarrow
+)
+
+func (o LinkOrder) String() string {
+	switch o {
+	case LinkOrderSlowWide:
+		return "slow-wide"
+	case LinkOrderFastNarrow:
+		return "fast-narrow"
+	}
+	return ""
+}
+
+func ParseLinkOrder(s string) (LinkOrder, error) {
+	switch s {
+	case "slow-wide":
+		return LinkOrderSlowWide, nil
+	case "fast-narrow":
+		return LinkOrderFastNarrow, nil
+	case "":
+		return 0, fmt.Errorf("link_order is required")
+	}
+	return 0, fmt.Errorf("unknown link order %q", s)
+}
+
+// Fallback is a compromise a driver makes when the requested format does
+// not fit any link configuration.
+type Fallback int
+
+const (
+	FallbackBPC Fallback = iota
+	FallbackYCbCr420
+	FallbackDSC
+)
+
+func (f Fallback) String() string {
+	switch f {
+	case FallbackBPC:
+		return "bpc"
+	case FallbackYCbCr420:
+		return "ycbcr420"
+	case FallbackDSC:
+		return "dsc"
+	}
+	return ""
+}
+
+func ParseFallback(s string) (Fallback, error) {
+	for _, f := range []Fallback{FallbackBPC, FallbackYCbCr420, FallbackDSC} {
+		if f.String() == s {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown fallback %q", s)
+}
+
+// Policy emulates how a driver picks a link configuration.
+type Policy struct {
+	Name        string
+	Description string
+	LinkOrder   LinkOrder
+	// Fallbacks are tried in order: every setting of an earlier fallback
+	// is exhausted before the next one is made. Fallbacks not listed are
+	// never made.
+	Fallbacks []Fallback
+}
+
+func Policies() []Policy {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return slices.Clone(resolved.policies)
+}
+
+func LookupPolicy(name string) (Policy, bool) {
+	for _, p := range Policies() {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return Policy{}, false
+}
+
+// LinkCaps are the link capabilities of a source or a sink.
+type LinkCaps struct {
+	// MaxRate is the name of the fastest link mode, empty for any.
+	MaxRate string
+	// MaxLanes is zero for any lane count.
+	MaxLanes int
+	// MaxBPC is zero for any color depth.
+	MaxBPC   int
+	DSC      bool
+	YCbCr420 bool
+}
+
+// AnyLinkCaps does not restrict the link.
+func AnyLinkCaps() LinkCaps {
+	return LinkCaps{DSC: true, YCbCr420: true}
+}
+
+// ParseLinkCaps parses a comma separated list of rate=MODE, lanes=N,
+// bpc=N, dsc=yes|no and 420=yes|no on top of AnyLinkCaps.
+func ParseLinkCaps(s string) (LinkCaps, error) {
+	c := AnyLinkCaps()
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return LinkCaps{}, fmt.Errorf("invalid link capability %q (want key=value)", field)
+		}
+		var err error
+		switch strings.ToLower(key) {
+		case "rate":
+			if !slices.ContainsFunc(LinkModes(), func(m LinkMode) bool { return m.Name == value }) {
+				return LinkCaps{}, fmt.Errorf("unknown link mode %q", value)
+			}
+			c.MaxRate = value
+		case "lanes":
+			c.MaxLanes, err = strconv.Atoi(value)
+			if err == nil && c.MaxLanes <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "bpc":
+			c.MaxBPC, err = strconv.Atoi(value)
+			if err == nil {
+				_, err = ColorDepthFromBPC(c.MaxBPC)
+			}
+		case "dsc":
+			c.DSC, err = parseYesNo(value)
+		case "420":
+			c.YCbCr420, err = parseYesNo(value)
+		default:
+			return LinkCaps{}, fmt.Errorf("unknown link capability %q", key)
+		}
+		if err != nil {
+			return LinkCaps{}, fmt.Errorf("link capability %s: %v", key, err)
+		}
+	}
+	return c, nil
+}
+
+func parseYesNo(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "true", "1":
+		return true, nil
+	case "no", "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid value %q (want yes or no)", s)
+}
+
+// LinkConfig is the link configuration a policy negotiated.
+type LinkConfig struct {
+	Policy    Policy
+	Interface Interface
+	Mode      TransmissionMode
+	// Lanes is zero when the lane count is fixed by the mode.
+	Lanes int
+	// Bandwidth is the effective bandwidth of Mode over Lanes.
+	Bandwidth cunits.Speed
+	// Display is the requested display with the negotiated color depth and
+	// encoding.
+	Display Display
+	// DSCBitsPerPixel is the compressed bits per pixel, zero without DSC.
+	DSCBitsPerPixel float64
+}
+
+func (c LinkConfig) LinkName() string {
+	if c.Lanes == 0 {
+		return c.Mode.GetName()
+	}
+	return fmt.Sprintf("%s x%d", c.Mode.GetName(), c.Lanes)
+}
+
+// Usage is the share of the link bandwidth the stream uses.
+func (c LinkConfig) Usage() float64 {
+	used := float64(c.Display.Bandwidth().Bits)
+	if c.DSCBitsPerPixel > 0 {
+		used = float64(c.Display.EffectivePixelRate()) * c.DSCBitsPerPixel
+	}
+	return used * 100 / float64(c.Bandwidth.Bits)
+}
+
+type linkFormat struct {
+	colorDepth ColorDepth
+	encoding   Encoding
+	dsc        bool
+	// levels of the bpc, ycbcr420 and dsc fallbacks, indexed by Fallback.
+	levels [3]int
+}
+
+type linkRate struct {
+	mode      TransmissionMode
+	lanes     int
+	bandwidth cunits.Speed
+}
+
+// Negotiate predicts the link configuration the policy picks to drive d
+// over i between source and sink. ok is false when no configuration fits.
+func (p Policy) Negotiate(d Display, i Interface, source, sink LinkCaps) (LinkConfig, bool) {
+	rates := p.linkRates(i, source, sink)
+	for _, f := range p.formats(d, i, source, sink) {
+		candidate := d
+		candidate.ColorDepth, candidate.Encoding = f.colorDepth, f.encoding
+		if !validTiming(candidate) {
+			continue
+		}
+		tried := rates
+		if f.dsc && len(rates) > 0 {
+			// Drivers train the fastest link before enabling DSC and pick
+			// the highest compressed bpp it carries.
+			tried = []linkRate{slices.MaxFunc(rates, func(a, b linkRate) int {
+				return cmp.Compare(a.bandwidth.Bits, b.bandwidth.Bits)
+			})}
+		}
+		for _, r := range tried {
+			bpp, ok := fitsLink(candidate, r.bandwidth, f.dsc)
+			if !ok {
+				continue
+			}
+			return LinkConfig{
+				Policy:          p,
+				Interface:       i,
+				Mode:            r.mode,
+				Lanes:           r.lanes,
+				Bandwidth:       r.bandwidth,
+				Display:         candidate,
+				DSCBitsPerPixel: bpp,
+			}, true
+		}
+	}
+	return LinkConfig{}, false
+}
+
+// fitsLink reports whether d fits in bandwidth, and the compressed bits
+// per pixel when dsc is set.
+func fitsLink(d Display, bandwidth cunits.Speed, dsc bool) (float64, bool) {
+	if !dsc {
+		return 0, d.Bandwidth().Bits <= bandwidth.Bits
+	}
+	return dscBitsPerPixel(d, bandwidth)
+}
+
+// dscBitsPerPixel is the compressed bits per pixel of d in bandwidth, in
+// the 1/16 steps of DSC and below the uncompressed bits per pixel. ok is
+// false below the lowest rate an encoder reaches.
+func dscBitsPerPixel(d Display, bandwidth cunits.Speed) (float64, bool) {
+	bpp := math.Floor(float64(bandwidth.Bits)/float64(d.EffectivePixelRate())*16) / 16
+	bpp = math.Min(bpp, d.BitsPerPixel()-1.0/16)
+	return bpp, bpp >= minDSCBitsPerPixel
+}
+
+// formats lists the color depth, encoding and DSC combinations in the order
+// the policy tries them.
+func (p Policy) formats(d Display, i Interface, source, sink LinkCaps) []linkFormat {
+	// Drivers clamp the requested color depth to what both ends support
+	// before any fallback.
+	var depths []ColorDepth
+	for _, c := range slices.Backward(ColorDepths()) {
+		if c > d.ColorDepth || (source.MaxBPC > 0 && c.BPC() > source.MaxBPC) || (sink.MaxBPC > 0 && c.BPC() > sink.MaxBPC) {
+			continue
+		}
+		depths = append(depths, c)
+		if !slices.Contains(p.Fallbacks, FallbackBPC) {
+			break
+		}
+	}
+	encodings := []Encoding{d.Encoding}
+	if slices.Contains(p.Fallbacks, FallbackYCbCr420) && source.YCbCr420 && sink.YCbCr420 && d.Encoding != EncodingYCbCr420 {
+		encodings = append(encodings, EncodingYCbCr420)
+	}
+	dscs := []bool{false}
+	if slices.Contains(p.Fallbacks, FallbackDSC) && i.DSC && source.DSC && sink.DSC {
+		dscs = append(dscs, true)
+	}
+
+	var out []linkFormat
+	for ci, c := range depths {
+		for ei, e := range encodings {
+			for di, dsc := range dscs {
+				out = append(out, linkFormat{
+					colorDepth: c,
+					encoding:   e,
+					dsc:        dsc,
+					levels:     [3]int{FallbackBPC: ci, FallbackYCbCr420: ei, FallbackDSC: di},
+				})
+			}
+		}
+	}
+	// The last fallback is the most significant: all settings of the
+	// earlier ones are tried before it is made.
+	slices.SortStableFunc(out, func(a, b linkFormat) int {
+		for _, f := range slices.Backward(p.Fallbacks) {
+			if c := cmp.Compare(a.levels[f], b.levels[f]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return out
+}
+
+// linkRates lists the link rate and lane count combinations both ends
+// support, in the order the policy tries them.
+func (p Policy) linkRates(i Interface, source, sink LinkCaps) []linkRate {
+	modes, lanes := commonLink(i, source, sink)
+	var out []linkRate
+	switch p.LinkOrder {
+	case LinkOrderFastNarrow:
+		for _, n := range lanes {
+			for _, m := range slices.Backward(modes) {
+				out = append(out, newLinkRate(i, m, n))
+			}
+		}
+	default:
+		for _, m := range modes {
+			for _, n := range slices.Backward(lanes) {
+				out = append(out, newLinkRate(i, m, n))
+			}
+		}
+	}
+	return out
+}
+
+// commonLink returns the modes of i, slowest first, and the lane counts,
+// fewest first, that every end supports. The only lane count is zero when
+// it is fixed by the mode.
+func commonLink(i Interface, caps ...LinkCaps) ([]TransmissionMode, []int) {
+	modes := sortedModes(i)
+	for _, c := range caps {
+		if c.MaxRate == "" {
+			continue
+		}
+		max, ok := LookupLinkMode(c.MaxRate)
+		if !ok {
+			continue
+		}
+		modes = slices.DeleteFunc(modes, func(m TransmissionMode) bool {
+			return m.GetBandwidth().Bits > max.MaxBandwidth.Bits
+		})
+	}
+	if len(i.Lanes) == 0 {
+		return modes, []int{0}
+	}
+	lanes := slices.DeleteFunc(slices.Clone(i.Lanes), func(n int) bool {
+		for _, c := range caps {
+			if c.MaxLanes > 0 && n > c.MaxLanes {
+				return true
+			}
+		}
+		return false
+	})
+	return modes, lanes
+}
+
+func newLinkRate(i Interface, m TransmissionMode, lanes int) linkRate {
+	bandwidth := m.EffectiveBandwidth()
+	if lanes > 0 {
+		bandwidth.Bits = bandwidth.Bits * cunits.Bits(lanes) / cunits.Bits(i.Lanes[len(i.Lanes)-1])
+	}
+	return linkRate{mode: m, lanes: lanes, bandwidth: bandwidth}
+}
+
+// LookupLinkMode returns the link mode of the active spec named name, e.g.
+// HBR3.
+func LookupLinkMode(name string) (LinkMode, bool) {
+	for _, m := range LinkModes() {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return LinkMode{}, false
+}
@@ -0,0 +1,84 @@
+package video
+
+import "testing"
+
+func testInterface(t *testing.T, family, version string) Interface {
+	t.Helper()
+	f, ok := LookupInterfaceFamily(family)
+	if !ok {
+		t.Fatalf("no %s family", family)
+	}
+	i, ok := f.Version(version)
+	if !ok {
+		t.Fatalf("no %s %s", family, version)
+	}
+	return i
+}
+
+func testDisplay(t *testing.T, width, height, refresh int, colorDepth ColorDepth) Display {
+	t.Helper()
+	timing, ok := LookupTiming("CVT-RBv2")
+	if !ok {
+		t.Fatal("no CVT-RBv2 timing")
+	}
+	return Display{Width: width, Height: height, RefreshRate: refresh, ColorDepth: colorDepth, Timing: timing}
+}
+
+func TestNegotiate(t *testing.T) {
+	uhd60 := testDisplay(t, 3840, 2160, 60, ColorDepth10bit())
+	uhd120 := testDisplay(t, 3840, 2160, 120, ColorDepth10bit())
+	uhd144 := testDisplay(t, 3840, 2160, 144, ColorDepth10bit())
+	noDSC := AnyLinkCaps()
+	noDSC.DSC = false
+
+	tests := []struct {
+		name     string
+		policy   string
+		d        Display
+		version  string
+		sink     LinkCaps
+		link     string
+		bpc      int
+		encoding Encoding
+		dsc      bool
+	}{
+		// slow-wide settles on the lowest rate at full width, fast-narrow
+		// on the highest rate at the fewest lanes.
+		{"slowest rate", "generic", uhd60, "2.x", AnyLinkCaps(), "UHBR10 x4", 10, EncodingRGB, false},
+		{"fewest lanes", "amdgpu", uhd60, "2.x", AnyLinkCaps(), "UHBR20 x1", 10, EncodingRGB, false},
+		// i915 gives up bpc, then full chroma, before DSC.
+		{"i915 bpc", "i915", uhd120, "1.4", LinkCaps{DSC: true}, "HBR3 x4", 8, EncodingRGB, false},
+		{"i915 420", "i915", uhd144, "1.4", AnyLinkCaps(), "HBR3 x4", 10, EncodingYCbCr420, false},
+		{"i915 dsc", "i915", uhd144, "1.4", LinkCaps{DSC: true}, "HBR3 x4", 10, EncodingRGB, true},
+		// amdgpu enables DSC at the requested bpc, and falls back to 4:2:0
+		// last when the sink has no DSC.
+		{"amdgpu dsc", "amdgpu", uhd144, "1.4", AnyLinkCaps(), "HBR3 x4", 10, EncodingRGB, true},
+		{"amdgpu 420", "amdgpu", uhd144, "1.4", noDSC, "HBR3 x4", 10, EncodingYCbCr420, false},
+		{"sink lanes", "generic", uhd60, "1.4", LinkCaps{MaxLanes: 2, DSC: true}, "HBR3 x2", 10, EncodingRGB, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := LookupPolicy(tt.policy)
+			if !ok {
+				t.Fatalf("no %s policy", tt.policy)
+			}
+			c, ok := p.Negotiate(tt.d, testInterface(t, "DisplayPort", tt.version), AnyLinkCaps(), tt.sink)
+			if !ok {
+				t.Fatal("no link configuration")
+			}
+			if c.LinkName() != tt.link || c.Display.ColorDepth.BPC() != tt.bpc || c.Display.Encoding != tt.encoding || (c.DSCBitsPerPixel > 0) != tt.dsc {
+				t.Errorf("config = %s at %d bpc %s, DSC %g bpp, want %s at %d bpc %s, DSC %t",
+					c.LinkName(), c.Display.ColorDepth.BPC(), c.Display.Encoding, c.DSCBitsPerPixel, tt.link, tt.bpc, tt.encoding, tt.dsc)
+			}
+			if c.Usage() > 100 {
+				t.Errorf("usage = %.1f%%, over the link", c.Usage())
+			}
+		})
+	}
+
+	// nouveau has no DSC and no 4:2:0, and 8 bpc does not fit HBR3.
+	nouveau, _ := LookupPolicy("nouveau")
+	if c, ok := nouveau.Negotiate(uhd144, testInterface(t, "DisplayPort", "1.4"), AnyLinkCaps(), AnyLinkCaps()); ok {
+		t.Errorf("nouveau negotiated %s at %d bpc", c.LinkName(), c.Display.ColorDepth.BPC())
+	}
+}
@@ -0,0 +1,158 @@
+package video
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Sink is a monitor or TV.
+type Sink struct {
+	Name     string
+	Category string
+	// Modes are the modes the sink advertises, native first.
+	Modes []SinkMode
+	// MaxBPC is zero when unknown.
+	MaxBPC int
+	// DSC is set when the sink has a DSC decoder.
+	DSC bool
+	// VRRMin and VRRMax are zero without VRR.
+	VRRMin int
+	VRRMax int
+	// Inputs are the physical inputs, each with the newest version it
+	// supports and the fastest TMDS or FRL rate it accepts.
+	Inputs []Port
+	// Quirks are EDID or firmware oddities worth knowing about.
+	Quirks []string
+}
+
+type SinkMode struct {
+	Width       int
+	Height      int
+	RefreshRate int
+	// Interlaced modes refresh at their field rate.
+	Interlaced bool
+}
+
+func (m SinkMode) String() string {
+	return fmt.Sprintf("%dx%d%s@%d", m.Width, m.Height, ScanSuffix(m.Interlaced), m.RefreshRate)
+}
+
+// ParseSinkMode parses WIDTHxHEIGHT@REFRESH, with an i after the height
+// for an interlaced mode: 1920x1080i@50.
+func ParseSinkMode(s string) (SinkMode, error) {
+	var m SinkMode
+	size, refresh, _ := strings.Cut(s, "@")
+	size, m.Interlaced = strings.CutSuffix(size, "i")
+	if _, err := fmt.Sscanf(size+"@"+refresh, "%dx%d@%d", &m.Width, &m.Height, &m.RefreshRate); err != nil ||
+		fmt.Sprint(m) != s {
+		return SinkMode{}, fmt.Errorf("invalid mode %q (want WIDTHxHEIGHT@REFRESH)", s)
+	}
+	if m.Width <= 0 || m.Height <= 0 || m.RefreshRate <= 0 {
+		return SinkMode{}, fmt.Errorf("invalid mode %q: values must be positive", s)
+	}
+	if m.Interlaced && m.Height%2 != 0 {
+		return SinkMode{}, fmt.Errorf("invalid mode %q: an interlaced height must be even", s)
+	}
+	return m, nil
+}
+
+// Display is m with the given pixel format and timing.
+func (m SinkMode) Display(colorDepth ColorDepth, encoding Encoding, timing Timing) Display {
+	return Display{
+		Width:       m.Width,
+		Height:      m.Height,
+		RefreshRate: m.RefreshRate,
+		ColorDepth:  colorDepth,
+		Encoding:    encoding,
+		Timing:      timing,
+		Interlaced:  m.Interlaced,
+	}
+}
+
+func Sinks() []Sink {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return slices.Clone(resolved.sinks)
+}
+
+func LookupSink(name string) (Sink, bool) {
+	for _, s := range Sinks() {
+		if strings.EqualFold(s.Name, name) {
+			return s, true
+		}
+	}
+	return Sink{}, false
+}
+
+// SearchSinks returns the sinks whose name, category or inputs contain every
+// word of query, ignoring case. An empty query matches every sink.
+func SearchSinks(query string) []Sink {
+	terms := strings.Fields(strings.ToLower(query))
+	var out []Sink
+	for _, s := range Sinks() {
+		text := strings.ToLower(s.Name + " " + s.Category + " " + s.InputSummary())
+		if !slices.ContainsFunc(terms, func(t string) bool { return !strings.Contains(text, t) }) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Native is the first advertised mode.
+func (s Sink) Native() SinkMode {
+	if len(s.Modes) == 0 {
+		return SinkMode{}
+	}
+	return s.Modes[0]
+}
+
+func (s Sink) InputSummary() string {
+	return portSummary(s.Inputs)
+}
+
+// VRR describes the VRR range, empty without VRR.
+func (s Sink) VRR() string {
+	if s.VRRMax == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d Hz", s.VRRMin, s.VRRMax)
+}
+
+// Family restricts f to the versions and modes the inputs of s accept,
+// without DSC when s has no decoder. ok is false when s has no input of that
+// family.
+func (s Sink) Family(f InterfaceFamily) (InterfaceFamily, bool) {
+	return restrictFamily(f, s.Inputs, s.DSC)
+}
+
+// Advertises reports whether s lists the mode of d.
+func (s Sink) Advertises(d Display) bool {
+	return slices.Contains(s.Modes, SinkMode{Width: d.Width, Height: d.Height, RefreshRate: d.RefreshRate, Interlaced: d.Interlaced})
+}
+
+// Rejects describes why s does not accept d, whatever the link. A mode is
+// accepted when it is advertised, or when its resolution is and its refresh
+// rate is within the VRR range and no faster than an advertised one.
+func (s Sink) Rejects(d Display) []string {
+	var out []string
+	maxRefresh, listed := 0, false
+	scan := ScanSuffix(d.Interlaced)
+	for _, m := range s.Modes {
+		if m.Width != d.Width || m.Height != d.Height || m.Interlaced != d.Interlaced {
+			continue
+		}
+		maxRefresh = max(maxRefresh, m.RefreshRate)
+		listed = listed || m.RefreshRate == d.RefreshRate
+	}
+	switch {
+	case maxRefresh == 0:
+		out = append(out, fmt.Sprintf("%s does not advertise %dx%d%s", s.Name, d.Width, d.Height, scan))
+	case !listed && (d.RefreshRate > maxRefresh || d.RefreshRate < s.VRRMin || d.RefreshRate > s.VRRMax):
+		out = append(out, fmt.Sprintf("%s does not accept %dx%d%s at %d Hz", s.Name, d.Width, d.Height, scan, d.RefreshRate))
+	}
+	if s.MaxBPC > 0 && d.ColorDepth.BPC() > s.MaxBPC {
+		out = append(out, fmt.Sprintf("%s accepts at most %d bpc", s.Name, s.MaxBPC))
+	}
+	return out
+}
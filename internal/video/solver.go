@@ -0,0 +1,128 @@
+package video
+
+import "fmt"
+
+// maxSolverRefreshRate bounds the refresh rate search. CVT reduced blanking
+// timings stop being defined once the frame period drops below the minimum
+// vertical blanking time, well before this.
+const maxSolverRefreshRate = 2000
+
+// maxSolverScale bounds the resolution search, in multiples of the aspect
+// ratio.
+const maxSolverScale = 4096
+
+// Fits reports whether d fits through mode, only uncompressed or also with
+// DSC when dsc is set and the interface supports it.
+func (i Interface) Fits(d Display, mode TransmissionMode, dsc bool) bool {
+	switch i.Check(d, mode).Status {
+	case StatusOK:
+		return true
+	case StatusDSC:
+		return dsc
+	}
+	return false
+}
+
+// MaxRefreshRate returns the highest integer refresh rate at which d, with
+// its refresh rate ignored, fits through mode. ok is false when not even
+// 1 Hz fits.
+func (i Interface) MaxRefreshRate(d Display, mode TransmissionMode, dsc bool) (int, bool) {
+	fits := func(refresh int) bool {
+		d.RefreshRate = refresh
+		return validTiming(d) && i.Fits(d, mode, dsc)
+	}
+	n, ok := searchMax(maxSolverRefreshRate, fits)
+	return n, ok
+}
+
+// MaxResolution returns the largest display with the aspect ratio
+// aspectW:aspectH that fits through mode at d's refresh rate. Widths and
+// heights are whole multiples of the aspect ratio terms.
+func (i Interface) MaxResolution(d Display, aspectW, aspectH int, mode TransmissionMode, dsc bool) (Display, bool) {
+	if aspectW <= 0 || aspectH <= 0 {
+		return Display{}, false
+	}
+	at := func(scale int) Display {
+		d.Width = aspectW * scale
+		d.Height = aspectH * scale
+		return d
+	}
+	scale, ok := searchMax(maxSolverScale, func(scale int) bool {
+		d := at(scale)
+		return validTiming(d) && i.Fits(d, mode, dsc)
+	})
+	if !ok {
+		return Display{}, false
+	}
+	return at(scale), true
+}
+
+// BestMaxRefreshRate is MaxRefreshRate over every mode of i.
+func (i Interface) BestMaxRefreshRate(d Display, dsc bool) (int, TransmissionMode, bool) {
+	var best int
+	var bestMode TransmissionMode
+	for _, mode := range i.Modes {
+		if n, ok := i.MaxRefreshRate(d, mode, dsc); ok && n > best {
+			best, bestMode = n, mode
+		}
+	}
+	return best, bestMode, bestMode != nil
+}
+
+// BestMaxResolution is MaxResolution over every mode of i.
+func (i Interface) BestMaxResolution(d Display, aspectW, aspectH int, dsc bool) (Display, TransmissionMode, bool) {
+	var best Display
+	var bestMode TransmissionMode
+	for _, mode := range i.Modes {
+		if r, ok := i.MaxResolution(d, aspectW, aspectH, mode, dsc); ok && r.FrameSize() > best.FrameSize() {
+			best, bestMode = r, mode
+		}
+	}
+	return best, bestMode, bestMode != nil
+}
+
+// validTiming rejects parameters for which the timing formula does not yield
+// a usable frame, such as refresh rates beyond the CVT blanking limit.
+func validTiming(d Display) bool {
+	hTotal, vTotal := d.Totals()
+	return hTotal >= d.Width && vTotal >= d.Height && d.EffectiveFrameSize() > 0
+}
+
+// CheckTiming returns an error when the timing of d does not yield a usable
+// frame, as CVT-RB at a refresh rate beyond its blanking limit.
+func CheckTiming(d Display) error {
+	if !validTiming(d) {
+		return fmt.Errorf("%s has no usable %s timing: the refresh rate is beyond its blanking limit", d.Mode(), d.Timing)
+	}
+	return nil
+}
+
+// searchMax returns the largest n in [1, limit] for which fits holds,
+// assuming fits is monotonic: bandwidth never decreases as the refresh rate
+// or resolution grows, even though the CVT vertical blanking is a rounded,
+// non-linear function of both.
+func searchMax(limit int, fits func(int) bool) (int, bool) {
+	if !fits(1) {
+		return 0, false
+	}
+	lo, hi := 1, 2
+	for hi <= limit && fits(hi) {
+		lo, hi = hi, hi*2
+	}
+	if hi > limit {
+		if fits(limit) {
+			return limit, true
+		}
+		hi = limit
+	}
+	// fits(lo) holds and fits(hi) does not.
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if fits(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo, true
+}
@@ -0,0 +1,46 @@
+package video
+
+import "testing"
+
+func TestBestMaxRefreshRate(t *testing.T) {
+	tests := []struct {
+		family, version string
+		colorDepth      ColorDepth
+		dsc             bool
+		want            int
+		mode            string
+	}{
+		{"DisplayPort", "1.4", ColorDepth10bit(), false, 97, "HBR3"},
+		{"DisplayPort", "1.4", ColorDepth10bit(), true, 268, "HBR3"},
+		{"DisplayPort", "1.2", ColorDepth8bit(), false, 81, "HBR2"},
+		{"HDMI", "2.0", ColorDepth8bit(), false, 68, "TMDS (600 MHz)"},
+	}
+	for _, tt := range tests {
+		i := testInterface(t, tt.family, tt.version)
+		d := testDisplay(t, 3840, 2160, 60, tt.colorDepth)
+		n, mode, ok := i.BestMaxRefreshRate(d, tt.dsc)
+		if !ok || n != tt.want || mode.GetName() != tt.mode {
+			t.Errorf("%s at %d bpc, DSC %t: %d Hz on %v, want %d Hz on %s", i, tt.colorDepth.BPC(), tt.dsc, n, mode, tt.want, tt.mode)
+			continue
+		}
+		// The answer is the edge: one more hertz does not fit.
+		d.RefreshRate = n + 1
+		if i.Fits(d, mode, tt.dsc) {
+			t.Errorf("%s at %d bpc, DSC %t: %d Hz fits too", i, tt.colorDepth.BPC(), tt.dsc, n+1)
+		}
+	}
+}
+
+func TestBestMaxResolution(t *testing.T) {
+	i := testInterface(t, "DisplayPort", "1.4")
+	d := testDisplay(t, 3840, 2160, 144, ColorDepth10bit())
+	for dsc, want := range map[bool][2]int{false: {3104, 1746}, true: {5424, 3051}} {
+		r, mode, ok := i.BestMaxResolution(d, 16, 9, dsc)
+		if !ok || r.Width != want[0] || r.Height != want[1] || r.RefreshRate != 144 || mode.GetName() != "HBR3" {
+			t.Errorf("DSC %t: %dx%d@%d on %v, want %dx%d@144 on HBR3", dsc, r.Width, r.Height, r.RefreshRate, mode, want[0], want[1])
+		}
+	}
+	if _, _, ok := i.BestMaxResolution(d, 0, 9, false); ok {
+		t.Error("a 0:9 aspect ratio was solved")
+	}
+}
@@ -0,0 +1,274 @@
+package video
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Source is the display engine of a GPU or other video source.
+type Source struct {
+	Name     string
+	Category string
+	// Heads is the number of display pipes driving outputs at once.
+	Heads int
+	// MaxPixelClock is the fastest pixel rate a head drives, in Hz.
+	MaxPixelClock int
+	// MaxJoinedHeads is the number of heads that can be joined to drive a
+	// single display faster than MaxPixelClock; 1 when heads cannot be
+	// joined.
+	MaxJoinedHeads int
+	// DSCHeads is the number of heads a DSC compressed stream occupies.
+	DSCHeads int
+	// DSCEncoders is the number of DSC encoders, one per head of a
+	// compressed stream.
+	DSCEncoders int
+	// MaxWidth, MaxHeight and MaxRefreshRate are zero when unknown.
+	MaxWidth       int
+	MaxHeight      int
+	MaxRefreshRate int
+	Ports          []Port
+}
+
+func Sources() []Source {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return slices.Clone(resolved.sources)
+}
+
+func LookupSource(name string) (Source, bool) {
+	for _, s := range Sources() {
+		if strings.EqualFold(s.Name, name) {
+			return s, true
+		}
+	}
+	return Source{}, false
+}
+
+// Port is a physical output of a source. Interface is the newest version
+// it supports, without the modes above MaxRate.
+type Port struct {
+	Name      string
+	Interface Interface
+	// MaxRate is the fastest link mode of the port; its zero value does
+	// not limit the port.
+	MaxRate LinkMode
+}
+
+// PortSummary counts the ports of each interface, e.g. "3x DisplayPort
+// 1.4, HDMI 2.1".
+func (s Source) PortSummary() string {
+	return portSummary(s.Ports)
+}
+
+func portSummary(ports []Port) string {
+	var names []string
+	counts := make(map[string]int)
+	for _, p := range ports {
+		name := p.Interface.String()
+		if p.MaxRate.Name != "" {
+			name += " (" + p.MaxRate.Name + ")"
+		}
+		if counts[name] == 0 {
+			names = append(names, name)
+		}
+		counts[name]++
+	}
+	for i, name := range names {
+		if counts[name] > 1 {
+			names[i] = fmt.Sprintf("%dx %s", counts[name], name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// Capped returns i without the modes faster than the port.
+func (p Port) Capped(i Interface) Interface {
+	if p.MaxRate.Name == "" {
+		return i
+	}
+	i.Modes = slices.DeleteFunc(slices.Clone(i.Modes), func(m TransmissionMode) bool {
+		return m.GetBandwidth().Bits > p.MaxRate.MaxBandwidth.Bits
+	})
+	return i
+}
+
+// Family restricts f to the versions and modes the ports of s output,
+// without DSC when s has no encoder. ok is false when s has no port of that
+// family.
+func (s Source) Family(f InterfaceFamily) (InterfaceFamily, bool) {
+	return restrictFamily(f, s.Ports, s.DSCEncoders > 0)
+}
+
+// restrictFamily keeps the versions of f up to the newest one of ports,
+// without the modes faster than that port and without DSC unless dsc is
+// set. Versions are ordered as in the registered family, so that f may
+// already be restricted.
+func restrictFamily(f InterfaceFamily, ports []Port, dsc bool) (InterfaceFamily, bool) {
+	full, ok := LookupInterfaceFamily(f.Name)
+	if !ok {
+		full = f
+	}
+	order := func(version string) int {
+		return slices.IndexFunc(full.Versions, func(v Interface) bool { return v.Version == version })
+	}
+	newest := -1
+	var port Port
+	for _, p := range ports {
+		if p.Interface.Family != f.Name {
+			continue
+		}
+		if n := order(p.Interface.Version); n >= 0 && (newest < 0 || n < newest) {
+			newest, port = n, p
+		}
+	}
+	if newest < 0 {
+		return InterfaceFamily{}, false
+	}
+	out := InterfaceFamily{Name: f.Name}
+	for _, v := range f.Versions {
+		if order(v.Version) < newest {
+			continue
+		}
+		v.DSC = v.DSC && dsc
+		if v = port.Capped(v); len(v.Modes) > 0 {
+			out.Versions = append(out.Versions, v)
+		}
+	}
+	return out, true
+}
+
+// Exceeds describes the source limits d is beyond, if any.
+func (s Source) Exceeds(d Display) []string {
+	var out []string
+	if (s.MaxWidth > 0 && d.Width > s.MaxWidth) || (s.MaxHeight > 0 && d.Height > s.MaxHeight) {
+		out = append(out, fmt.Sprintf("%s outputs at most %dx%d", s.Name, s.MaxWidth, s.MaxHeight))
+	}
+	if s.MaxRefreshRate > 0 && d.RefreshRate > s.MaxRefreshRate {
+		out = append(out, fmt.Sprintf("%s outputs at most %d Hz", s.Name, s.MaxRefreshRate))
+	}
+	return out
+}
+
+func (s Source) Port(name string) (Port, bool) {
+	for _, p := range s.Ports {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return Port{}, false
+}
+
+// Output is a display connected to a port of a source. A link carrying
+// several tiles of a tiled display over MST has one Display stream per
+// tile; Streams is zero or 1 otherwise.
+type Output struct {
+	Name    string
+	Port    string
+	Display Display
+	Streams int
+}
+
+// OutputUsage is what an output takes from its source.
+type OutputUsage struct {
+	Output        Output
+	Port          Port
+	Compatibility Compatibility
+	Heads         int
+	DSC           bool
+	Problems      []string
+}
+
+// SourceUsage is the outcome of validating outputs against a source.
+type SourceUsage struct {
+	Source      Source
+	Outputs     []OutputUsage
+	Heads       int
+	DSCEncoders int
+	// Problems lists the limits the setup exceeds as a whole.
+	Problems []string
+}
+
+// OK reports whether every output works and the source has enough heads
+// and DSC encoders for all of them at once.
+func (u SourceUsage) OK() bool {
+	if len(u.Problems) > 0 {
+		return false
+	}
+	for _, o := range u.Outputs {
+		if len(o.Problems) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks every output against its port and the source limits,
+// then the heads and DSC encoders all the outputs need together.
+func (s Source) Validate(outputs []Output) SourceUsage {
+	u := SourceUsage{Source: s}
+	used := make(map[string]string, len(outputs))
+	for _, o := range outputs {
+		ou := OutputUsage{Output: o}
+		problem := func(format string, args ...any) {
+			ou.Problems = append(ou.Problems, fmt.Sprintf(format, args...))
+		}
+		port, ok := s.Port(o.Port)
+		if !ok {
+			problem("no port %q on %s", o.Port, s.Name)
+			u.Outputs = append(u.Outputs, ou)
+			continue
+		}
+		ou.Port = port
+		if other, ok := used[strings.ToLower(port.Name)]; ok {
+			problem("port %s is already used by %s", port.Name, other)
+		}
+		used[strings.ToLower(port.Name)] = o.Name
+
+		ou.Problems = append(ou.Problems, s.Exceeds(o.Display)...)
+		streams := max(o.Streams, 1)
+		mode := o.Display.Mode()
+		if streams > 1 {
+			mode = fmt.Sprintf("%d streams of %s", streams, mode)
+			if !port.Interface.MST() {
+				problem("%s has no MST to carry %d streams on %s", port.Interface, streams, port.Name)
+			}
+		}
+		ou.Compatibility = port.Interface.CheckStreams(o.Display, streams)
+		switch ou.Compatibility.Status {
+		case StatusOK:
+		case StatusDSC:
+			ou.DSC = true
+			if s.DSCEncoders == 0 {
+				problem("%s needs DSC on %s, %s has no DSC encoder", mode, port.Interface, s.Name)
+			}
+		default:
+			problem("%s does not fit %s", mode, port.Interface)
+		}
+
+		// Each stream has its own heads.
+		pixelClock := o.Display.EffectivePixelRate()
+		heads := 1
+		if s.MaxPixelClock > 0 {
+			heads = (pixelClock + s.MaxPixelClock - 1) / s.MaxPixelClock
+		}
+		if heads > max(s.MaxJoinedHeads, 1) {
+			problem("%.2f MHz pixel clock needs %d heads, %s joins at most %d",
+				float64(pixelClock)/1e6, heads, s.Name, max(s.MaxJoinedHeads, 1))
+		}
+		if ou.DSC && s.DSCEncoders > 0 {
+			heads = max(heads, s.DSCHeads)
+			u.DSCEncoders += heads * streams
+		}
+		ou.Heads = heads * streams
+		u.Heads += ou.Heads
+		u.Outputs = append(u.Outputs, ou)
+	}
+	if u.Heads > s.Heads {
+		u.Problems = append(u.Problems, fmt.Sprintf("%d heads needed, %s has %d", u.Heads, s.Name, s.Heads))
+	}
+	if u.DSCEncoders > s.DSCEncoders {
+		u.Problems = append(u.Problems, fmt.Sprintf("%d DSC encoders needed, %s has %d", u.DSCEncoders, s.Name, s.DSCEncoders))
+	}
+	return u
+}
@@ -0,0 +1,59 @@
+package video
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSourceValidate(t *testing.T) {
+	s, ok := LookupSource("NVIDIA GeForce RTX 4090")
+	if !ok {
+		t.Fatal("no RTX 4090 source")
+	}
+	uhd60 := testDisplay(t, 3840, 2160, 60, ColorDepth10bit())
+	uhd240 := testDisplay(t, 3840, 2160, 240, ColorDepth10bit())
+	out := func(port string, d Display) Output { return Output{Name: port, Port: port, Display: d} }
+
+	tests := []struct {
+		name    string
+		outputs []Output
+		heads   int
+		dsc     int
+		// problems are substrings of the problems of the setup, then of
+		// each output in order, empty when there are none.
+		problems []string
+	}{
+		{"every port", []Output{out("DP-1", uhd60), out("DP-2", uhd60), out("DP-3", uhd60), out("HDMI-1", uhd60)}, 4, 0,
+			[]string{"", "", "", "", ""}},
+		// 4K240 needs DSC and two joined heads, leaving too few for the
+		// other three outputs.
+		{"joined heads", []Output{out("DP-1", uhd240), out("DP-2", uhd60), out("DP-3", uhd60), out("HDMI-1", uhd60)}, 5, 2,
+			[]string{"5 heads needed, NVIDIA GeForce RTX 4090 has 4", "", "", "", ""}},
+		{"ports", []Output{out("DP-1", uhd60), out("dp-1", uhd60), out("DP-4", uhd60)}, 2, 0,
+			[]string{"", "", "port DP-1 is already used by DP-1", `no port "DP-4"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := s.Validate(tt.outputs)
+			if u.Heads != tt.heads || u.DSCEncoders != tt.dsc {
+				t.Errorf("%d heads, %d DSC encoders, want %d and %d", u.Heads, u.DSCEncoders, tt.heads, tt.dsc)
+			}
+			got := []string{strings.Join(u.Problems, "; ")}
+			for _, o := range u.Outputs {
+				got = append(got, strings.Join(o.Problems, "; "))
+			}
+			if len(got) != len(tt.problems) {
+				t.Fatalf("problems = %q, want %q", got, tt.problems)
+			}
+			for n, want := range tt.problems {
+				if (want == "") != (got[n] == "") || !strings.Contains(got[n], want) {
+					t.Errorf("problems = %q, want %q", got, tt.problems)
+					break
+				}
+			}
+			if ok := u.OK(); ok != (strings.Join(tt.problems, "") == "") {
+				t.Errorf("OK = %t with problems %q", ok, got)
+			}
+		})
+	}
+}
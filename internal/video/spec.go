@@ -0,0 +1,857 @@
+package video
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/hekmon/cunits/v3"
+	"gopkg.in/yaml.v3"
+)
+
+const SpecVersion = 1
+
+//go:embed specs/default.yaml
+var specFS embed.FS
+
+type Spec struct {
+	Version    int             `yaml:"version"`
+	Modes      []ModeSpec      `yaml:"modes,omitempty"`
+	Interfaces []InterfaceSpec `yaml:"interfaces,omitempty"`
+	Timings    []TimingSpec    `yaml:"timings,omitempty"`
+	Presets    []PresetSpec    `yaml:"presets,omitempty"`
+	Policies   []PolicySpec    `yaml:"policies,omitempty"`
+	Sources    []SourceSpec    `yaml:"sources,omitempty"`
+	Sinks      []SinkSpec      `yaml:"sinks,omitempty"`
+	Cables     []CableSpec     `yaml:"cables,omitempty"`
+	Adapters   []AdapterSpec   `yaml:"adapters,omitempty"`
+	Docks      []DockSpec      `yaml:"docks,omitempty"`
+}
+
+type ModeSpec struct {
+	Name       string  `yaml:"name"`
+	Bandwidth  float64 `yaml:"bandwidth"`
+	Efficiency float64 `yaml:"efficiency"`
+}
+
+type InterfaceSpec struct {
+	Family string `yaml:"family"`
+	// Lanes lists the lane counts a link can be trained at. Mode bandwidths
+	// are those of the widest one.
+	Lanes    []int         `yaml:"lanes,omitempty"`
+	Versions []VersionSpec `yaml:"versions"`
+}
+
+type VersionSpec struct {
+	Version string `yaml:"version"`
+	DSC     bool   `yaml:"dsc,omitempty"`
+	HDR     bool   `yaml:"hdr,omitempty"`
+	// Training is the link training fallback order: rate-first,
+	// bandwidth, or empty when the link is not trained.
+	Training string   `yaml:"training,omitempty"`
+	Modes    []string `yaml:"modes"`
+}
+
+type TimingSpec struct {
+	Name   string  `yaml:"name"`
+	Type   string  `yaml:"type"`
+	VMin   float64 `yaml:"vmin,omitempty"`
+	HBlank int     `yaml:"hblank,omitempty"`
+}
+
+type PresetSpec struct {
+	Name     string `yaml:"name"`
+	Category string `yaml:"category,omitempty"`
+	Width    int    `yaml:"width"`
+	Height   int    `yaml:"height"`
+	Refresh  int    `yaml:"refresh"`
+	BPC      int    `yaml:"bpc"`
+	Encoding string `yaml:"encoding,omitempty"`
+	Timing   string `yaml:"timing"`
+	// Interlaced presets refresh at their field rate.
+	Interlaced bool `yaml:"interlaced,omitempty"`
+	// Fractional presets refresh at 1000/1001 of Refresh.
+	Fractional bool `yaml:"fractional,omitempty"`
+}
+
+type PolicySpec struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	LinkOrder   string   `yaml:"link_order"`
+	Fallbacks   []string `yaml:"fallbacks,omitempty"`
+}
+
+type SourceSpec struct {
+	Name     string `yaml:"name"`
+	Category string `yaml:"category,omitempty"`
+	Heads    int    `yaml:"heads"`
+	// MaxPixelClock is in MHz.
+	MaxPixelClock  float64    `yaml:"max_pixel_clock"`
+	MaxWidth       int        `yaml:"max_width,omitempty"`
+	MaxHeight      int        `yaml:"max_height,omitempty"`
+	MaxRefresh     int        `yaml:"max_refresh,omitempty"`
+	MaxJoinedHeads int        `yaml:"max_joined_heads,omitempty"`
+	DSCHeads       int        `yaml:"dsc_heads,omitempty"`
+	DSCEncoders    int        `yaml:"dsc_encoders,omitempty"`
+	Ports          []PortSpec `yaml:"ports"`
+}
+
+// SinkSpec is a monitor or TV. Modes are the modes it advertises as
+// WIDTHxHEIGHT@REFRESH, native first.
+type SinkSpec struct {
+	Name     string     `yaml:"name"`
+	Category string     `yaml:"category,omitempty"`
+	Modes    []string   `yaml:"modes"`
+	MaxBPC   int        `yaml:"max_bpc,omitempty"`
+	DSC      bool       `yaml:"dsc,omitempty"`
+	VRRMin   int        `yaml:"vrr_min,omitempty"`
+	VRRMax   int        `yaml:"vrr_max,omitempty"`
+	Inputs   []PortSpec `yaml:"inputs"`
+	Quirks   []string   `yaml:"quirks,omitempty"`
+}
+
+type CableSpec struct {
+	Name      string `yaml:"name"`
+	Interface string `yaml:"interface"`
+	// MaxRate is the fastest link mode the cable is certified for.
+	MaxRate string `yaml:"max_rate"`
+}
+
+// AdapterSpec is a dongle or converter. Active adapters train a link on
+// each side; passive ones have the source drive the output directly.
+type AdapterSpec struct {
+	Name   string   `yaml:"name"`
+	Input  PortSpec `yaml:"input"`
+	Output PortSpec `yaml:"output"`
+	Active bool     `yaml:"active,omitempty"`
+	// MaxTMDSClock is in MHz.
+	MaxTMDSClock float64 `yaml:"max_tmds_clock,omitempty"`
+	DSC          bool    `yaml:"dsc,omitempty"`
+}
+
+type DockSpec struct {
+	Name     string       `yaml:"name"`
+	Upstream UpstreamSpec `yaml:"upstream"`
+	// MSTStreams is the number of displays the MST hub drives from one
+	// stream, when the dock has one.
+	MSTStreams int            `yaml:"mst_streams,omitempty"`
+	Ports      []DockPortSpec `yaml:"ports"`
+}
+
+// UpstreamSpec is the host link of a dock: dp-alt on the given lane
+// counts, or thunderbolt4 and usb4 tunnelling streams through bandwidth
+// Gb/s. Version is the DisplayPort version of the link or tunnels.
+type UpstreamSpec struct {
+	Link      string  `yaml:"link"`
+	Version   string  `yaml:"version"`
+	Lanes     []int   `yaml:"lanes,omitempty"`
+	Bandwidth float64 `yaml:"bandwidth,omitempty"`
+	Tunnels   int     `yaml:"tunnels,omitempty"`
+}
+
+type DockPortSpec struct {
+	PortSpec `yaml:",inline"`
+	// Tunnel is the index of the upstream stream feeding the port.
+	Tunnel int `yaml:"tunnel,omitempty"`
+}
+
+type PortSpec struct {
+	Name      string `yaml:"name"`
+	Interface string `yaml:"interface"`
+	Version   string `yaml:"version"`
+	// MaxRate is the fastest link mode of the port, when it is slower than
+	// its version allows.
+	MaxRate string `yaml:"max_rate,omitempty"`
+}
+
+// ParseSpec decodes a YAML or JSON spec document. Unknown fields are
+// rejected so that typos do not silently fall back to zero values.
+func ParseSpec(data []byte) (Spec, error) {
+	var s Spec
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil {
+		return Spec{}, fmt.Errorf("spec: %w", err)
+	}
+	if s.Version != SpecVersion {
+		return Spec{}, fmt.Errorf("spec: unsupported version %d (want %d)", s.Version, SpecVersion)
+	}
+	if err := s.checkDuplicates(); err != nil {
+		return Spec{}, err
+	}
+	return s, nil
+}
+
+// LoadSpecFile extends or overrides the active definitions with the spec at
+// path. Entries are matched by name; the file is rejected as a whole if the
+// merged result does not validate.
+func LoadSpecFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	s, err := ParseSpec(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := ApplySpec(s); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func ApplySpec(s Spec) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	merged := activeSpec.merge(s)
+	r, err := merged.resolve()
+	if err != nil {
+		return err
+	}
+	// Families registered in Go keep their name; a spec cannot define it
+	// again.
+	for _, f := range r.families {
+		if slices.ContainsFunc(registered, func(g InterfaceFamily) bool { return g.Name == f.Name }) {
+			return fmt.Errorf("spec: interface %q: already registered", f.Name)
+		}
+	}
+	activeSpec = merged
+	resolved = r
+	return nil
+}
+
+func ActiveSpec() Spec {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return activeSpec
+}
+
+func (s Spec) checkDuplicates() error {
+	var errs []error
+	dup := func(section string, names []string) {
+		seen := make(map[string]bool, len(names))
+		for _, n := range names {
+			if seen[n] {
+				errs = append(errs, fmt.Errorf("spec: %s: duplicate entry %q", section, n))
+			}
+			seen[n] = true
+		}
+	}
+	dup("modes", names(s.Modes, func(m ModeSpec) string { return m.Name }))
+	dup("interfaces", names(s.Interfaces, func(i InterfaceSpec) string { return i.Family }))
+	for _, i := range s.Interfaces {
+		dup("interfaces["+i.Family+"].versions", names(i.Versions, func(v VersionSpec) string { return v.Version }))
+	}
+	dup("timings", names(s.Timings, func(t TimingSpec) string { return t.Name }))
+	dup("presets", names(s.Presets, func(p PresetSpec) string { return p.Name }))
+	dup("policies", names(s.Policies, func(p PolicySpec) string { return p.Name }))
+	dup("sources", names(s.Sources, func(p SourceSpec) string { return p.Name }))
+	dup("sinks", names(s.Sinks, func(p SinkSpec) string { return p.Name }))
+	dup("cables", names(s.Cables, func(c CableSpec) string { return c.Name }))
+	dup("adapters", names(s.Adapters, func(a AdapterSpec) string { return a.Name }))
+	dup("docks", names(s.Docks, func(d DockSpec) string { return d.Name }))
+	return errors.Join(errs...)
+}
+
+func (s Spec) merge(o Spec) Spec {
+	s.Version = o.Version
+	s.Modes = mergeByName(s.Modes, o.Modes, func(m ModeSpec) string { return m.Name })
+	s.Timings = mergeByName(s.Timings, o.Timings, func(t TimingSpec) string { return t.Name })
+	s.Presets = mergeByName(s.Presets, o.Presets, func(p PresetSpec) string { return p.Name })
+	s.Policies = mergeByName(s.Policies, o.Policies, func(p PolicySpec) string { return p.Name })
+	s.Sources = mergeByName(s.Sources, o.Sources, func(p SourceSpec) string { return p.Name })
+	s.Sinks = mergeByName(s.Sinks, o.Sinks, func(p SinkSpec) string { return p.Name })
+	s.Cables = mergeByName(s.Cables, o.Cables, func(c CableSpec) string { return c.Name })
+	s.Adapters = mergeByName(s.Adapters, o.Adapters, func(a AdapterSpec) string { return a.Name })
+	s.Docks = mergeByName(s.Docks, o.Docks, func(d DockSpec) string { return d.Name })
+	interfaces := slices.Clone(s.Interfaces)
+	for _, oi := range o.Interfaces {
+		i := slices.IndexFunc(interfaces, func(i InterfaceSpec) bool { return i.Family == oi.Family })
+		if i < 0 {
+			interfaces = append(interfaces, oi)
+			continue
+		}
+		if oi.Lanes != nil {
+			interfaces[i].Lanes = oi.Lanes
+		}
+		interfaces[i].Versions = mergeByName(interfaces[i].Versions, oi.Versions, func(v VersionSpec) string { return v.Version })
+	}
+	s.Interfaces = interfaces
+	return s
+}
+
+type resolvedSpec struct {
+	modes    []LinkMode
+	families []InterfaceFamily
+	timings  []Timing
+	presets  []Preset
+	policies []Policy
+	sources  []Source
+	sinks    []Sink
+	cables   []Cable
+	adapters []Adapter
+	docks    []Dock
+}
+
+func (s Spec) resolve() (resolvedSpec, error) {
+	var r resolvedSpec
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("spec: "+format, args...))
+	}
+
+	modes := make(map[string]LinkMode, len(s.Modes))
+	for i, m := range s.Modes {
+		if m.Name == "" {
+			fail("modes[%d]: name is required", i)
+			continue
+		}
+		if m.Bandwidth <= 0 {
+			fail("mode %q: bandwidth must be positive, got %g", m.Name, m.Bandwidth)
+		}
+		if m.Efficiency <= 0 || m.Efficiency > 1 {
+			fail("mode %q: efficiency must be in (0, 1], got %g", m.Name, m.Efficiency)
+		}
+		mode := LinkMode{
+			Name:         m.Name,
+			MaxBandwidth: cunits.Speed{Bits: cunits.ImportInGb(m.Bandwidth)},
+			Efficiency:   m.Efficiency,
+		}
+		modes[m.Name] = mode
+		r.modes = append(r.modes, mode)
+	}
+
+	for i, is := range s.Interfaces {
+		if is.Family == "" {
+			fail("interfaces[%d]: family is required", i)
+			continue
+		}
+		if len(is.Versions) == 0 {
+			fail("interface %q: at least one version is required", is.Family)
+		}
+		for _, n := range is.Lanes {
+			if n <= 0 {
+				fail("interface %q: lane counts must be positive, got %d", is.Family, n)
+			}
+		}
+		lanes := slices.Clone(is.Lanes)
+		slices.Sort(lanes)
+		lanes = slices.Compact(lanes)
+		f := InterfaceFamily{Name: is.Family}
+		for j, vs := range is.Versions {
+			if vs.Version == "" {
+				fail("interface %q: versions[%d]: version is required", is.Family, j)
+			}
+			if len(vs.Modes) == 0 {
+				fail("interface %q version %q: at least one mode is required", is.Family, vs.Version)
+			}
+			training, err := ParseLinkTraining(vs.Training)
+			if err != nil {
+				fail("interface %q version %q: %v", is.Family, vs.Version, err)
+			}
+			v := Interface{
+				Family:   is.Family,
+				Version:  vs.Version,
+				DSC:      vs.DSC,
+				HDR:      vs.HDR,
+				Lanes:    lanes,
+				Training: training,
+			}
+			for _, name := range vs.Modes {
+				mode, ok := modes[name]
+				if !ok {
+					fail("interface %q version %q: unknown mode %q", is.Family, vs.Version, name)
+					continue
+				}
+				v.Modes = append(v.Modes, mode)
+			}
+			f.Versions = append(f.Versions, v)
+		}
+		r.families = append(r.families, f)
+	}
+
+	timings := make(map[string]Timing, len(s.Timings))
+	for i, ts := range s.Timings {
+		if ts.Name == "" {
+			fail("timings[%d]: name is required", i)
+			continue
+		}
+		t, err := ts.timing()
+		if err != nil {
+			fail("timing %q: %v", ts.Name, err)
+			continue
+		}
+		timings[ts.Name] = t
+		r.timings = append(r.timings, t)
+	}
+
+	for i, ps := range s.Presets {
+		if ps.Name == "" {
+			fail("presets[%d]: name is required", i)
+			continue
+		}
+		p, err := ps.preset(func(name string) (Timing, bool) {
+			t, ok := timings[name]
+			return t, ok
+		})
+		if err != nil {
+			fail("preset %q: %v", ps.Name, err)
+			continue
+		}
+		r.presets = append(r.presets, p)
+	}
+
+	for i, ps := range s.Policies {
+		if ps.Name == "" {
+			fail("policies[%d]: name is required", i)
+			continue
+		}
+		p, err := ps.policy()
+		if err != nil {
+			fail("policy %q: %v", ps.Name, err)
+			continue
+		}
+		r.policies = append(r.policies, p)
+	}
+
+	lookupFamily := func(name string) (InterfaceFamily, bool) {
+		i := slices.IndexFunc(r.families, func(f InterfaceFamily) bool { return f.Name == name })
+		if i < 0 {
+			return InterfaceFamily{}, false
+		}
+		return r.families[i], true
+	}
+	for i, ss := range s.Sources {
+		if ss.Name == "" {
+			fail("sources[%d]: name is required", i)
+			continue
+		}
+		src, err := ss.source(lookupFamily, modes)
+		if err != nil {
+			fail("source %q: %v", ss.Name, err)
+			continue
+		}
+		r.sources = append(r.sources, src)
+	}
+
+	for i, ss := range s.Sinks {
+		if ss.Name == "" {
+			fail("sinks[%d]: name is required", i)
+			continue
+		}
+		sink, err := ss.sink(lookupFamily, modes)
+		if err != nil {
+			fail("sink %q: %v", ss.Name, err)
+			continue
+		}
+		r.sinks = append(r.sinks, sink)
+	}
+
+	for i, cs := range s.Cables {
+		if cs.Name == "" {
+			fail("cables[%d]: name is required", i)
+			continue
+		}
+		if _, ok := lookupFamily(cs.Interface); !ok {
+			fail("cable %q: unknown interface %q", cs.Name, cs.Interface)
+			continue
+		}
+		mode, ok := modes[cs.MaxRate]
+		if !ok {
+			fail("cable %q: unknown mode %q", cs.Name, cs.MaxRate)
+			continue
+		}
+		r.cables = append(r.cables, Cable{Name: cs.Name, Family: cs.Interface, MaxRate: mode})
+	}
+
+	for i, as := range s.Adapters {
+		if as.Name == "" {
+			fail("adapters[%d]: name is required", i)
+			continue
+		}
+		a, err := as.adapter(lookupFamily, modes)
+		if err != nil {
+			fail("adapter %q: %v", as.Name, err)
+			continue
+		}
+		r.adapters = append(r.adapters, a)
+	}
+
+	for i, ds := range s.Docks {
+		if ds.Name == "" {
+			fail("docks[%d]: name is required", i)
+			continue
+		}
+		d, err := ds.dock(lookupFamily, modes)
+		if err != nil {
+			fail("dock %q: %v", ds.Name, err)
+			continue
+		}
+		r.docks = append(r.docks, d)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return resolvedSpec{}, err
+	}
+	return r, nil
+}
+
+func NewPresetSpec(p Preset) PresetSpec {
+	return PresetSpec{
+		Name:       p.Name,
+		Category:   p.Category,
+		Width:      p.Display.Width,
+		Height:     p.Display.Height,
+		Refresh:    p.Display.RefreshRate,
+		BPC:        p.Display.ColorDepth.BPC(),
+		Encoding:   encodingSpec(p.Display.Encoding),
+		Timing:     p.Display.Timing.String(),
+		Interlaced: p.Display.Interlaced,
+		Fractional: p.Display.Fractional,
+	}
+}
+
+// Preset resolves the spec against the active timings.
+func (p PresetSpec) Preset() (Preset, error) {
+	return p.preset(LookupTiming)
+}
+
+func (p PresetSpec) preset(lookupTiming func(string) (Timing, bool)) (Preset, error) {
+	if p.Width <= 0 || p.Height <= 0 || p.Refresh <= 0 {
+		return Preset{}, fmt.Errorf("width, height and refresh must be positive")
+	}
+	if p.Interlaced && p.Height%2 != 0 {
+		return Preset{}, fmt.Errorf("an interlaced height must be even, got %d", p.Height)
+	}
+	colorDepth, err := ColorDepthFromBPC(p.BPC)
+	if err != nil {
+		return Preset{}, err
+	}
+	encoding, err := ParseEncoding(p.Encoding)
+	if err != nil {
+		return Preset{}, err
+	}
+	timing, ok := lookupTiming(p.Timing)
+	if !ok {
+		return Preset{}, fmt.Errorf("unknown timing %q", p.Timing)
+	}
+	return Preset{
+		Name:     p.Name,
+		Category: p.Category,
+		Display: Display{
+			Width:       p.Width,
+			Height:      p.Height,
+			RefreshRate: p.Refresh,
+			ColorDepth:  colorDepth,
+			Encoding:    encoding,
+			Timing:      timing,
+			Interlaced:  p.Interlaced,
+			Fractional:  p.Fractional,
+		},
+	}, nil
+}
+
+func encodingSpec(e Encoding) string {
+	if e == EncodingRGB {
+		return ""
+	}
+	return e.ShortName()
+}
+
+func (t TimingSpec) timing() (Timing, error) {
+	switch t.Type {
+	case "cvt-rb":
+		if t.VMin <= 0 {
+			return nil, fmt.Errorf("vmin must be positive, got %g", t.VMin)
+		}
+		if t.HBlank < 0 {
+			return nil, fmt.Errorf("hblank must not be negative, got %d", t.HBlank)
+		}
+		return CVTRBTiming{
+			Name:   t.Name,
+			VMin:   t.VMin,
+			HBlank: t.HBlank,
+		}, nil
+	case "":
+		return nil, fmt.Errorf("type is required")
+	}
+	return nil, fmt.Errorf("unknown timing type %q", t.Type)
+}
+
+func (p PolicySpec) policy() (Policy, error) {
+	order, err := ParseLinkOrder(p.LinkOrder)
+	if err != nil {
+		return Policy{}, err
+	}
+	policy := Policy{
+		Name:        p.Name,
+		Description: p.Description,
+		LinkOrder:   order,
+	}
+	for _, name := range p.Fallbacks {
+		f, err := ParseFallback(name)
+		if err != nil {
+			return Policy{}, err
+		}
+		if slices.Contains(policy.Fallbacks, f) {
+			return Policy{}, fmt.Errorf("duplicate fallback %q", name)
+		}
+		policy.Fallbacks = append(policy.Fallbacks, f)
+	}
+	return policy, nil
+}
+
+// Source resolves the spec against the active interfaces and link modes.
+func (s SourceSpec) Source() (Source, error) {
+	modes := make(map[string]LinkMode)
+	for _, m := range LinkModes() {
+		modes[m.Name] = m
+	}
+	return s.source(LookupInterfaceFamily, modes)
+}
+
+func (s SourceSpec) source(lookupFamily func(string) (InterfaceFamily, bool), modes map[string]LinkMode) (Source, error) {
+	var errs []error
+	if s.Name == "" {
+		errs = append(errs, fmt.Errorf("name is required"))
+	}
+	if s.Heads <= 0 {
+		errs = append(errs, fmt.Errorf("heads must be positive, got %d", s.Heads))
+	}
+	if s.MaxPixelClock < 0 || s.MaxJoinedHeads < 0 || s.DSCHeads < 0 || s.DSCEncoders < 0 ||
+		s.MaxWidth < 0 || s.MaxHeight < 0 || s.MaxRefresh < 0 {
+		errs = append(errs, fmt.Errorf("limits must not be negative"))
+	}
+	src := Source{
+		Name:           s.Name,
+		Category:       s.Category,
+		Heads:          s.Heads,
+		MaxWidth:       s.MaxWidth,
+		MaxHeight:      s.MaxHeight,
+		MaxRefreshRate: s.MaxRefresh,
+		MaxPixelClock:  int(s.MaxPixelClock * 1e6),
+		MaxJoinedHeads: max(s.MaxJoinedHeads, 1),
+		DSCHeads:       max(s.DSCHeads, 1),
+		DSCEncoders:    s.DSCEncoders,
+	}
+	if len(s.Ports) == 0 {
+		errs = append(errs, fmt.Errorf("at least one port is required"))
+	}
+	ports, err := resolvePorts(s.Ports, lookupFamily, modes)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	src.Ports = ports
+	if err := errors.Join(errs...); err != nil {
+		return Source{}, err
+	}
+	return src, nil
+}
+
+func (s SinkSpec) sink(lookupFamily func(string) (InterfaceFamily, bool), modes map[string]LinkMode) (Sink, error) {
+	var errs []error
+	if s.MaxBPC != 0 {
+		if _, err := ColorDepthFromBPC(s.MaxBPC); err != nil {
+			errs = append(errs, fmt.Errorf("max_bpc: %v", err))
+		}
+	}
+	if s.VRRMin < 0 || s.VRRMax < s.VRRMin || (s.VRRMin > 0) != (s.VRRMax > 0) {
+		errs = append(errs, fmt.Errorf("invalid VRR range %d-%d Hz", s.VRRMin, s.VRRMax))
+	}
+	sink := Sink{
+		Name:     s.Name,
+		Category: s.Category,
+		MaxBPC:   s.MaxBPC,
+		DSC:      s.DSC,
+		VRRMin:   s.VRRMin,
+		VRRMax:   s.VRRMax,
+		Quirks:   slices.Clone(s.Quirks),
+	}
+	if len(s.Modes) == 0 {
+		errs = append(errs, fmt.Errorf("at least one mode is required"))
+	}
+	for _, ms := range s.Modes {
+		m, err := ParseSinkMode(ms)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sink.Modes = append(sink.Modes, m)
+	}
+	if len(s.Inputs) == 0 {
+		errs = append(errs, fmt.Errorf("at least one input is required"))
+	}
+	inputs, err := resolvePorts(s.Inputs, lookupFamily, modes)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	sink.Inputs = inputs
+	if err := errors.Join(errs...); err != nil {
+		return Sink{}, err
+	}
+	return sink, nil
+}
+
+func (s AdapterSpec) adapter(lookupFamily func(string) (InterfaceFamily, bool), modes map[string]LinkMode) (Adapter, error) {
+	if s.MaxTMDSClock < 0 {
+		return Adapter{}, fmt.Errorf("max_tmds_clock must not be negative")
+	}
+	in, out := s.Input, s.Output
+	if in.Name == "" {
+		in.Name = "input"
+	}
+	if out.Name == "" {
+		out.Name = "output"
+	}
+	ports, err := resolvePorts([]PortSpec{in, out}, lookupFamily, modes)
+	if err != nil {
+		return Adapter{}, err
+	}
+	return Adapter{
+		Name:         s.Name,
+		Input:        ports[0],
+		Output:       ports[1],
+		Active:       s.Active,
+		MaxTMDSClock: int(s.MaxTMDSClock * 1e6),
+		DSC:          s.DSC,
+	}, nil
+}
+
+func (s DockSpec) dock(lookupFamily func(string) (InterfaceFamily, bool), modes map[string]LinkMode) (Dock, error) {
+	var errs []error
+	link, err := ParseUpstreamLink(s.Upstream.Link)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	d := Dock{
+		Name:       s.Name,
+		Upstream:   link,
+		Bandwidth:  cunits.Speed{Bits: cunits.ImportInGb(s.Upstream.Bandwidth)},
+		Tunnels:    max(s.Upstream.Tunnels, 1),
+		MSTStreams: max(s.MSTStreams, 1),
+	}
+	if f, ok := lookupFamily("DisplayPort"); !ok {
+		errs = append(errs, fmt.Errorf("the DisplayPort interface is not defined"))
+	} else if d.Interface, ok = f.Version(s.Upstream.Version); !ok {
+		errs = append(errs, fmt.Errorf("unknown DisplayPort version %q", s.Upstream.Version))
+	}
+	switch link {
+	case UpstreamDPAltMode:
+		d.Tunnels = 1
+		for _, n := range s.Upstream.Lanes {
+			if n != 2 && n != 4 {
+				errs = append(errs, fmt.Errorf("alt mode lane counts are 2 or 4, got %d", n))
+			}
+		}
+		d.Lanes = slices.Clone(s.Upstream.Lanes)
+		slices.Sort(d.Lanes)
+		d.Lanes = slices.Compact(d.Lanes)
+		if len(d.Lanes) == 0 {
+			errs = append(errs, fmt.Errorf("alt mode needs at least one lane count"))
+		}
+	default:
+		if s.Upstream.Bandwidth <= 0 {
+			errs = append(errs, fmt.Errorf("%s needs a positive bandwidth", link))
+		}
+	}
+	if len(s.Ports) == 0 {
+		errs = append(errs, fmt.Errorf("at least one port is required"))
+	}
+	specs := make([]PortSpec, len(s.Ports))
+	for i, ps := range s.Ports {
+		specs[i] = ps.PortSpec
+		if ps.Tunnel < 0 || ps.Tunnel >= d.Tunnels {
+			errs = append(errs, fmt.Errorf("port %q: tunnel must be in [0, %d), got %d", ps.Name, d.Tunnels, ps.Tunnel))
+		}
+	}
+	ports, err := resolvePorts(specs, lookupFamily, modes)
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		for i, p := range ports {
+			d.Ports = append(d.Ports, DockPort{Port: p, Tunnel: s.Ports[i].Tunnel})
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return Dock{}, err
+	}
+	return d, nil
+}
+
+func resolvePorts(specs []PortSpec, lookupFamily func(string) (InterfaceFamily, bool), modes map[string]LinkMode) ([]Port, error) {
+	var out []Port
+	var errs []error
+	for i, ps := range specs {
+		if ps.Name == "" {
+			errs = append(errs, fmt.Errorf("ports[%d]: name is required", i))
+			continue
+		}
+		f, ok := lookupFamily(ps.Interface)
+		if !ok {
+			errs = append(errs, fmt.Errorf("port %q: unknown interface %q", ps.Name, ps.Interface))
+			continue
+		}
+		iface, ok := f.Version(ps.Version)
+		if !ok {
+			errs = append(errs, fmt.Errorf("port %q: unknown %s version %q", ps.Name, f.Name, ps.Version))
+			continue
+		}
+		port := Port{Name: ps.Name, Interface: iface}
+		if ps.MaxRate != "" {
+			if port.MaxRate, ok = modes[ps.MaxRate]; !ok {
+				errs = append(errs, fmt.Errorf("port %q: unknown mode %q", ps.Name, ps.MaxRate))
+				continue
+			}
+			port.Interface = port.Capped(iface)
+			if len(port.Interface.Modes) == 0 {
+				errs = append(errs, fmt.Errorf("port %q: no %s mode at or below %s", ps.Name, iface, ps.MaxRate))
+				continue
+			}
+		}
+		out = append(out, port)
+	}
+	return out, errors.Join(errs...)
+}
+
+func mergeByName[T any](base, override []T, name func(T) string) []T {
+	out := slices.Clone(base)
+	for _, o := range override {
+		i := slices.IndexFunc(out, func(b T) bool { return name(b) == name(o) })
+		if i < 0 {
+			out = append(out, o)
+		} else {
+			out[i] = o
+		}
+	}
+	return out
+}
+
+func names[T any](s []T, name func(T) string) []string {
+	out := make([]string, len(s))
+	for i, v := range s {
+		out[i] = name(v)
+	}
+	return out
+}
+
+var (
+	activeSpec Spec
+	resolved   resolvedSpec
+)
+
+func init() {
+	data, err := specFS.ReadFile("specs/default.yaml")
+	if err != nil {
+		panic(err)
+	}
+	s, err := ParseSpec(data)
+	if err != nil {
+		panic(err)
+	}
+	if err := ApplySpec(s); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,110 @@
+package video
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "valid",
+			data: "version: 1\nmodes:\n  - name: FAST\n    bandwidth: 10\n    efficiency: 0.8\n",
+		},
+		{
+			name: "json",
+			data: `{"version": 1, "timings": [{"name": "Slow", "type": "cvt-rb", "vmin": 0.00046, "hblank": 160}]}`,
+		},
+		{
+			name: "unknown field",
+			data: "version: 1\nmodes:\n  - name: FAST\n    bandwith: 10\n",
+			err:  "field bandwith not found",
+		},
+		{
+			name: "version",
+			data: "version: 2\n",
+			err:  "unsupported version 2",
+		},
+		{
+			name: "duplicate",
+			data: "version: 1\nmodes:\n  - name: FAST\n  - name: FAST\n",
+			err:  `duplicate entry "FAST"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSpec([]byte(tt.data))
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("ParseSpec: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("ParseSpec error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDefaultSpec(t *testing.T) {
+	data, err := specFS.ReadFile("specs/default.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ParseSpec(data)
+	if err != nil {
+		t.Fatalf("ParseSpec: %v", err)
+	}
+	if _, err := s.resolve(); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	for _, name := range []string{"DisplayPort", "HDMI"} {
+		if _, ok := LookupInterfaceFamily(name); !ok {
+			t.Errorf("no %s family", name)
+		}
+	}
+}
+
+func TestApplySpecRejectsRegisteredFamily(t *testing.T) {
+	registryMu.Lock()
+	saved, savedSpec, savedResolved := registered, activeSpec, resolved
+	registryMu.Unlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registered, activeSpec, resolved = saved, savedSpec, savedResolved
+		registryMu.Unlock()
+	})
+
+	hbr, ok := LookupLinkMode("HBR")
+	if !ok {
+		t.Fatal("no HBR mode")
+	}
+	f := InterfaceFamily{Name: "Test", Versions: []Interface{{Version: "1", Modes: []TransmissionMode{hbr}}}}
+	if err := RegisterInterfaceFamily(f); err != nil {
+		t.Fatalf("RegisterInterfaceFamily: %v", err)
+	}
+	if err := RegisterInterfaceFamily(f); err == nil {
+		t.Fatal("registering Test twice succeeded")
+	}
+	s, err := ParseSpec([]byte("version: 1\ninterfaces:\n  - family: Test\n    versions:\n      - version: \"2\"\n        modes: [HBR]\n"))
+	if err != nil {
+		t.Fatalf("ParseSpec: %v", err)
+	}
+	if err := ApplySpec(s); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Fatalf("ApplySpec error = %v, want already registered", err)
+	}
+	n := 0
+	for _, f := range InterfaceFamilies() {
+		if f.Name == "Test" {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Fatalf("%d Test families, want 1", n)
+	}
+}
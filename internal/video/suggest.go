@@ -0,0 +1,236 @@
+package video
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/hekmon/cunits/v3"
+)
+
+// Visual cost of each kind of change, used to rank suggestions. Changes that
+// cannot be seen on screen are cheap; subsampling and lower refresh rates
+// are not.
+const (
+	costTiming        = 0.5
+	costDSC           = 1
+	costInterface     = 2
+	costColorDepth    = 2.5 // per bpc step
+	costYCbCr422      = 4
+	costYCbCr420      = 6
+	costRefreshBase   = 1
+	costRefreshFactor = 10 // times the fraction of refresh rate lost
+)
+
+// minSuggestedRefreshRate is the lowest refresh rate worth suggesting.
+const minSuggestedRefreshRate = 24
+
+// Suggestion is a nearby configuration that fits where the original display
+// does not.
+type Suggestion struct {
+	Display   Display
+	Interface Interface
+	Mode      TransmissionMode
+	// DSCBitsPerPixel is the compressed bits per pixel, zero without DSC.
+	DSCBitsPerPixel float64
+	Changes         []string
+	Cost            float64
+
+	severity [5]float64
+}
+
+// Suggest searches the parameter space around d for the cheapest changes
+// that make it fit i: other timings, lower color depths, chroma subsampling,
+// DSC, lower refresh rates and newer versions of the same interface family.
+// At most limit suggestions are returned, cheapest first; none when d
+// already fits.
+func Suggest(d Display, i Interface, limit int) []Suggestion {
+	if fitsAny(d, i, true) {
+		return nil
+	}
+	var out []Suggestion
+	for _, t := range Timings() {
+		for _, c := range ColorDepths() {
+			if c > d.ColorDepth {
+				continue
+			}
+			for _, e := range Encodings() {
+				if e.samples() > d.Encoding.samples() || (e == EncodingYCbCr444 && d.Encoding == EncodingRGB) {
+					continue
+				}
+				for _, dsc := range []bool{false, true} {
+					if dsc && !i.DSC {
+						continue
+					}
+					candidate := d
+					candidate.Timing, candidate.ColorDepth, candidate.Encoding = t, c, e
+					if fitsAny(candidate, i, dsc) {
+						if s, ok := newSuggestion(d, candidate, i, dsc); ok {
+							out = append(out, s)
+						}
+						continue
+					}
+					if n, ok := bestMaxRefreshRate(candidate, i, dsc); ok && n >= minSuggestedRefreshRate {
+						candidate.RefreshRate = n
+						if s, ok := newSuggestion(d, candidate, i, dsc); ok {
+							out = append(out, s)
+						}
+					}
+				}
+			}
+		}
+	}
+	if s, ok := suggestInterface(d, i); ok {
+		out = append(out, s)
+	}
+
+	out = paretoFront(out)
+	slices.SortStableFunc(out, func(a, b Suggestion) int {
+		return cmp.Compare(a.Cost, b.Cost)
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// suggestInterface picks the least capable other version of i's family that
+// carries d unchanged, preferring uncompressed over DSC.
+func suggestInterface(d Display, i Interface) (Suggestion, bool) {
+	f, ok := LookupInterfaceFamily(i.Family)
+	if !ok {
+		return Suggestion{}, false
+	}
+	for _, dsc := range []bool{false, true} {
+		var best Interface
+		var bestBandwidth cunits.Bits
+		for _, other := range f.Versions {
+			if other.Version == i.Version || (dsc && !other.DSC) || !fitsAny(d, other, dsc) {
+				continue
+			}
+			bandwidth := sortedModes(other)[len(other.Modes)-1].EffectiveBandwidth().Bits
+			if best.Version == "" || bandwidth < bestBandwidth {
+				best, bestBandwidth = other, bandwidth
+			}
+		}
+		if best.Version == "" {
+			continue
+		}
+		s, ok := newSuggestion(d, d, best, dsc)
+		if !ok {
+			continue
+		}
+		s.Changes = append([]string{fmt.Sprintf("move to %s (%s)", best, s.Mode.GetName())}, s.Changes...)
+		s.Cost += costInterface
+		s.severity[4] += 1
+		return s, true
+	}
+	return Suggestion{}, false
+}
+
+func fitsAny(d Display, i Interface, dsc bool) bool {
+	for _, mode := range i.Modes {
+		if i.Fits(d, mode, dsc) {
+			return true
+		}
+	}
+	return false
+}
+
+func bestMaxRefreshRate(d Display, i Interface, dsc bool) (int, bool) {
+	n, _, ok := i.BestMaxRefreshRate(d, dsc)
+	return n, ok && n < d.RefreshRate
+}
+
+// newSuggestion describes the changes from d to candidate on i and prices
+// them.
+func newSuggestion(d, candidate Display, i Interface, dsc bool) (Suggestion, bool) {
+	s := Suggestion{Display: candidate, Interface: i}
+	for _, mode := range sortedModes(i) {
+		if i.Fits(candidate, mode, false) {
+			s.Mode = mode
+			dsc = false
+			break
+		}
+		if dsc && i.Fits(candidate, mode, true) {
+			// The rate negotiated links compress to, or none.
+			if bpp, ok := dscBitsPerPixel(candidate, mode.EffectiveBandwidth()); ok {
+				s.Mode, s.DSCBitsPerPixel = mode, bpp
+				break
+			}
+		}
+	}
+	if s.Mode == nil {
+		return Suggestion{}, false
+	}
+	if candidate.Timing.String() != d.Timing.String() {
+		s.Changes = append(s.Changes, fmt.Sprintf("use %s", candidate.Timing))
+		s.Cost += costTiming
+		s.severity[0] = 1
+	}
+	if candidate.ColorDepth != d.ColorDepth {
+		steps := float64(d.ColorDepth.BPC()-candidate.ColorDepth.BPC()) / 2
+		s.Changes = append(s.Changes, fmt.Sprintf("drop to %d bpc", candidate.ColorDepth.BPC()))
+		s.Cost += costColorDepth * steps
+		s.severity[1] = steps
+	}
+	if candidate.Encoding != d.Encoding {
+		s.Changes = append(s.Changes, fmt.Sprintf("switch to %s", candidate.Encoding))
+		switch candidate.Encoding {
+		case EncodingYCbCr422:
+			s.Cost += costYCbCr422
+			s.severity[2] = 1
+		case EncodingYCbCr420:
+			s.Cost += costYCbCr420
+			s.severity[2] = 2
+		}
+	}
+	if candidate.RefreshRate != d.RefreshRate {
+		lost := 1 - float64(candidate.RefreshRate)/float64(d.RefreshRate)
+		s.Changes = append(s.Changes, fmt.Sprintf("lower the refresh to %s Hz", FormatRefresh(candidate.RefreshRate, candidate.Fractional)))
+		s.Cost += costRefreshBase + costRefreshFactor*lost
+		s.severity[3] = lost
+	}
+	if dsc {
+		s.Changes = append(s.Changes, fmt.Sprintf("enable DSC at %g bpp", s.DSCBitsPerPixel))
+		s.Cost += costDSC
+		s.severity[4] += 0.5
+	}
+	return s, true
+}
+
+func sortedModes(i Interface) []TransmissionMode {
+	modes := slices.Clone(i.Modes)
+	slices.SortFunc(modes, func(a, b TransmissionMode) int {
+		return cmp.Compare(a.EffectiveBandwidth().Bits, b.EffectiveBandwidth().Bits)
+	})
+	return modes
+}
+
+// paretoFront drops every suggestion for which another one is at least as
+// mild in every respect.
+func paretoFront(in []Suggestion) []Suggestion {
+	var out []Suggestion
+	for i, a := range in {
+		dominated := false
+		for j, b := range in {
+			if i != j && dominates(b, a) && (!dominates(a, b) || j < i) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func dominates(a, b Suggestion) bool {
+	for k := range a.severity {
+		if a.severity[k] > b.severity[k] {
+			return false
+		}
+	}
+	return true
+}
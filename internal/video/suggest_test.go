@@ -0,0 +1,66 @@
+package video
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       Display
+		version string
+		// first and second are the changes of the two cheapest
+		// suggestions.
+		first, second []string
+		mode          string
+	}{
+		// The least capable version that carries the display beats any
+		// visible change, DSC being cheaper than a lower refresh rate.
+		{"newer version", testDisplay(t, 3840, 2160, 144, ColorDepth10bit()), "1.2",
+			[]string{"move to DisplayPort 2.x (UHBR13.5)"}, []string{"lower the refresh to 65 Hz"}, "UHBR13.5"},
+		{"newer version with dsc", testDisplay(t, 7680, 4320, 120, ColorDepth10bit()), "1.4",
+			[]string{"move to DisplayPort 2.x (UHBR13.5)", "enable DSC at 12.25 bpp"},
+			[]string{"lower the refresh to 74 Hz", "enable DSC at 10.0625 bpp"}, "UHBR13.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := testInterface(t, "DisplayPort", tt.version)
+			got := Suggest(tt.d, i, 0)
+			if len(got) < 2 {
+				t.Fatalf("%d suggestions, want several", len(got))
+			}
+			if !slices.Equal(got[0].Changes, tt.first) || got[0].Mode.GetName() != tt.mode || !slices.Equal(got[1].Changes, tt.second) {
+				t.Errorf("suggestions = %q on %s, then %q, want %q on %s, then %q",
+					got[0].Changes, got[0].Mode.GetName(), got[1].Changes, tt.first, tt.mode, tt.second)
+			}
+			for n, s := range got {
+				if n > 0 && s.Cost < got[n-1].Cost {
+					t.Errorf("suggestion %d costs %g, less than %g before it", n, s.Cost, got[n-1].Cost)
+				}
+				if !s.Interface.Fits(s.Display, s.Mode, s.DSCBitsPerPixel > 0) {
+					t.Errorf("suggestion %q does not fit %s %s", s.Changes, s.Interface, s.Mode.GetName())
+				}
+			}
+			if limited := Suggest(tt.d, i, 2); len(limited) != 2 || !slices.Equal(limited[1].Changes, got[1].Changes) {
+				t.Errorf("limited suggestions = %d, want the first 2", len(limited))
+			}
+		})
+	}
+
+	// A lower refresh rate of a 1000/1001 display stays 1000/1001.
+	d := testDisplay(t, 3840, 2160, 120, ColorDepth10bit())
+	d.Fractional = true
+	got := Suggest(d, testInterface(t, "DisplayPort", "1.2"), 0)
+	i := slices.IndexFunc(got, func(s Suggestion) bool {
+		return len(s.Changes) == 1 && strings.HasPrefix(s.Changes[0], "lower the refresh")
+	})
+	if i < 0 || !got[i].Display.Fractional || !strings.Contains(got[i].Changes[0], ".") {
+		t.Errorf("suggestions = %+v, want a fractional refresh rate", got)
+	}
+
+	if got := Suggest(testDisplay(t, 1920, 1080, 60, ColorDepth8bit()), testInterface(t, "DisplayPort", "1.2"), 0); got != nil {
+		t.Errorf("suggestions for a display that fits = %+v", got)
+	}
+}
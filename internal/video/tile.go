@@ -0,0 +1,133 @@
+package video
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hekmon/cunits/v3"
+)
+
+// TiledDisplay is a monitor driven as several tiles side by side, each sent
+// as its own stream with its own timing: e.g. 5K as 2x1 tiles of 2560x2880.
+// All the tiles share one mode, as on the tiled monitors DisplayID
+// describes; a layout of tiles of different sizes or timings cannot be
+// modelled.
+type TiledDisplay struct {
+	HTiles int
+	VTiles int
+	// Tile is the mode of every tile.
+	Tile Display
+}
+
+// NewTiledDisplay splits d into hTiles by vTiles tiles of the same size,
+// each with the timing of d. An exact timing, such as that of an EDID or a
+// modeline, cannot be split: d is then the mode of one tile.
+func NewTiledDisplay(d Display, hTiles, vTiles int) (TiledDisplay, error) {
+	if hTiles <= 0 || vTiles <= 0 {
+		return TiledDisplay{}, fmt.Errorf("tile layout %dx%d must be positive", hTiles, vTiles)
+	}
+	if _, ok := d.Timing.(ExactTiming); ok {
+		return TiledDisplay{HTiles: hTiles, VTiles: vTiles, Tile: d}, nil
+	}
+	if d.Width%hTiles != 0 || d.Height%vTiles != 0 {
+		return TiledDisplay{}, fmt.Errorf("%dx%d does not split into %dx%d tiles", d.Width, d.Height, hTiles, vTiles)
+	}
+	tile := d
+	tile.Width, tile.Height = d.Width/hTiles, d.Height/vTiles
+	return TiledDisplay{HTiles: hTiles, VTiles: vTiles, Tile: tile}, nil
+}
+
+// ParseTileLayout parses a tile layout such as 2x1, columns first.
+func ParseTileLayout(s string) (hTiles, vTiles int, err error) {
+	h, v, ok := strings.Cut(s, "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid tile layout %q (want e.g. 2x1)", s)
+	}
+	if hTiles, err = strconv.Atoi(h); err == nil {
+		vTiles, err = strconv.Atoi(v)
+	}
+	if err != nil || hTiles <= 0 || vTiles <= 0 {
+		return 0, 0, fmt.Errorf("invalid tile layout %q (want e.g. 2x1)", s)
+	}
+	return hTiles, vTiles, nil
+}
+
+func (t TiledDisplay) String() string {
+	d := t.Tile
+	d.Width, d.Height = t.Size()
+	return fmt.Sprintf("%s as %dx%d tiles of %dx%d", d.Mode(), t.HTiles, t.VTiles, t.Tile.Width, t.Tile.Height)
+}
+
+func (t TiledDisplay) Tiles() int {
+	return t.HTiles * t.VTiles
+}
+
+// Size is the resolution of the whole display.
+func (t TiledDisplay) Size() (width, height int) {
+	return t.HTiles * t.Tile.Width, t.VTiles * t.Tile.Height
+}
+
+// Bandwidth is the bandwidth of all the tile streams together.
+func (t TiledDisplay) Bandwidth() cunits.Speed {
+	return streamsBandwidth(t.Tile, t.Tiles())
+}
+
+// MST reports whether a link of i carries several streams, which came with
+// DisplayPort 1.2.
+func (i Interface) MST() bool {
+	return i.Family == "DisplayPort" && i.Version != "1.0" && i.Version != "1.1"
+}
+
+// CheckStreams checks n streams of d sharing one link of i, at the lowest
+// mode that carries them all.
+func (i Interface) CheckStreams(d Display, n int) Compatibility {
+	bandwidth := streamsBandwidth(d, n)
+	return i.checkBandwidth(bandwidth, d.ColorDepth, i.lowestCompatibleMode(bandwidth, d.ColorDepth))
+}
+
+func streamsBandwidth(d Display, n int) cunits.Speed {
+	return cunits.Speed{Bits: d.Bandwidth().Bits * cunits.Bits(n)}
+}
+
+// TileLink is a link carrying some of the tiles of a display, as MST
+// streams when there are several.
+type TileLink struct {
+	// Tiles are the indexes of the tiles, as in Spread.
+	Tiles []int
+	// Bandwidth is that of all the streams of the link.
+	Bandwidth     cunits.Speed
+	Compatibility Compatibility
+}
+
+// CheckTiles spreads the tiles of t over links links of i and checks the
+// streams of each link. One link per tile drives each tile over its own
+// cable; fewer links carry several tiles over MST.
+func (i Interface) CheckTiles(t TiledDisplay, links int) ([]TileLink, error) {
+	if links <= 0 || links > t.Tiles() {
+		return nil, fmt.Errorf("%d tiles cannot be spread over %d links", t.Tiles(), links)
+	}
+	if links < t.Tiles() && !i.MST() {
+		return nil, fmt.Errorf("%s has no MST to carry several tiles on one link", i)
+	}
+	out := make([]TileLink, links)
+	for n, tiles := range t.Spread(links) {
+		out[n] = TileLink{
+			Tiles:         tiles,
+			Bandwidth:     streamsBandwidth(t.Tile, len(tiles)),
+			Compatibility: i.CheckStreams(t.Tile, len(tiles)),
+		}
+	}
+	return out, nil
+}
+
+// Spread splits the tiles of t in order over links links, as evenly as
+// possible. Tiles are indexed from 0, left to right then top to bottom.
+func (t TiledDisplay) Spread(links int) [][]int {
+	out := make([][]int, links)
+	for n := range t.Tiles() {
+		l := n * links / t.Tiles()
+		out[l] = append(out[l], n)
+	}
+	return out
+}
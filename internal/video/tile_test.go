@@ -0,0 +1,56 @@
+package video
+
+import "testing"
+
+func TestNewTiledDisplay(t *testing.T) {
+	timing, ok := LookupTiming("CVT-RBv2")
+	if !ok {
+		t.Fatal("no CVT-RBv2 timing")
+	}
+	d := Display{Width: 5120, Height: 2880, RefreshRate: 60, ColorDepth: ColorDepth10bit(), Timing: timing}
+	tiled, err := NewTiledDisplay(d, 2, 1)
+	if err != nil {
+		t.Fatalf("NewTiledDisplay: %v", err)
+	}
+	if tiled.Tile.Width != 2560 || tiled.Tile.Height != 2880 {
+		t.Errorf("tile = %dx%d, want 2560x2880", tiled.Tile.Width, tiled.Tile.Height)
+	}
+	if _, err := NewTiledDisplay(d, 3, 1); err == nil {
+		t.Error("5120 split into 3 columns")
+	}
+	if _, err := NewTiledDisplay(d, 0, 1); err == nil {
+		t.Error("a layout of 0 columns was accepted")
+	}
+
+	exact := ExactTiming{Name: "Modeline", Width: 2560, Height: 2880, HFrontPorch: 48, HSync: 32, HBackPorch: 80,
+		VFrontPorch: 3, VSync: 5, VBackPorch: 56, PixelClock: 483_000_000}
+	tiled, err = NewTiledDisplay(exact.Display(ColorDepth10bit(), EncodingRGB), 2, 1)
+	if err != nil {
+		t.Fatalf("NewTiledDisplay of an exact timing: %v", err)
+	}
+	if w, h := tiled.Size(); w != 5120 || h != 2880 {
+		t.Errorf("size = %dx%d, want the exact timing as one tile of 5120x2880", w, h)
+	}
+}
+
+func TestCheckTiles(t *testing.T) {
+	timing, _ := LookupTiming("CVT-RBv2")
+	tiled, err := NewTiledDisplay(Display{Width: 7680, Height: 4320, RefreshRate: 60, ColorDepth: ColorDepth8bit(), Timing: timing}, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hdmi, ok := LookupInterfaceFamily("HDMI")
+	if !ok {
+		t.Fatal("no HDMI family")
+	}
+	if _, err := hdmi.Versions[0].CheckTiles(tiled, 1); err == nil {
+		t.Error("HDMI carried 2 tiles on one link")
+	}
+	links, err := hdmi.Versions[0].CheckTiles(tiled, 2)
+	if err != nil {
+		t.Fatalf("CheckTiles: %v", err)
+	}
+	if len(links) != 2 || links[0].Tiles[0] != 0 || links[1].Tiles[0] != 1 {
+		t.Errorf("links = %+v, want one tile each", links)
+	}
+}
@@ -0,0 +1,220 @@
+package video
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/hekmon/cunits/v3"
+)
+
+// LinkTraining is the order in which a source retrains a link at lower
+// rates and lane counts after a failure.
+type LinkTraining int
+
+const (
+	// LinkTrainingNone trains once at the top configuration.
+	LinkTrainingNone LinkTraining = iota
+	// LinkTrainingRateFirst lowers the link rate first, then halves the
+	// lane count and starts again from the top rate, as in DisplayPort 1.4.
+	LinkTrainingRateFirst
+	// LinkTrainingBandwidth walks every configuration by decreasing
+	// bandwidth, as in the DisplayPort 2.x fallback table.
+	LinkTrainingBandwidth
+)
+
+func (t LinkTraining) String() string {
+	switch t {
+	case LinkTrainingNone:
+		return ""
+	case LinkTrainingRateFirst:
+		return "rate-first"
+	case LinkTrainingBandwidth:
+		return "bandwidth"
+	}
+	return ""
+}
+
+func ParseLinkTraining(s string) (LinkTraining, error) {
+	for _, t := range []LinkTraining{LinkTrainingNone, LinkTrainingRateFirst, LinkTrainingBandwidth} {
+		if t.String() == s {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown link training %q", s)
+}
+
+// TrainingStep is one link training attempt.
+type TrainingStep struct {
+	Mode TransmissionMode
+	// Lanes is zero when the lane count is fixed by the mode.
+	Lanes     int
+	Bandwidth cunits.Speed
+	Trained   bool
+}
+
+func (s TrainingStep) LinkName() string {
+	if s.Lanes == 0 {
+		return s.Mode.GetName()
+	}
+	return fmt.Sprintf("%s x%d", s.Mode.GetName(), s.Lanes)
+}
+
+// TrainingResult is the outcome of a simulated link training.
+type TrainingResult struct {
+	Interface Interface
+	Steps     []TrainingStep
+	// Trained is false when every attempt failed.
+	Trained bool
+	// Status is how the display fits the trained link.
+	Status Status
+	// DSCBitsPerPixel is the compressed bits per pixel when Status is
+	// StatusDSC.
+	DSCBitsPerPixel float64
+	// ColorDepth is the highest color depth that fits the trained link
+	// uncompressed, zero when none does.
+	ColorDepth ColorDepth
+	// RefreshRate is the highest refresh rate that fits the trained link
+	// uncompressed at the requested color depth, zero when none does.
+	RefreshRate int
+}
+
+// Link is the configuration the link trained at. It is only meaningful
+// when Trained is set.
+func (r TrainingResult) Link() TrainingStep {
+	if len(r.Steps) == 0 {
+		return TrainingStep{}
+	}
+	return r.Steps[len(r.Steps)-1]
+}
+
+// SimulateLinkTraining walks the fallback sequence of i from the top
+// configuration source, sink and cable all support. Attempts at link rates
+// above topsOut, the fastest mode the cable actually carries, fail; an
+// empty topsOut never fails. The display is then checked against the link
+// the training ends on.
+func SimulateLinkTraining(d Display, i Interface, source, sink, cable LinkCaps, topsOut string) TrainingResult {
+	r := TrainingResult{Interface: i}
+	limit, limited := LookupLinkMode(topsOut)
+	for _, link := range trainingSequence(i, source, sink, cable) {
+		step := TrainingStep{
+			Mode:      link.mode,
+			Lanes:     link.lanes,
+			Bandwidth: link.bandwidth,
+			Trained:   !limited || link.mode.GetBandwidth().Bits <= limit.MaxBandwidth.Bits,
+		}
+		r.Steps = append(r.Steps, step)
+		if step.Trained {
+			r.Trained = true
+			break
+		}
+		if i.Training == LinkTrainingNone {
+			break
+		}
+	}
+	if !r.Trained {
+		r.Status = StatusBandwidth
+		return r
+	}
+
+	bandwidth := r.Link().Bandwidth
+	if _, ok := fitsLink(d, bandwidth, false); ok {
+		r.Status = StatusOK
+	} else if !i.DSC || !source.DSC || !sink.DSC {
+		r.Status = StatusNoDSC
+	} else if bpp, ok := fitsLink(d, bandwidth, true); ok {
+		r.Status = StatusDSC
+		r.DSCBitsPerPixel = bpp
+	} else {
+		r.Status = StatusBandwidth
+	}
+	for _, c := range slices.Backward(ColorDepths()) {
+		if c > d.ColorDepth {
+			continue
+		}
+		lower := d
+		lower.ColorDepth = c
+		if _, ok := fitsLink(lower, bandwidth, false); ok {
+			r.ColorDepth = c
+			break
+		}
+	}
+	r.RefreshRate, _ = searchMax(maxSolverRefreshRate, func(refresh int) bool {
+		d.RefreshRate = refresh
+		_, ok := fitsLink(d, bandwidth, false)
+		return validTiming(d) && ok
+	})
+	return r
+}
+
+// trainingSequence lists the configurations a source tries, in order. A
+// version falls back to the link rates of the older versions of its family
+// as well as its own.
+func trainingSequence(i Interface, caps ...LinkCaps) []linkRate {
+	modes, lanes := commonLink(withFallbackModes(i), caps...)
+	if len(modes) == 0 || len(lanes) == 0 {
+		return nil
+	}
+
+	var out []linkRate
+	switch i.Training {
+	case LinkTrainingNone:
+		out = append(out, newLinkRate(i, modes[len(modes)-1], lanes[len(lanes)-1]))
+	case LinkTrainingRateFirst:
+		for _, n := range slices.Backward(lanes) {
+			for _, m := range slices.Backward(modes) {
+				out = append(out, newLinkRate(i, m, n))
+			}
+		}
+	case LinkTrainingBandwidth:
+		for _, n := range lanes {
+			for _, m := range modes {
+				out = append(out, newLinkRate(i, m, n))
+			}
+		}
+		slices.SortStableFunc(out, func(a, b linkRate) int {
+			if c := cmp.Compare(b.bandwidth.Bits, a.bandwidth.Bits); c != 0 {
+				return c
+			}
+			return cmp.Compare(b.lanes, a.lanes)
+		})
+	}
+	return out
+}
+
+// withFallbackModes adds the link rates of the older versions of i's family
+// to its own.
+func withFallbackModes(i Interface) Interface {
+	f, ok := LookupInterfaceFamily(i.Family)
+	if !ok {
+		return i
+	}
+	n := slices.IndexFunc(f.Versions, func(v Interface) bool { return v.Version == i.Version })
+	if n < 0 {
+		return i
+	}
+	for _, older := range f.Versions[n+1:] {
+		for _, m := range older.Modes {
+			if !slices.ContainsFunc(i.Modes, func(o TransmissionMode) bool { return o.GetName() == m.GetName() }) {
+				i.Modes = append(slices.Clip(i.Modes), m)
+			}
+		}
+	}
+	return i
+}
+
+// TrainingModes are the link modes a source may train i at, its own and
+// those of the older versions of its family, slowest first.
+func (i Interface) TrainingModes() []TransmissionMode {
+	return sortedModes(withFallbackModes(i))
+}
+
+// LinkBandwidth is the effective bandwidth of m over lanes of i. A zero
+// lane count, or any when the lane count is fixed by the mode, is the
+// widest link.
+func (i Interface) LinkBandwidth(m TransmissionMode, lanes int) cunits.Speed {
+	if len(i.Lanes) == 0 {
+		lanes = 0
+	}
+	return newLinkRate(i, m, lanes).bandwidth
+}
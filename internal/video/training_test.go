@@ -0,0 +1,70 @@
+package video
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSimulateLinkTraining(t *testing.T) {
+	uhd60 := testDisplay(t, 3840, 2160, 60, ColorDepth10bit())
+	twoLanes := AnyLinkCaps()
+	twoLanes.MaxLanes = 2
+
+	tests := []struct {
+		name    string
+		family  string
+		version string
+		cable   LinkCaps
+		topsOut string
+		steps   []string
+		trained bool
+		status  Status
+	}{
+		{"first try", "DisplayPort", "1.4", AnyLinkCaps(), "", []string{"HBR3 x4"}, true, StatusOK},
+		// Rate first lowers the rate at full width, down through the rates
+		// of the older versions.
+		{"rate first", "DisplayPort", "1.4", AnyLinkCaps(), "HBR", []string{"HBR3 x4", "HBR2 x4", "HBR x4"}, true, StatusDSC},
+		{"rate first two lanes", "DisplayPort", "1.4", twoLanes, "HBR2", []string{"HBR3 x2", "HBR2 x2"}, true, StatusDSC},
+		// Bandwidth order interleaves lane counts, more lanes first on a tie.
+		{"bandwidth", "DisplayPort", "2.x", AnyLinkCaps(), "HBR3",
+			[]string{"UHBR20 x4", "UHBR13.5 x4", "UHBR10 x4", "UHBR20 x2", "UHBR13.5 x2", "HBR3 x4"}, true, StatusOK},
+		// HDMI 2.0 does not retrain.
+		{"no fallback", "HDMI", "2.0", AnyLinkCaps(), "TMDS (340 MHz)", []string{"TMDS (600 MHz)"}, false, StatusBandwidth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := SimulateLinkTraining(uhd60, testInterface(t, tt.family, tt.version), AnyLinkCaps(), AnyLinkCaps(), tt.cable, tt.topsOut)
+			var steps []string
+			for _, s := range r.Steps {
+				steps = append(steps, s.LinkName())
+			}
+			if !slices.Equal(steps, tt.steps) || r.Trained != tt.trained || r.Status != tt.status {
+				t.Errorf("training = %q, trained %t, %s, want %q, trained %t, %s", steps, r.Trained, r.Status, tt.steps, tt.trained, tt.status)
+			}
+			for n, s := range r.Steps {
+				if last := n == len(r.Steps)-1; s.Trained != (last && tt.trained) {
+					t.Errorf("step %s trained = %t", s.LinkName(), s.Trained)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulateLinkTrainingDSC(t *testing.T) {
+	// 4K144 at 10 bpc needs DSC on HBR2 x4, and 8 bpc does not fit either.
+	r := SimulateLinkTraining(testDisplay(t, 3840, 2160, 144, ColorDepth10bit()), testInterface(t, "DisplayPort", "1.4"),
+		AnyLinkCaps(), AnyLinkCaps(), AnyLinkCaps(), "HBR2")
+	if r.Link().LinkName() != "HBR2 x4" || r.Status != StatusDSC || r.DSCBitsPerPixel < minDSCBitsPerPixel || r.ColorDepth != 0 {
+		t.Errorf("result = %s, %s at %g bpp, %s, want DSC on HBR2 x4", r.Link().LinkName(), r.Status, r.DSCBitsPerPixel, r.ColorDepth)
+	}
+	if r.RefreshRate == 0 || r.RefreshRate >= 144 {
+		t.Errorf("max refresh rate = %d, want below 144", r.RefreshRate)
+	}
+
+	noDSC := AnyLinkCaps()
+	noDSC.DSC = false
+	if r := SimulateLinkTraining(testDisplay(t, 3840, 2160, 144, ColorDepth10bit()), testInterface(t, "DisplayPort", "1.4"),
+		AnyLinkCaps(), noDSC, AnyLinkCaps(), "HBR2"); r.Status != StatusNoDSC {
+		t.Errorf("status without sink DSC = %s, want %s", r.Status, StatusNoDSC)
+	}
+}
@@ -2,25 +2,65 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"io"
 	"log"
 	"log/slog"
+	"os"
 
+	"github.com/aloababa/gvbc/internal/cli"
+	"github.com/aloababa/gvbc/internal/preset"
 	"github.com/aloababa/gvbc/internal/tui"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := cli.Lookup(os.Args[1]); ok {
+			os.Exit(cmd.Run(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
 	fps := flag.Int("fps", 60, "The max fps at which the renderer should run (min 1, max 120)")
 	debug := flag.Bool("debug", false, "Enable debug to log file")
 	debugLogFile := flag.String("log-file", "debug.log", "The path to debug log file")
+	specFile := flag.String("spec", "", "The path to a spec file extending the built-in definitions (default $XDG_CONFIG_HOME/gvbc/spec.yaml if present)")
+	flag.Usage = usage
 	flag.Parse()
+	if err := cli.LoadSpec(*specFile); err != nil {
+		log.Fatal(err)
+	}
 	err := run(*debug, *debugLogFile, *fps)
 	if err != nil {
 		log.Fatal(err)
 	}
 }
 
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "Usage: gvbc [flags]\n       gvbc <command> [flags]\n\nCommands:\n")
+	for _, c := range cli.Commands() {
+		fmt.Fprintf(w, "  %-12s %s\n", c.Name, c.Summary)
+	}
+	fmt.Fprintf(w, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func openPresetStore() (*preset.Store, error) {
+	path, err := preset.DefaultPath()
+	if err != nil {
+		slog.Warn("user presets disabled", slog.Any("error", err))
+		return nil, nil
+	}
+	s, err := preset.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range s.Warnings() {
+		slog.Warn("user preset skipped", slog.String("warning", w))
+	}
+	return s, nil
+}
+
 func run(debug bool, debugLogFile string, fps int) error {
 	if debug {
 		f, err := tea.LogToFile(debugLogFile, "debug")
@@ -32,7 +72,11 @@ func run(debug bool, debugLogFile string, fps int) error {
 	} else {
 		slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
 	}
-	m := tui.NewModel()
+	presets, err := openPresetStore()
+	if err != nil {
+		return err
+	}
+	m := tui.NewModel(presets)
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithFPS(fps))
 	if _, err := p.Run(); err != nil {
 		return err